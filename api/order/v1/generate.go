@@ -0,0 +1,4 @@
+// Package orderv1 contém o contrato gRPC do Order Service.
+package orderv1
+
+//go:generate protoc -I ../.. --go_out=../.. --go_opt=paths=source_relative --go-grpc_out=../.. --go-grpc_opt=paths=source_relative order/v1/order.proto
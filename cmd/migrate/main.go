@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"text/tabwriter"
+
+	"order-service/internal/config"
+	"order-service/pkg/db"
+	"order-service/pkg/db/migrations"
+	"order-service/pkg/logger"
+	"order-service/pkg/migrate"
+)
+
+// Diretório dos arquivos .sql, relativo à raiz do repositório (usado por
+// create), com um subdiretório por driver
+const migrationsDir = "pkg/db/migrations"
+
+const usage = `uso: migrate [flags de configuração] <comando>
+
+  up            aplica todas as migrations pendentes
+  down [n]      reverte as últimas n migrations (padrão 1)
+  status        lista as migrations e quando foram aplicadas
+  create <nome> cria o par <versão>_<nome>.up.sql / .down.sql de cada driver`
+
+func main() {
+	cfg, err := config.Load(os.Args[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	logger.Init(&cfg.Log)
+
+	args := cfg.Args()
+	if len(args) < 1 {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	if args[0] == "create" {
+		if len(args) != 2 {
+			fmt.Fprintln(os.Stderr, usage)
+			os.Exit(2)
+		}
+		dirs := make([]string, len(migrations.Drivers))
+		for i, driver := range migrations.Drivers {
+			dirs[i] = filepath.Join(migrationsDir, driver)
+		}
+		paths, err := migrate.Create(args[1], dirs...)
+		if err != nil {
+			logger.Fatal("Erro ao criar migration", "error", err)
+		}
+		for _, path := range paths {
+			fmt.Println(path)
+		}
+		return
+	}
+
+	database, err := db.Connect(&cfg.Database)
+	if err != nil {
+		logger.Fatal("Erro ao conectar ao banco", "error", err)
+	}
+	sqlDB, err := database.DB()
+	if err != nil {
+		logger.Fatal("Erro ao obter pool do banco", "error", err)
+	}
+	defer sqlDB.Close()
+
+	fsys, err := migrations.For(cfg.Database.Driver)
+	if err != nil {
+		logger.Fatal("Erro ao carregar migrations", "error", err)
+	}
+	migrator, err := migrate.New(sqlDB, cfg.Database.Driver, fsys)
+	if err != nil {
+		logger.Fatal("Erro ao carregar migrations", "error", err)
+	}
+
+	ctx := context.Background()
+	switch args[0] {
+	case "up":
+		if _, err := migrator.Up(ctx); err != nil {
+			logger.Fatal("Erro ao aplicar migrations", "error", err)
+		}
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				logger.Fatal("Quantidade inválida", "value", args[1])
+			}
+		}
+		if _, err := migrator.Down(ctx, steps); err != nil {
+			logger.Fatal("Erro ao reverter migrations", "error", err)
+		}
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			logger.Fatal("Erro ao consultar migrations", "error", err)
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSÃO\tNOME\tAPLICADA EM")
+		for _, status := range statuses {
+			appliedAt := "pendente"
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.Local().Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(w, "%06d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+		}
+		w.Flush()
+	default:
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
@@ -1,86 +1,304 @@
 package main
 
 import (
-	"log"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
 	"net/http"
+	"os"
+	"time"
 
+	checkouthandler "order-service/internal/checkout/handler"
+	checkoutrepository "order-service/internal/checkout/repository"
+	checkoutservice "order-service/internal/checkout/service"
 	"order-service/internal/config"
 	"order-service/internal/order/handler"
 	"order-service/internal/order/repository"
+	"order-service/internal/order/rpc"
 	"order-service/internal/order/service"
+	webhookhandler "order-service/internal/webhook/handler"
+	webhookrepository "order-service/internal/webhook/repository"
+	webhookservice "order-service/internal/webhook/service"
+	"order-service/pkg/cache"
 	"order-service/pkg/db"
+	"order-service/pkg/health"
+	"order-service/pkg/lifecycle"
+	"order-service/pkg/logger"
 	"order-service/pkg/mq"
-
-	"github.com/gin-gonic/gin"
+	"order-service/pkg/tracing"
+	"order-service/pkg/transaction"
 )
 
 func main() {
-	cfg := config.Load()
-	log.Printf("Iniciando Order Service na porta %s", cfg.Server.Port)
+	cfg, err := config.Load(os.Args[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	logger.Init(&cfg.Log)
+	slog.Info("Iniciando Order Service", "version", fullVersion(), "env", cfg.Server.Env, "port", cfg.Server.Port)
+
+	// Os hooks param na ordem inversa: primeiro quem recebe tráfego, por
+	// último as dependências (broker, banco, tracing).
+	app := lifecycle.New(cfg.Server.ShutdownTimeout)
+
+	shutdownTracing, err := tracing.Init(context.Background(), &cfg.Tracing, tracing.InstrumentationName, fullVersion())
+	if err != nil {
+		logger.Fatal("Erro ao configurar tracing", "error", err)
+	}
+	app.Append(lifecycle.Hook{Name: "tracing", OnStop: shutdownTracing})
 
 	database, err := db.Connect(&cfg.Database)
 	if err != nil {
-		log.Fatal("Erro ao conectar ao banco:", err)
+		logger.Fatal("Erro ao conectar ao banco", "error", err)
 	}
+	sqlDB, err := database.DB()
+	if err != nil {
+		logger.Fatal("Erro ao obter pool do banco", "error", err)
+	}
+	app.Append(lifecycle.Hook{
+		Name:   cfg.Database.Driver,
+		OnStop: func(context.Context) error { return sqlDB.Close() },
+	})
 
-	if err := db.Migrate(database); err != nil {
-		log.Fatal("Erro ao executar migrations:", err)
+	if err := db.Migrate(context.Background(), sqlDB, cfg.Database.Driver, cfg.Database.MigrationsMode); err != nil {
+		logger.Fatal("Erro ao executar migrations", "error", err)
 	}
 
-	publisher, err := mq.NewPublisher(&cfg.RabbitMQ)
+	publisher, err := mq.NewPublisher(&cfg.MQ)
 	if err != nil {
-		log.Fatal("Erro ao conectar ao RabbitMQ:", err)
+		logger.Fatal("Erro ao conectar ao broker", "error", err)
 	}
-	defer publisher.Close()
+	app.Append(lifecycle.Hook{Name: cfg.MQ.Broker + "_publisher", OnStop: publisher.Shutdown})
 
-	orderRepo := repository.NewOrderRepository(database)
-	orderService := service.NewOrderService(orderRepo, publisher)
-	orderHandler := handler.NewOrderHandler(orderService)
+	events := mq.NewBroadcaster(cfg.MQ.EventHistorySize)
+	consumer, err := mq.NewConsumer(&cfg.MQ)
+	if err != nil {
+		logger.Fatal("Erro ao conectar consumer", "error", err)
+	}
+	// Consumer próprio para os eventos de pagamento e entrega de outros serviços
+	statusConsumer, err := mq.NewConsumer(&cfg.MQ)
+	if err != nil {
+		logger.Fatal("Erro ao conectar consumer de status", "error", err)
+	}
+	checkoutConsumer, err := mq.NewConsumer(&cfg.MQ)
+	if err != nil {
+		logger.Fatal("Erro ao conectar consumer de checkout", "error", err)
+	}
 
-	if cfg.Server.Env == "production" {
-		gin.SetMode(gin.ReleaseMode)
+	var orderRepo repository.OrderRepository = repository.NewOrderRepository(database)
+	transactions := transaction.NewManager(database, cfg.Database.TxMaxAttempts)
+	if cfg.EventStore.Enabled {
+		store := repository.NewEventSourcedOrderRepository(database, cfg.EventStore.SnapshotEvery)
+		orderRepo = store
+		// Os eventos são gravados com cada alteração e publicados depois; o
+		// relay para depois dos servidores, publicando o que eles gravaram
+		app.Append(background("event_store_relay",
+			service.NewEventRelay(store, transactions, publisher, &cfg.EventStore).Run))
+	}
+	var orderService service.OrderService = service.NewOrderService(orderRepo, transactions, publisher)
+	handleOrderEvent := events.HandleEvent
+	if cfg.Cache.Backend != cache.BackendNone {
+		orderCache, err := cache.New(&cfg.Cache)
+		if err != nil {
+			logger.Fatal("Erro ao conectar ao cache", "error", err)
+		}
+		app.Append(lifecycle.Hook{
+			Name:   "cache_" + cfg.Cache.Backend,
+			OnStop: func(context.Context) error { return orderCache.Close() },
+		})
+		cached := service.NewCachedOrderService(orderService, orderCache, cfg.Cache.TTL)
+		orderService = cached
+		// O cache é invalidado antes de os streams relerem o pedido
+		handleOrderEvent = func(ctx context.Context, event mq.OrderEvent) error {
+			cached.HandleEvent(ctx, event)
+			return events.HandleEvent(ctx, event)
+		}
 	}
+	summaryService := service.NewSummaryService(repository.NewSummaryRepository(database), transactions)
+	orderHandler := handler.NewOrderHandler(orderService, summaryService, cfg.Server.MaxBatchSize)
+	streamHandler := handler.NewStreamHandler(orderService, events, cfg.Server.AllowedOrigins())
+	replayService := service.NewReplayService(orderRepo, publisher, cfg.MQ.ReplayRate)
+	replayHandler := handler.NewReplayHandler(replayService)
+	// Replays em andamento param antes do publisher que usam
+	app.Append(lifecycle.Hook{Name: "replays", OnStop: replayService.Shutdown})
 
-	r := gin.Default()
+	webhookRepo := webhookrepository.NewWebhookRepository(database)
+	webhookService := webhookservice.NewWebhookService(webhookRepo)
+	webhookHandler := webhookhandler.NewWebhookHandler(webhookService)
 
-	r.Use(func(c *gin.Context) {
-		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization")
+	checkoutService := checkoutservice.NewCheckoutService(
+		checkoutrepository.NewSagaRepository(database), orderService, transactions, publisher, &cfg.Checkout)
+	checkoutHandler := checkouthandler.NewCheckoutHandler(checkoutService)
 
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
-			return
-		}
+	app.Append(lifecycle.Hook{
+		Name: cfg.MQ.Broker + "_consumer",
+		OnStart: func(context.Context) error {
+			// Eventos em tempo real (SSE, WebSocket, gRPC WatchOrder) e invalidação do
+			// cache vêm do exchange, por uma fila exclusiva desta réplica, para incluir
+			// mudanças feitas nas outras.
+			if err := consumer.StartListening("", []string{"order.#"}, handleOrderEvent); err != nil {
+				return err
+			}
+			// Webhooks e read model das listagens: filas duráveis compartilhadas entre réplicas
+			if err := consumer.StartListening(cfg.Webhook.Queue, []string{"order.#"}, webhookService.HandleOrderEvent); err != nil {
+				return err
+			}
+			return consumer.StartListening(cfg.MQ.SummaryQueue, []string{"order.#"}, summaryService.HandleEvent)
+		},
+		OnStop: consumer.Shutdown,
+	})
 
-		c.Next()
+	app.Append(lifecycle.Hook{
+		Name: cfg.MQ.Broker + "_status_consumer",
+		OnStart: func(context.Context) error {
+			return statusConsumer.StartListening(cfg.MQ.StatusEventsQueue, service.StatusEventRoutingKeys(),
+				service.NewStatusEventHandler(orderService))
+		},
+		OnStop: statusConsumer.Shutdown,
 	})
 
-	r.GET("/health", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{
-			"status":  "ok",
-			"service": "order-service",
-			"version": "1.0.0",
-		})
+	// Respostas de estoque e pagamento às sagas de checkout
+	app.Append(lifecycle.Hook{
+		Name: cfg.MQ.Broker + "_checkout_consumer",
+		OnStart: func(context.Context) error {
+			return checkoutConsumer.StartListening(cfg.Checkout.Queue, checkoutservice.ReplyRoutingKeys(), checkoutService.HandleReply)
+		},
+		OnStop: checkoutConsumer.Shutdown,
 	})
 
-	api := r.Group("/api/v1")
-	{
-		orders := api.Group("/orders")
-		{
-			orders.POST("", orderHandler.CreateOrder)
-			orders.GET("", orderHandler.GetOrdersByCustomer)
-			orders.GET("/:id", orderHandler.GetOrder)
-			orders.PUT("/:id/status", orderHandler.UpdateOrderStatus)
-			orders.PUT("/:id/cancel", orderHandler.CancelOrder)
-		}
+	// O dispatcher reserva cada entrega no banco antes de enviar
+	app.Append(background("webhook_dispatcher",
+		webhookservice.NewDispatcher(webhookRepo, nil, &cfg.Webhook).Run))
+
+	// Prazos vencidos e sagas interrompidas (queda da réplica, broker fora)
+	app.Append(background("checkout_sagas", checkoutService.Run))
+
+	grpcServer, grpcHealth := rpc.NewServer(rpc.NewOrderServer(orderService, summaryService, events))
+	app.Append(lifecycle.Hook{
+		Name: "grpc",
+		OnStart: func(context.Context) error {
+			lis, err := net.Listen("tcp", ":"+cfg.Server.GRPCPort)
+			if err != nil {
+				return err
+			}
+			go func() {
+				if err := grpcServer.Serve(lis); err != nil {
+					app.Fail(fmt.Errorf("grpc server: %w", err))
+				}
+			}()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			done := make(chan struct{})
+			go func() {
+				grpcServer.GracefulStop()
+				close(done)
+			}()
+			select {
+			case <-done:
+				return nil
+			case <-ctx.Done():
+				grpcServer.Stop()
+				return ctx.Err()
+			}
+		},
+	})
+
+	// Prontidão: banco, publisher e consumers. /livez não depende deles.
+	checker := health.NewChecker(fullVersion(), cfg.Server.ReadinessTimeout, cfg.Server.ReadinessCacheTTL)
+	checker.Register(cfg.Database.Driver, sqlDB.PingContext)
+	checker.Register(cfg.MQ.Broker+"_publisher", func(context.Context) error { return publisher.Check() })
+	checker.Register(cfg.MQ.Broker+"_consumer", func(context.Context) error { return consumer.Check() })
+	checker.Register(cfg.MQ.Broker+"_status_consumer", func(context.Context) error { return statusConsumer.Check() })
+	checker.Register(cfg.MQ.Broker+"_checkout_consumer", func(context.Context) error { return checkoutConsumer.Check() })
+
+	server := &http.Server{
+		Addr:              ":" + cfg.Server.Port,
+		Handler:           setupRouter(cfg, checker, orderHandler, streamHandler, replayHandler, webhookHandler, checkoutHandler),
+		ReadHeaderTimeout: 10 * time.Second,
 	}
+	app.Append(lifecycle.Hook{
+		Name: "http",
+		OnStart: func(context.Context) error {
+			lis, err := net.Listen("tcp", server.Addr)
+			if err != nil {
+				return err
+			}
+			go func() {
+				if err := server.Serve(lis); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					app.Fail(fmt.Errorf("http server: %w", err))
+				}
+			}()
+			return nil
+		},
+		OnStop: server.Shutdown,
+	})
+
+	// Streams SSE/WebSocket/WatchOrder não terminam sozinhos: fechar o
+	// broadcaster os encerra antes de drenar HTTP e gRPC.
+	app.Append(lifecycle.Hook{
+		Name: "streams",
+		OnStop: func(context.Context) error {
+			events.Close()
+			return nil
+		},
+	})
+
+	// Primeiro passo do desligamento: /readyz e o grpc-health passam a falhar
+	// e aguardamos o load balancer tirar a réplica de rotação.
+	app.Append(lifecycle.Hook{
+		Name: "readiness",
+		OnStop: func(ctx context.Context) error {
+			checker.SetShuttingDown()
+			grpcHealth.Shutdown()
+			select {
+			case <-time.After(cfg.Server.ShutdownDelay):
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		},
+	})
+
+	slog.Info("Order Service rodando",
+		"http", "http://localhost:"+cfg.Server.Port,
+		"openapi", "http://localhost:"+cfg.Server.Port+"/openapi.json",
+		"metrics", "http://localhost:"+cfg.Server.Port+"/metrics",
+		"grpc", "localhost:"+cfg.Server.GRPCPort,
+	)
+
+	if err := app.Run(context.Background()); err != nil {
+		logger.Fatal("Order Service encerrado com erro", "error", err)
+	}
+	slog.Info("Order Service encerrado")
+}
 
-	log.Printf("Order Service rodando em http://localhost:%s", cfg.Server.Port)
-	log.Printf("Health check: http://localhost:%s/health", cfg.Server.Port)
-	log.Printf("API Orders: http://localhost:%s/api/v1/orders", cfg.Server.Port)
+// background roda fn em uma goroutine até o desligamento; OnStop cancela o
+// contexto e espera fn retornar.
+func background(name string, fn func(ctx context.Context)) lifecycle.Hook {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
 
-	if err := r.Run(":" + cfg.Server.Port); err != nil {
-		log.Fatal("Erro ao iniciar servidor:", err)
+	return lifecycle.Hook{
+		Name: name,
+		OnStart: func(context.Context) error {
+			go func() {
+				defer close(done)
+				fn(ctx)
+			}()
+			return nil
+		},
+		OnStop: func(stopCtx context.Context) error {
+			cancel()
+			select {
+			case <-done:
+				return nil
+			case <-stopCtx.Done():
+				return stopCtx.Err()
+			}
+		},
 	}
 }
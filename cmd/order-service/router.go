@@ -0,0 +1,189 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+
+	checkouthandler "order-service/internal/checkout/handler"
+	"order-service/internal/config"
+	"order-service/internal/order/handler"
+	webhookhandler "order-service/internal/webhook/handler"
+	"order-service/pkg/auth"
+	"order-service/pkg/deadline"
+	"order-service/pkg/health"
+	"order-service/pkg/logger"
+	"order-service/pkg/metrics"
+	"order-service/pkg/openapi"
+	"order-service/pkg/tracing"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+)
+
+// openAPISpec monta o contrato com as rotas de todos os módulos.
+func openAPISpec() *openapi.Document {
+	spec := handler.OpenAPISpec(version)
+	webhookhandler.AddOpenAPI(spec)
+	checkouthandler.AddOpenAPI(spec)
+
+	// Rotas da API com prazo (deadline.Middleware) podem responder 504
+	timeout := &openapi.Response{
+		Description: "Prazo da requisição esgotado",
+		Content:     openapi.JSONContent(spec.Register(handler.ErrorResponse{})),
+	}
+	for _, route := range spec.Routes() {
+		method, path, _ := strings.Cut(route, " ")
+		op := spec.Operation(method, path)
+		if strings.HasPrefix(path, "/api/v1/") && !op.Streaming() {
+			op.Responses["504"] = timeout
+		}
+	}
+
+	// Rotas com auth.AdminToken respondem 401 e 403
+	unauthorized := &openapi.Response{
+		Description: "Header Authorization: Bearer <ADMIN_TOKEN> ausente ou inválido",
+		Content:     openapi.JSONContent(spec.Register(handler.ErrorResponse{})),
+	}
+	forbidden := &openapi.Response{
+		Description: "ADMIN_TOKEN não configurado",
+		Content:     openapi.JSONContent(spec.Register(handler.ErrorResponse{})),
+	}
+	for _, route := range spec.Routes() {
+		method, path, _ := strings.Cut(route, " ")
+		if isAdminPath(path) {
+			op := spec.Operation(method, path)
+			op.Responses["401"] = unauthorized
+			op.Responses["403"] = forbidden
+		}
+	}
+	return spec
+}
+
+func isAdminPath(path string) bool {
+	return strings.HasPrefix(path, "/debug/") || strings.HasPrefix(path, "/api/v1/admin/") ||
+		path == "/api/v1/webhooks" || strings.HasPrefix(path, "/api/v1/webhooks/")
+}
+
+func setupRouter(
+	cfg *config.Config,
+	checker *health.Checker,
+	orderHandler *handler.OrderHandler,
+	streamHandler *handler.StreamHandler,
+	replayHandler *handler.ReplayHandler,
+	webhookHandler *webhookhandler.WebhookHandler,
+	checkoutHandler *checkouthandler.CheckoutHandler,
+) *gin.Engine {
+	if cfg.Server.GinMode != "" {
+		gin.SetMode(cfg.Server.GinMode)
+	}
+
+	// gin.New em vez de gin.Default: o log de acesso sai pelo slog, com request ID
+	r := gin.New()
+	r.Use(gin.Recovery())
+	r.Use(logger.Middleware())
+	r.Use(otelgin.Middleware(tracing.InstrumentationName))
+	r.Use(metrics.Middleware())
+
+	r.Use(func(c *gin.Context) {
+		c.Header("Access-Control-Allow-Origin", "*")
+		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization")
+
+		if c.Request.Method == "OPTIONS" {
+			c.AbortWithStatus(204)
+			return
+		}
+
+		c.Next()
+	})
+
+	spec := openAPISpec()
+	if cfg.Server.ValidateRequests || cfg.Server.ValidateResponses {
+		r.Use(openapi.Validator(spec, openapi.ValidatorOptions{
+			ValidateRequests:  cfg.Server.ValidateRequests,
+			ValidateResponses: cfg.Server.ValidateResponses,
+			OnRequestError: func(c *gin.Context, err error) {
+				c.AbortWithStatusJSON(http.StatusBadRequest, handler.ErrorResponse{
+					Error:   "Dados inválidos",
+					Message: err.Error(),
+				})
+			},
+		}))
+	}
+
+	r.GET("/health", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"status":  "ok",
+			"service": "order-service",
+			"version": fullVersion(),
+		})
+	})
+
+	r.GET("/livez", checker.LiveHandler)
+	r.GET("/readyz", checker.ReadyHandler)
+
+	r.GET("/openapi.json", func(c *gin.Context) {
+		c.JSON(http.StatusOK, spec)
+	})
+
+	r.GET("/metrics", gin.WrapH(metrics.Handler()))
+
+	// Rotas administrativas exigem ADMIN_TOKEN
+	adminAuth := auth.AdminToken(cfg.Server.AdminToken)
+
+	debug := r.Group("/debug", adminAuth)
+	{
+		debug.GET("/log-level", logger.LevelHandler)
+		debug.PUT("/log-level", logger.LevelHandler)
+	}
+
+	// Prazo por rota: cancela as queries da requisição quando estoura.
+	// Streams (SSE e WebSocket) ficam sem prazo.
+	timeout := deadline.Middleware(cfg.Server.RequestTimeout)
+	batchTimeout := deadline.Middleware(cfg.Server.BatchRequestTimeout)
+
+	api := r.Group("/api/v1")
+	{
+		orders := api.Group("/orders", handler.OrderContext())
+		{
+			orders.POST("", timeout, orderHandler.CreateOrder)
+			orders.GET("", timeout, orderHandler.GetOrdersByCustomer)
+			orders.POST("/batch", batchTimeout, orderHandler.CreateOrdersBatch)
+			orders.POST("/batch/status", batchTimeout, orderHandler.UpdateOrderStatusesBatch)
+			orders.GET("/:id", timeout, orderHandler.GetOrder)
+			orders.PUT("/:id/status", timeout, orderHandler.UpdateOrderStatus)
+			orders.PUT("/:id/cancel", timeout, orderHandler.CancelOrder)
+			orders.GET("/:id/events", streamHandler.OrderEvents)
+		}
+
+		api.GET("/customers/:customer_id/orders/ws", streamHandler.CustomerOrders)
+
+		admin := api.Group("/admin", adminAuth, timeout)
+		{
+			admin.POST("/replays", replayHandler.StartReplay)
+			admin.GET("/replays/:id", replayHandler.GetReplay)
+			admin.DELETE("/replays/:id", replayHandler.CancelReplay)
+		}
+
+		// Assinaturas de webhook recebem os eventos de todos os clientes
+		webhooks := api.Group("/webhooks", adminAuth, timeout)
+		{
+			webhooks.POST("", webhookHandler.CreateSubscription)
+			webhooks.GET("", webhookHandler.ListSubscriptions)
+			webhooks.GET("/:id", webhookHandler.GetSubscription)
+			webhooks.PUT("/:id", webhookHandler.UpdateSubscription)
+			webhooks.DELETE("/:id", webhookHandler.DeleteSubscription)
+			webhooks.GET("/:id/deliveries", webhookHandler.ListDeliveries)
+			webhooks.POST("/:id/deliveries/:delivery_id/redeliver", webhookHandler.Redeliver)
+		}
+
+		checkouts := api.Group("/checkouts", timeout)
+		{
+			checkouts.POST("", checkoutHandler.StartCheckout)
+			checkouts.GET("", checkoutHandler.ListSagas)
+			checkouts.GET("/:id", checkoutHandler.GetSaga)
+		}
+	}
+
+	return r
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	checkouthandler "order-service/internal/checkout/handler"
+	"order-service/internal/config"
+	"order-service/internal/order/handler"
+	"order-service/internal/order/service"
+	webhookhandler "order-service/internal/webhook/handler"
+	"order-service/pkg/health"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newTestRouter(cfg *config.Config) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	return setupRouter(
+		cfg,
+		health.NewChecker("test", time.Second, 0),
+		handler.NewOrderHandler(nil, nil, 0),
+		handler.NewStreamHandler(nil, nil, nil),
+		handler.NewReplayHandler(service.NewReplayService(nil, nil, 100)),
+		webhookhandler.NewWebhookHandler(nil),
+		checkouthandler.NewCheckoutHandler(nil),
+	)
+}
+
+func TestRoutesMatchOpenAPISpec(t *testing.T) {
+	r := newTestRouter(&config.Config{})
+
+	var registered []string
+	for _, route := range r.Routes() {
+		registered = append(registered, route.Method+" "+route.Path)
+	}
+
+	documented := openAPISpec().Routes()
+
+	for _, route := range registered {
+		if !slices.Contains(documented, route) {
+			t.Errorf("rota %s não está documentada no OpenAPI", route)
+		}
+	}
+	for _, route := range documented {
+		if !slices.Contains(registered, route) {
+			t.Errorf("rota %s documentada no OpenAPI mas não registrada", route)
+		}
+	}
+}
+
+func TestAdminRoutesRequireToken(t *testing.T) {
+	admin := []struct{ method, path, body string }{
+		{http.MethodPost, "/api/v1/admin/replays", `{"order_ids": [1]}`},
+		{http.MethodGet, "/api/v1/admin/replays/abc", ""},
+		{http.MethodDelete, "/api/v1/admin/replays/abc", ""},
+		{http.MethodGet, "/debug/log-level", ""},
+		{http.MethodPut, "/debug/log-level", `{"level": "info"}`},
+		{http.MethodPost, "/api/v1/webhooks", `{"url": "https://evil.example.com", "events": ["order.created"]}`},
+		{http.MethodGet, "/api/v1/webhooks", ""},
+		{http.MethodGet, "/api/v1/webhooks/1", ""},
+		{http.MethodPut, "/api/v1/webhooks/1", `{"active": false}`},
+		{http.MethodDelete, "/api/v1/webhooks/1", ""},
+		{http.MethodGet, "/api/v1/webhooks/1/deliveries", ""},
+		{http.MethodPost, "/api/v1/webhooks/1/deliveries/1/redeliver", ""},
+	}
+	do := func(r *gin.Engine, method, path, body, token string) int {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// Sem ADMIN_TOKEN as rotas ficam fechadas
+	closed := newTestRouter(&config.Config{})
+	for _, route := range admin {
+		if got := do(closed, route.method, route.path, route.body, ""); got != http.StatusForbidden {
+			t.Errorf("%s %s sem ADMIN_TOKEN: status %d, esperado 403", route.method, route.path, got)
+		}
+	}
+
+	r := newTestRouter(&config.Config{Server: config.ServerConfig{AdminToken: "s3cret"}})
+	for _, route := range admin {
+		if got := do(r, route.method, route.path, route.body, ""); got != http.StatusUnauthorized {
+			t.Errorf("%s %s anônimo: status %d, esperado 401", route.method, route.path, got)
+		}
+		if got := do(r, route.method, route.path, route.body, "outro"); got != http.StatusUnauthorized {
+			t.Errorf("%s %s com token errado: status %d, esperado 401", route.method, route.path, got)
+		}
+	}
+
+	// Com o token a requisição chega ao handler (replay desconhecido)
+	if got := do(r, http.MethodGet, "/api/v1/admin/replays/abc", "", "s3cret"); got != http.StatusNotFound {
+		t.Errorf("GET /api/v1/admin/replays/abc autenticado: status %d, esperado 404", got)
+	}
+	if got := do(r, http.MethodGet, "/debug/log-level", "", "s3cret"); got != http.StatusOK {
+		t.Errorf("GET /debug/log-level autenticado: status %d, esperado 200", got)
+	}
+}
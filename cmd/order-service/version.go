@@ -0,0 +1,36 @@
+package main
+
+import "runtime/debug"
+
+// Preenchidos no build:
+//
+//	go build -ldflags "-X main.version=$(git describe --tags --always) -X main.commit=$(git rev-parse --short HEAD)" ./cmd/order-service
+var (
+	version = "dev"
+	commit  = ""
+)
+
+func init() {
+	if commit != "" {
+		return
+	}
+
+	// Sem ldflags, usa a revisão que o toolchain grava no binário
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return
+	}
+	for _, setting := range info.Settings {
+		if setting.Key == "vcs.revision" && len(setting.Value) >= 7 {
+			commit = setting.Value[:7]
+		}
+	}
+}
+
+// fullVersion junta versão e commit, ex.: "1.4.0+3f2a9c1".
+func fullVersion() string {
+	if commit == "" {
+		return version
+	}
+	return version + "+" + commit
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"order-service/internal/config"
+	"order-service/internal/order/repository"
+	"order-service/internal/order/service"
+	"order-service/pkg/db"
+	"order-service/pkg/logger"
+	"order-service/pkg/transaction"
+)
+
+const usage = `uso: projection [flags de configuração] rebuild
+
+Recria customer_order_summary, o read model das listagens por cliente, a
+partir dos pedidos gravados. Os consumidores podem continuar rodando: os
+eventos anteriores ao início do rebuild são descartados.`
+
+func main() {
+	cfg, err := config.Load(os.Args[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	logger.Init(&cfg.Log)
+
+	args := cfg.Args()
+	if len(args) != 1 || args[0] != "rebuild" {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	database, err := db.Connect(&cfg.Database)
+	if err != nil {
+		logger.Fatal("Erro ao conectar ao banco", "error", err)
+	}
+	sqlDB, err := database.DB()
+	if err != nil {
+		logger.Fatal("Erro ao obter pool do banco", "error", err)
+	}
+	defer sqlDB.Close()
+
+	// Ctrl+C interrompe o rebuild, que roda numa transação: nada muda
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	summaries := service.NewSummaryService(
+		repository.NewSummaryRepository(database),
+		transaction.NewManager(database, cfg.Database.TxMaxAttempts),
+	)
+	rebuilt, err := summaries.Rebuild(ctx)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fmt.Printf("%d pedidos no read model\n", rebuilt)
+}
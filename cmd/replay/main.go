@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"order-service/internal/config"
+	"order-service/internal/order/model"
+	"order-service/internal/order/repository"
+	"order-service/internal/order/service"
+	"order-service/pkg/db"
+	"order-service/pkg/logger"
+	"order-service/pkg/mq"
+)
+
+const usage = `uso: replay [flags de configuração] publish [filtros e destino]
+
+Republica um evento order.created com o estado atual de cada pedido
+selecionado, marcado com o header replay, a até mq.replay_rate eventos/s.
+
+  -from, -to     pedidos com mudanças no intervalo (RFC 3339)
+  -ids           IDs separados por vírgula
+  -status        status atual dos pedidos
+  -routing-key   routing key no lugar de order.created
+  -queue         única fila que deve processar os eventos
+  -rate          eventos por segundo (até mq.replay_rate)`
+
+func main() {
+	cfg, err := config.Load(os.Args[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	logger.Init(&cfg.Log)
+
+	args := cfg.Args()
+	if len(args) < 1 || args[0] != "publish" {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+	req, err := parseRequest(args[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	database, err := db.Connect(&cfg.Database)
+	if err != nil {
+		logger.Fatal("Erro ao conectar ao banco", "error", err)
+	}
+	sqlDB, err := database.DB()
+	if err != nil {
+		logger.Fatal("Erro ao obter pool do banco", "error", err)
+	}
+	defer sqlDB.Close()
+
+	publisher, err := mq.NewPublisher(&cfg.MQ)
+	if err != nil {
+		logger.Fatal("Erro ao conectar ao broker", "error", err)
+	}
+	defer publisher.Shutdown(context.Background())
+
+	// Ctrl+C interrompe o replay; os eventos já publicados ficam no broker
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	replays := service.NewReplayService(repository.NewOrderRepository(database), publisher, cfg.MQ.ReplayRate)
+	result, err := replays.Replay(ctx, req)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	fmt.Printf("replay %s: %s, %d publicados, %d falhas\n", result.ID, result.Status, result.Published, result.Failed)
+	if result.Error != "" {
+		fmt.Println(result.Error)
+	}
+	if result.Status != model.ReplayCompleted || result.Failed > 0 {
+		os.Exit(1)
+	}
+}
+
+func parseRequest(args []string) (model.ReplayRequest, error) {
+	var req model.ReplayRequest
+	flags := flag.NewFlagSet("publish", flag.ContinueOnError)
+	flags.Usage = func() {}
+	flags.Func("from", "", timeFlag(&req.From))
+	flags.Func("to", "", timeFlag(&req.To))
+	flags.Func("ids", "", func(value string) error {
+		for _, field := range strings.Split(value, ",") {
+			id, err := strconv.ParseUint(strings.TrimSpace(field), 10, 32)
+			if err != nil {
+				return fmt.Errorf("ID inválido %q", field)
+			}
+			req.OrderIDs = append(req.OrderIDs, uint(id))
+		}
+		return nil
+	})
+	flags.Func("status", "", func(value string) error {
+		req.Status = model.OrderStatus(value)
+		return nil
+	})
+	flags.StringVar(&req.RoutingKey, "routing-key", "", "")
+	flags.StringVar(&req.Queue, "queue", "", "")
+	flags.IntVar(&req.Rate, "rate", 0, "")
+
+	if err := flags.Parse(args); err != nil {
+		return req, err
+	}
+	if flags.NArg() > 0 {
+		return req, errors.New("argumentos inesperados: " + strings.Join(flags.Args(), " "))
+	}
+	return req, nil
+}
+
+func timeFlag(dst **time.Time) func(string) error {
+	return func(value string) error {
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return fmt.Errorf("data inválida %q (use RFC 3339, ex.: 2025-01-31T00:00:00Z)", value)
+		}
+		*dst = &t
+		return nil
+	}
+}
@@ -1,37 +1,42 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
-	"log"
+	"fmt"
+	"log/slog"
 	"os"
 	"os/signal"
 	"syscall"
 
 	"order-service/internal/config"
+	"order-service/pkg/logger"
 	"order-service/pkg/mq"
 )
 
 func main() {
-	log.Println("Iniciando Test Consumer...")
+	cfg, err := config.Load(os.Args[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	logger.Init(&cfg.Log)
 
-	cfg := config.Load()
+	slog.Info("Iniciando Test Consumer")
 
-	consumer, err := mq.NewConsumer(&cfg.RabbitMQ)
+	consumer, err := mq.NewConsumer(&cfg.MQ)
 	if err != nil {
-		log.Fatal("Erro ao conectar consumer:", err)
+		logger.Fatal("Erro ao conectar consumer", "error", err)
 	}
 	defer consumer.Close()
 
-	handler := func(event mq.OrderEvent) error {
-		log.Printf("EVENTO RECEBIDO:")
-		log.Printf("   Tipo: %s", event.Type)
-		log.Printf("   Order ID: %d", event.OrderID)
-
-		if jsonData, err := json.MarshalIndent(event.Data, "   ", "  "); err == nil {
-			log.Printf("   Dados: %s", string(jsonData))
+	handler := func(ctx context.Context, event mq.OrderEvent) error {
+		attrs := []any{"event_type", event.Type, "order_id", event.OrderID, "event_id", event.ID}
+		if jsonData, err := json.Marshal(event.Data); err == nil {
+			attrs = append(attrs, "data", string(jsonData))
 		}
 
-		log.Println("   ---")
+		slog.InfoContext(ctx, "Evento recebido", attrs...)
 		return nil
 	}
 
@@ -43,14 +48,14 @@ func main() {
 	}
 
 	if err := consumer.StartListening("test_order_events", routingKeys, handler); err != nil {
-		log.Fatal("Erro ao iniciar consumer:", err)
+		logger.Fatal("Erro ao iniciar consumer", "error", err)
 	}
 
-	log.Println("Consumer rodando... Pressione Ctrl+C para parar")
+	slog.Info("Consumer rodando, pressione Ctrl+C para parar")
 
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
 	<-c
 
-	log.Println("Parando consumer...")
+	slog.Info("Parando consumer")
 }
@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"order-service/internal/checkout/model"
+	"order-service/internal/checkout/service"
+	ordermodel "order-service/internal/order/model"
+	orderservice "order-service/internal/order/service"
+	"order-service/pkg/deadline"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CheckoutHandler struct {
+	checkoutService service.CheckoutService
+}
+
+func NewCheckoutHandler(checkoutService service.CheckoutService) *CheckoutHandler {
+	return &CheckoutHandler{
+		checkoutService: checkoutService,
+	}
+}
+
+func (h *CheckoutHandler) StartCheckout(c *gin.Context) {
+	var req ordermodel.CreateOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Dados inválidos",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	saga, err := h.checkoutService.StartCheckout(c.Request.Context(), req)
+	if err != nil {
+		if deadline.Respond(c, err) {
+			return
+		}
+		status := http.StatusInternalServerError
+		if errors.Is(err, orderservice.ErrEmptyOrder) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, ErrorResponse{
+			Error:   "Erro ao iniciar checkout",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.Header("Location", "/api/v1/checkouts/"+strconv.FormatUint(uint64(saga.ID), 10))
+	c.JSON(http.StatusAccepted, saga)
+}
+
+func (h *CheckoutHandler) ListSagas(c *gin.Context) {
+	// Parâmetros de paginação
+	limit := 20
+	offset := 0
+
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+			limit = l
+		}
+	}
+
+	if offsetStr := c.Query("offset"); offsetStr != "" {
+		if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
+			offset = o
+		}
+	}
+
+	sagas, err := h.checkoutService.ListSagas(c.Request.Context(), model.SagaStatus(c.Query("status")), limit, offset)
+	if err != nil {
+		if deadline.Respond(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Erro ao buscar checkouts",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, SagaListResponse{
+		Sagas:  sagas,
+		Count:  len(sagas),
+		Limit:  limit,
+		Offset: offset,
+	})
+}
+
+func (h *CheckoutHandler) GetSaga(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "ID inválido",
+			Message: "id deve ser um número",
+		})
+		return
+	}
+
+	saga, err := h.checkoutService.GetSaga(c.Request.Context(), uint(id))
+	if err != nil {
+		if deadline.Respond(c, err) {
+			return
+		}
+		status := http.StatusInternalServerError
+		if errors.Is(err, service.ErrSagaNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, ErrorResponse{
+			Error:   "Checkout não encontrado",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, saga)
+}
+
+// ErrorResponse é o corpo de erro comum às rotas da API.
+type ErrorResponse = deadline.ErrorResponse
+
+type SagaListResponse struct {
+	Sagas  []model.Saga `json:"sagas"`
+	Count  int          `json:"count"`
+	Limit  int          `json:"limit"`
+	Offset int          `json:"offset"`
+}
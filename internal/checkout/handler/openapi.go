@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"net/http"
+
+	"order-service/internal/checkout/model"
+	ordermodel "order-service/internal/order/model"
+	"order-service/pkg/openapi"
+)
+
+// AddOpenAPI registra as rotas de checkout no documento do serviço.
+func AddOpenAPI(doc *openapi.Document) {
+	status := doc.RegisterEnum(model.SagaStatus(""),
+		model.SagaRunning,
+		model.SagaCompensating,
+		model.SagaCompleted,
+		model.SagaFailed,
+	)
+	doc.RegisterEnum(model.StepStatus(""),
+		model.StepPending,
+		model.StepWaiting,
+		model.StepCompleted,
+		model.StepFailed,
+		model.StepTimedOut,
+		model.StepCompensated,
+	)
+	createOrder := doc.Register(ordermodel.CreateOrderRequest{})
+	saga := doc.Register(model.Saga{})
+	sagaList := doc.Register(SagaListResponse{})
+	errorContent := openapi.JSONContent(doc.Register(ErrorResponse{}))
+
+	doc.AddOperation(http.MethodPost, "/api/v1/checkouts", &openapi.Operation{
+		OperationID: "startCheckout",
+		Summary:     "Cria o pedido e inicia a saga de checkout (estoque, pagamento, confirmação)",
+		Tags:        []string{"checkout"},
+		RequestBody: &openapi.RequestBody{Required: true, Content: openapi.JSONContent(createOrder)},
+		Responses: map[string]*openapi.Response{
+			"202": {Description: "Checkout iniciado", Content: openapi.JSONContent(saga)},
+			"400": {Description: "Dados inválidos", Content: errorContent},
+			"500": {Description: "Erro ao iniciar checkout", Content: errorContent},
+		},
+	})
+
+	doc.AddOperation(http.MethodGet, "/api/v1/checkouts", &openapi.Operation{
+		OperationID: "listCheckouts",
+		Summary:     "Lista as sagas de checkout, das mais recentes às mais antigas",
+		Tags:        []string{"checkout"},
+		Parameters: []openapi.Parameter{
+			openapi.QueryParam("status", false, status),
+			openapi.QueryParam("limit", false, openapi.Integer()),
+			openapi.QueryParam("offset", false, openapi.Integer()),
+		},
+		Responses: map[string]*openapi.Response{
+			"200": {Description: "Sagas", Content: openapi.JSONContent(sagaList)},
+			"500": {Description: "Erro ao buscar checkouts", Content: errorContent},
+		},
+	})
+
+	doc.AddOperation(http.MethodGet, "/api/v1/checkouts/:id", &openapi.Operation{
+		OperationID: "getCheckout",
+		Summary:     "Estado da saga e de cada passo",
+		Tags:        []string{"checkout"},
+		Parameters:  []openapi.Parameter{openapi.PathParam("id", openapi.Integer())},
+		Responses: map[string]*openapi.Response{
+			"200": {Description: "Saga", Content: openapi.JSONContent(saga)},
+			"400": {Description: "ID inválido", Content: errorContent},
+			"404": {Description: "Checkout não encontrado", Content: errorContent},
+		},
+	})
+}
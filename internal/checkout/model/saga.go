@@ -0,0 +1,116 @@
+package model
+
+import "time"
+
+type SagaStatus string
+
+const (
+	SagaRunning SagaStatus = "running"
+	// Um passo falhou: os anteriores estão sendo desfeitos
+	SagaCompensating SagaStatus = "compensating"
+	SagaCompleted    SagaStatus = "completed"
+	SagaFailed       SagaStatus = "failed"
+)
+
+type StepStatus string
+
+const (
+	StepPending StepStatus = "pending"
+	// Comando enviado, aguardando a resposta do participante até Deadline
+	StepWaiting   StepStatus = "waiting"
+	StepCompleted StepStatus = "completed"
+	// Recusado pelo participante: não há o que compensar
+	StepFailed StepStatus = "failed"
+	// Sem resposta no prazo: o participante pode ter executado, então o
+	// passo também é compensado
+	StepTimedOut    StepStatus = "timed_out"
+	StepCompensated StepStatus = "compensated"
+)
+
+// Passos do checkout, na ordem em que são executados
+const (
+	StepReserveStock   = "reserve_stock"
+	StepRequestPayment = "request_payment"
+	StepConfirmOrder   = "confirm_order"
+)
+
+// Steps são os passos de uma saga nova.
+var Steps = []string{StepReserveStock, StepRequestPayment, StepConfirmOrder}
+
+type Saga struct {
+	ID      uint       `json:"id" gorm:"primarykey"`
+	OrderID uint       `json:"order_id" gorm:"not null;uniqueIndex"`
+	Status  SagaStatus `json:"status" gorm:"type:varchar(20);not null"`
+	// Passo em execução; durante a compensação, o que falhou
+	CurrentStep string `json:"current_step" gorm:"type:varchar(30);not null"`
+	// Quando o orquestrador deve agir sem esperar resposta: prazo do passo em
+	// andamento ou nova tentativa após um erro. Vazio nas sagas encerradas.
+	NextRunAt *time.Time `json:"next_run_at"`
+	Error     string     `json:"error,omitempty" gorm:"type:text"`
+	Steps     []SagaStep `json:"steps" gorm:"foreignKey:SagaID;constraint:OnDelete:CASCADE"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+func (Saga) TableName() string {
+	return "checkout_sagas"
+}
+
+type SagaStep struct {
+	ID       uint       `json:"-" gorm:"primarykey"`
+	SagaID   uint       `json:"-" gorm:"not null;uniqueIndex:idx_checkout_saga_step"`
+	Position int        `json:"-" gorm:"not null;uniqueIndex:idx_checkout_saga_step"`
+	Name     string     `json:"name" gorm:"type:varchar(30);not null"`
+	Status   StepStatus `json:"status" gorm:"type:varchar(20);not null"`
+	// ID devolvido pelo participante (reserva, pagamento), usado na compensação
+	Reference     string     `json:"reference,omitempty" gorm:"type:varchar(100)"`
+	Error         string     `json:"error,omitempty" gorm:"type:text"`
+	StartedAt     *time.Time `json:"started_at"`
+	Deadline      *time.Time `json:"deadline"`
+	FinishedAt    *time.Time `json:"finished_at"`
+	CompensatedAt *time.Time `json:"compensated_at"`
+}
+
+func (SagaStep) TableName() string {
+	return "checkout_saga_steps"
+}
+
+// NewSaga cria a saga do pedido com todos os passos pendentes, pronta para
+// executar o primeiro.
+func NewSaga(orderID uint, now time.Time) *Saga {
+	saga := &Saga{
+		OrderID:     orderID,
+		Status:      SagaRunning,
+		CurrentStep: Steps[0],
+		NextRunAt:   &now,
+	}
+	for i, name := range Steps {
+		saga.Steps = append(saga.Steps, SagaStep{Position: i, Name: name, Status: StepPending})
+	}
+	return saga
+}
+
+// Step devolve o passo pelo nome, ou nil.
+func (s *Saga) Step(name string) *SagaStep {
+	for i := range s.Steps {
+		if s.Steps[i].Name == name {
+			return &s.Steps[i]
+		}
+	}
+	return nil
+}
+
+// Next devolve o passo seguinte a step, ou nil se step for o último.
+func (s *Saga) Next(step *SagaStep) *SagaStep {
+	for i := range s.Steps {
+		if s.Steps[i].Position == step.Position+1 {
+			return &s.Steps[i]
+		}
+	}
+	return nil
+}
+
+// Finished informa se a saga chegou a um estado final.
+func (s *Saga) Finished() bool {
+	return s.Status == SagaCompleted || s.Status == SagaFailed
+}
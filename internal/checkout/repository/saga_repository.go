@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"order-service/internal/checkout/model"
+	"order-service/pkg/transaction"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/plugin/dbresolver"
+)
+
+type SagaRepository interface {
+	// Create grava a saga com os passos
+	Create(ctx context.Context, saga *model.Saga) error
+	GetByID(ctx context.Context, id uint) (*model.Saga, error)
+	// GetByIDForUpdate e GetByOrderIDForUpdate bloqueiam a saga (FOR UPDATE)
+	// até o fim da transação em andamento, para que réplicas e consumers não
+	// avancem a mesma saga ao mesmo tempo.
+	GetByIDForUpdate(ctx context.Context, id uint) (*model.Saga, error)
+	GetByOrderIDForUpdate(ctx context.Context, orderID uint) (*model.Saga, error)
+	// List filtra por status quando informado, das mais recentes às mais antigas
+	List(ctx context.Context, status model.SagaStatus, limit, offset int) ([]model.Saga, error)
+	// ListDue devolve os IDs das sagas em andamento com next_run_at vencido.
+	// now deve estar em UTC: no SQLite os horários são comparados como texto.
+	ListDue(ctx context.Context, now time.Time, limit int) ([]uint, error)
+	// Update grava a saga e os passos
+	Update(ctx context.Context, saga *model.Saga) error
+	// Reschedule altera só o next_run_at, fora da transação que falhou
+	Reschedule(ctx context.Context, id uint, at time.Time) error
+}
+
+type sagaRepository struct {
+	db *gorm.DB
+}
+
+func NewSagaRepository(db *gorm.DB) SagaRepository {
+	return &sagaRepository{db: db}
+}
+
+func (r *sagaRepository) Create(ctx context.Context, saga *model.Saga) error {
+	return transaction.DB(ctx, r.db).Create(saga).Error
+}
+
+func (r *sagaRepository) GetByID(ctx context.Context, id uint) (*model.Saga, error) {
+	var saga model.Saga
+	err := transaction.DB(ctx, r.db).Preload("Steps", orderByPosition).First(&saga, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &saga, nil
+}
+
+func (r *sagaRepository) GetByIDForUpdate(ctx context.Context, id uint) (*model.Saga, error) {
+	var saga model.Saga
+	err := transaction.DB(ctx, r.db).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Preload("Steps", orderByPosition).
+		First(&saga, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &saga, nil
+}
+
+func (r *sagaRepository) GetByOrderIDForUpdate(ctx context.Context, orderID uint) (*model.Saga, error) {
+	var saga model.Saga
+	err := transaction.DB(ctx, r.db).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Preload("Steps", orderByPosition).
+		Where("order_id = ?", orderID).
+		First(&saga).Error
+	if err != nil {
+		return nil, err
+	}
+	return &saga, nil
+}
+
+func (r *sagaRepository) List(ctx context.Context, status model.SagaStatus, limit, offset int) ([]model.Saga, error) {
+	var sagas []model.Saga
+	query := transaction.DB(ctx, r.db).Preload("Steps", orderByPosition)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Order("id DESC").Limit(limit).Offset(offset).Find(&sagas).Error
+	return sagas, err
+}
+
+func (r *sagaRepository) ListDue(ctx context.Context, now time.Time, limit int) ([]uint, error) {
+	var ids []uint
+	err := transaction.DB(ctx, r.db).Clauses(dbresolver.Write).Model(&model.Saga{}).
+		Where("status IN ? AND next_run_at <= ?", []model.SagaStatus{model.SagaRunning, model.SagaCompensating}, now).
+		Order("next_run_at").
+		Limit(limit).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+func (r *sagaRepository) Update(ctx context.Context, saga *model.Saga) error {
+	return transaction.DB(ctx, r.db).Session(&gorm.Session{FullSaveAssociations: true}).Save(saga).Error
+}
+
+func (r *sagaRepository) Reschedule(ctx context.Context, id uint, at time.Time) error {
+	return transaction.DB(ctx, r.db).Model(&model.Saga{}).Where("id = ?", id).Update("next_run_at", at).Error
+}
+
+func orderByPosition(db *gorm.DB) *gorm.DB {
+	return db.Order("position")
+}
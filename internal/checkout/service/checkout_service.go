@@ -0,0 +1,429 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"time"
+
+	"order-service/internal/checkout/model"
+	"order-service/internal/checkout/repository"
+	"order-service/internal/config"
+	ordermodel "order-service/internal/order/model"
+	orderservice "order-service/internal/order/service"
+	"order-service/pkg/mq"
+	"order-service/pkg/transaction"
+
+	"gorm.io/gorm"
+)
+
+// resumeBatchSize é quantas sagas vencidas cada rodada de ResumeDue avança.
+const resumeBatchSize = 50
+
+// Comandos enviados aos serviços de estoque e pagamento (routing keys)
+const (
+	CommandReserveStock   = "inventory.reserve"
+	CommandReleaseStock   = "inventory.release"
+	CommandRequestPayment = "payment.request"
+	CommandVoidPayment    = "payment.void"
+)
+
+// reply é uma resposta de participante a um passo da saga.
+type reply struct {
+	step string
+	ok   bool
+	// Campo de data com o ID da reserva ou do pagamento
+	reference string
+}
+
+var replies = map[string]reply{
+	"inventory.reserved": {step: model.StepReserveStock, ok: true, reference: "reservation_id"},
+	"inventory.rejected": {step: model.StepReserveStock},
+	"payment.authorized": {step: model.StepRequestPayment, ok: true, reference: "payment_id"},
+	"payment.failed":     {step: model.StepRequestPayment},
+}
+
+// ReplyRoutingKeys são as routing keys tratadas por HandleReply.
+func ReplyRoutingKeys() []string {
+	return slices.Sorted(maps.Keys(replies))
+}
+
+// CheckoutService conduz o checkout como uma saga persistida: reserva o
+// estoque, solicita o pagamento, aguarda o resultado e confirma o pedido.
+// Estoque e pagamento são outros serviços, acionados por comandos no broker
+// e que respondem com eventos. Cada passo tem prazo e uma compensação
+// (liberar o estoque, cancelar o pagamento), executadas do último ao
+// primeiro quando um passo falha.
+//
+// Cada transição é gravada na mesma transação em que é decidida, com a saga
+// bloqueada, então réplicas e consumers podem disputar a mesma saga e uma
+// saga interrompida é retomada por ResumeDue. Comandos podem ser reenviados
+// nessa retomada; o ID do evento é o mesmo a cada envio, para que os
+// participantes descartem repetições.
+type CheckoutService interface {
+	// StartCheckout cria o pedido e a saga na mesma transação e inicia o
+	// primeiro passo.
+	StartCheckout(ctx context.Context, req ordermodel.CreateOrderRequest) (*model.Saga, error)
+	GetSaga(ctx context.Context, id uint) (*model.Saga, error)
+	ListSagas(ctx context.Context, status model.SagaStatus, limit, offset int) ([]model.Saga, error)
+	// HandleReply aplica uma resposta de estoque ou pagamento; respostas
+	// repetidas ou atrasadas não mudam a saga.
+	HandleReply(ctx context.Context, event mq.OrderEvent) error
+	// ResumeDue avança as sagas com prazo vencido ou interrompidas por erro.
+	ResumeDue(ctx context.Context) error
+	// Run chama ResumeDue a cada PollInterval até ctx ser cancelado.
+	Run(ctx context.Context)
+}
+
+type checkoutService struct {
+	repo         repository.SagaRepository
+	orders       orderservice.OrderService
+	transactions transaction.Manager
+	publisher    mq.Publisher
+	cfg          *config.CheckoutConfig
+}
+
+func NewCheckoutService(
+	repo repository.SagaRepository,
+	orders orderservice.OrderService,
+	transactions transaction.Manager,
+	publisher mq.Publisher,
+	cfg *config.CheckoutConfig,
+) CheckoutService {
+	return &checkoutService{
+		repo:         repo,
+		orders:       orders,
+		transactions: transactions,
+		publisher:    publisher,
+		cfg:          cfg,
+	}
+}
+
+func (s *checkoutService) StartCheckout(ctx context.Context, req ordermodel.CreateOrderRequest) (*model.Saga, error) {
+	var saga *model.Saga
+	// Pedido sem saga ficaria pending para sempre: se a saga não for gravada,
+	// o pedido também não é. O order.created sai só depois do commit
+	err := s.transactions.Do(ctx, func(ctx context.Context) error {
+		order, err := s.orders.CreateOrder(ctx, req)
+		if err != nil {
+			return err
+		}
+
+		saga = model.NewSaga(order.ID, time.Now().UTC())
+		if err := s.repo.Create(ctx, saga); err != nil {
+			return fmt.Errorf("erro ao criar saga: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slog.InfoContext(ctx, "Checkout iniciado", "saga_id", saga.ID, "order_id", saga.OrderID)
+
+	// Se o primeiro passo falhar agora, ResumeDue tenta de novo
+	s.advance(ctx, saga.ID)
+	return s.GetSaga(ctx, saga.ID)
+}
+
+func (s *checkoutService) GetSaga(ctx context.Context, id uint) (*model.Saga, error) {
+	saga, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSagaNotFound, err)
+	}
+	return saga, nil
+}
+
+func (s *checkoutService) ListSagas(ctx context.Context, status model.SagaStatus, limit, offset int) ([]model.Saga, error) {
+	sagas, err := s.repo.List(ctx, status, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar sagas: %w", err)
+	}
+	return sagas, nil
+}
+
+func (s *checkoutService) HandleReply(ctx context.Context, event mq.OrderEvent) error {
+	r, ok := replies[event.RoutingKey]
+	if !ok || event.OrderID <= 0 {
+		return nil
+	}
+
+	lock := func(ctx context.Context) (*model.Saga, error) {
+		saga, err := s.repo.GetByOrderIDForUpdate(ctx, uint(event.OrderID))
+		// Pedidos criados fora do checkout também recebem eventos de pagamento
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return saga, err
+	}
+
+	return s.update(ctx, lock, func(saga *model.Saga) bool {
+		step := saga.Step(r.step)
+		if saga.Status != model.SagaRunning || saga.CurrentStep != step.Name || step.Status != model.StepWaiting {
+			slog.DebugContext(ctx, "Resposta ignorada pela saga",
+				"saga_id", saga.ID, "routing_key", event.RoutingKey, "event_id", event.ID, "step", step.Name, "step_status", step.Status)
+			return false
+		}
+
+		now := time.Now().UTC()
+		step.FinishedAt = &now
+		data, _ := event.Data.(map[string]any)
+		if r.ok {
+			step.Status = model.StepCompleted
+			if ref, ok := data[r.reference]; ok {
+				step.Reference = fmt.Sprint(ref)
+			}
+			return true
+		}
+
+		step.Status = model.StepFailed
+		step.Error = event.RoutingKey
+		if reason, ok := data["reason"].(string); ok && reason != "" {
+			step.Error += ": " + reason
+		}
+		s.compensateFrom(saga, step, now)
+		return true
+	})
+}
+
+func (s *checkoutService) ResumeDue(ctx context.Context) error {
+	ids, err := s.repo.ListDue(ctx, time.Now().UTC(), resumeBatchSize)
+	if err != nil {
+		return fmt.Errorf("erro ao buscar sagas: %w", err)
+	}
+
+	for _, id := range ids {
+		if ctx.Err() != nil {
+			return nil
+		}
+		s.advance(ctx, id)
+	}
+	return nil
+}
+
+// Run processa as sagas vencidas até o contexto ser cancelado. A rodada em
+// andamento é concluída antes de retornar.
+func (s *checkoutService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.ResumeDue(context.WithoutCancel(ctx)); err != nil {
+			slog.ErrorContext(ctx, "Erro ao retomar sagas", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// advance executa o que a saga puder fazer agora.
+func (s *checkoutService) advance(ctx context.Context, id uint) {
+	err := s.update(ctx, func(ctx context.Context) (*model.Saga, error) {
+		return s.repo.GetByIDForUpdate(ctx, id)
+	}, nil)
+	if err != nil {
+		slog.ErrorContext(ctx, "Erro ao avançar saga", "saga_id", id, "error", err)
+	}
+}
+
+// update bloqueia a saga com lock, aplica change (quando retorna false, a
+// saga fica como está) e avança o que for possível, gravando tudo na mesma
+// transação. Se algo falhar, nada é gravado e a saga é reagendada para
+// RetryInterval.
+func (s *checkoutService) update(ctx context.Context, lock func(context.Context) (*model.Saga, error), change func(*model.Saga) bool) error {
+	var saga *model.Saga
+	var before model.SagaStatus
+	err := s.transactions.Do(ctx, func(ctx context.Context) error {
+		var err error
+		saga, err = lock(ctx)
+		if err != nil || saga == nil {
+			return err
+		}
+		before = saga.Status
+
+		if change != nil && !change(saga) {
+			saga = nil
+			return nil
+		}
+		if err := s.proceed(ctx, saga); err != nil {
+			return err
+		}
+		return s.repo.Update(ctx, saga)
+	})
+	if err != nil {
+		if saga != nil {
+			retry := time.Now().UTC().Add(s.cfg.RetryInterval)
+			if err := s.repo.Reschedule(context.WithoutCancel(ctx), saga.ID, retry); err != nil {
+				slog.ErrorContext(ctx, "Erro ao reagendar saga", "saga_id", saga.ID, "error", err)
+			}
+		}
+		return err
+	}
+
+	if saga != nil && saga.Status != before {
+		slog.InfoContext(ctx, "Saga alterada",
+			"saga_id", saga.ID, "order_id", saga.OrderID, "from", before, "to", saga.Status, "step", saga.CurrentStep, "error", saga.Error)
+		if saga.Finished() {
+			sagasFinished.WithLabelValues(string(saga.Status)).Inc()
+		}
+	}
+	return nil
+}
+
+// proceed percorre os passos até a saga precisar esperar uma resposta ou
+// terminar.
+func (s *checkoutService) proceed(ctx context.Context, saga *model.Saga) error {
+	for !saga.Finished() {
+		now := time.Now().UTC()
+		if saga.Status == model.SagaCompensating {
+			return s.compensate(ctx, saga, now)
+		}
+
+		step := saga.Step(saga.CurrentStep)
+		switch step.Status {
+		case model.StepPending:
+			if err := s.begin(ctx, saga, step, now); err != nil {
+				return err
+			}
+		case model.StepWaiting:
+			if now.Before(*step.Deadline) {
+				saga.NextRunAt = step.Deadline
+				return nil
+			}
+			step.Status = model.StepTimedOut
+			step.Error = "prazo esgotado"
+			step.FinishedAt = &now
+			stepTimeouts.WithLabelValues(step.Name).Inc()
+			s.compensateFrom(saga, step, now)
+		case model.StepCompleted:
+			next := saga.Next(step)
+			if next == nil {
+				saga.Status = model.SagaCompleted
+				saga.NextRunAt = nil
+				return nil
+			}
+			saga.CurrentStep = next.Name
+		default:
+			s.compensateFrom(saga, step, now)
+		}
+	}
+	return nil
+}
+
+// begin executa a ação do passo: envia o comando ao participante e passa a
+// aguardar a resposta, ou, na confirmação, altera o pedido diretamente.
+func (s *checkoutService) begin(ctx context.Context, saga *model.Saga, step *model.SagaStep, now time.Time) error {
+	step.StartedAt = &now
+
+	if step.Name == model.StepConfirmOrder {
+		// O pedido pode já estar confirmed (status consumer) ou ter sido
+		// cancelado durante a saga. Só o pedido cancelado ou inexistente
+		// falha o passo, compensando o pagamento; falhas do banco desfazem a
+		// transação e a saga é reagendada
+		_, err := s.orders.AdvanceOrderStatus(ctx, saga.OrderID, ordermodel.StatusConfirmed)
+		switch {
+		case errors.Is(err, orderservice.ErrInvalidStatusTransition), errors.Is(err, orderservice.ErrOrderNotFound):
+			step.Status = model.StepFailed
+			step.Error = err.Error()
+		case err != nil:
+			return err
+		default:
+			step.Status = model.StepCompleted
+		}
+		step.FinishedAt = &now
+		return nil
+	}
+
+	order, err := s.orders.GetOrderByID(ctx, saga.OrderID)
+	if err != nil {
+		return err
+	}
+
+	var timeout time.Duration
+	switch step.Name {
+	case model.StepReserveStock:
+		items := make([]map[string]any, len(order.Items))
+		for i, item := range order.Items {
+			items[i] = map[string]any{"product_id": item.ProductID, "quantity": item.Quantity}
+		}
+		err = s.send(ctx, saga, CommandReserveStock, map[string]any{"items": items})
+		timeout = s.cfg.StockTimeout
+	case model.StepRequestPayment:
+		err = s.send(ctx, saga, CommandRequestPayment, map[string]any{
+			"customer_id": order.CustomerID,
+			"amount":      order.TotalAmount,
+		})
+		timeout = s.cfg.PaymentTimeout
+	}
+	if err != nil {
+		return err
+	}
+
+	deadline := now.Add(timeout)
+	step.Status = model.StepWaiting
+	step.Deadline = &deadline
+	saga.NextRunAt = &deadline
+	return nil
+}
+
+// compensateFrom passa a saga a desfazer os passos anteriores a step, que falhou.
+func (s *checkoutService) compensateFrom(saga *model.Saga, step *model.SagaStep, now time.Time) {
+	saga.Status = model.SagaCompensating
+	saga.CurrentStep = step.Name
+	saga.Error = step.Name + ": " + step.Error
+	saga.NextRunAt = &now
+}
+
+// compensate desfaz, do último ao primeiro, os passos que tiveram (ou podem
+// ter tido) efeito e encerra a saga com o pedido em failed.
+func (s *checkoutService) compensate(ctx context.Context, saga *model.Saga, now time.Time) error {
+	for i := len(saga.Steps) - 1; i >= 0; i-- {
+		step := &saga.Steps[i]
+		if step.Status != model.StepCompleted && step.Status != model.StepTimedOut {
+			continue
+		}
+
+		var err error
+		switch step.Name {
+		case model.StepReserveStock:
+			err = s.send(ctx, saga, CommandReleaseStock, map[string]any{"reservation_id": step.Reference})
+		case model.StepRequestPayment:
+			err = s.send(ctx, saga, CommandVoidPayment, map[string]any{"payment_id": step.Reference})
+		}
+		if err != nil {
+			return err
+		}
+		step.Status = model.StepCompensated
+		step.CompensatedAt = &now
+	}
+
+	// Um pedido já cancelado fica como está
+	_, err := s.orders.AdvanceOrderStatus(ctx, saga.OrderID, ordermodel.StatusFailed)
+	if err != nil && !errors.Is(err, orderservice.ErrInvalidStatusTransition) {
+		return err
+	}
+
+	saga.Status = model.SagaFailed
+	saga.NextRunAt = nil
+	return nil
+}
+
+// send publica um comando da saga. O ID do evento não muda entre reenvios.
+func (s *checkoutService) send(ctx context.Context, saga *model.Saga, command string, data map[string]any) error {
+	data["saga_id"] = saga.ID
+	data["order_id"] = saga.OrderID
+
+	return s.publisher.PublishTo(ctx, command, mq.OrderEvent{
+		ID:      fmt.Sprintf("saga-%d-%s", saga.ID, command),
+		Type:    command,
+		OrderID: int(saga.OrderID),
+		Data:    data,
+	})
+}
@@ -0,0 +1,329 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"order-service/internal/checkout/model"
+	"order-service/internal/checkout/repository"
+	"order-service/internal/config"
+	ordermodel "order-service/internal/order/model"
+	orderrepository "order-service/internal/order/repository"
+	orderservice "order-service/internal/order/service"
+	"order-service/pkg/db/dbtest"
+	"order-service/pkg/mq"
+	"order-service/pkg/mq/mqtest"
+	"order-service/pkg/transaction"
+)
+
+type checkoutTest struct {
+	checkout  CheckoutService
+	orders    orderservice.OrderService
+	publisher *mqtest.Publisher
+	// Pedidos como o CheckoutService os vê; advanceErr simula falhas
+	flaky *flakyOrders
+	// sagas cria o CheckoutService sobre outro repositório de sagas
+	sagas func(repository.SagaRepository) CheckoutService
+	// restart cria outro CheckoutService sobre o mesmo banco, como uma
+	// réplica nova depois de uma queda
+	restart func() CheckoutService
+}
+
+// newCheckoutTest monta o serviço sobre um SQLite em memória, com os
+// repositórios e o gerenciador de transações reais.
+func newCheckoutTest(t *testing.T, cfg config.CheckoutConfig) *checkoutTest {
+	t.Helper()
+	database := dbtest.NewSQLite(t)
+
+	publisher := mqtest.NewPublisher()
+	transactions := transaction.NewManager(database, 1)
+	orders := orderservice.NewOrderService(orderrepository.NewOrderRepository(database), transactions, publisher)
+	flaky := &flakyOrders{OrderService: orders}
+	sagas := func(repo repository.SagaRepository) CheckoutService {
+		return NewCheckoutService(repo, flaky, transactions, publisher, &cfg)
+	}
+	restart := func() CheckoutService {
+		return sagas(repository.NewSagaRepository(database))
+	}
+	return &checkoutTest{checkout: restart(), orders: orders, publisher: publisher, flaky: flaky, sagas: sagas, restart: restart}
+}
+
+// flakyOrders devolve advanceErr em AdvanceOrderStatus enquanto ele não
+// for nil.
+type flakyOrders struct {
+	orderservice.OrderService
+	advanceErr error
+}
+
+func (o *flakyOrders) AdvanceOrderStatus(ctx context.Context, id uint, status ordermodel.OrderStatus) (*ordermodel.OrderResponse, error) {
+	if o.advanceErr != nil {
+		return nil, o.advanceErr
+	}
+	return o.OrderService.AdvanceOrderStatus(ctx, id, status)
+}
+
+func (c *checkoutTest) start(t *testing.T) *model.Saga {
+	t.Helper()
+	saga, err := c.checkout.StartCheckout(context.Background(), ordermodel.CreateOrderRequest{
+		CustomerID: 7,
+		Items:      []ordermodel.CreateOrderItemRequest{{ProductID: 10, Name: "Teclado", Price: 150, Quantity: 2}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return saga
+}
+
+func (c *checkoutTest) reply(t *testing.T, saga *model.Saga, routingKey string, data map[string]any) *model.Saga {
+	t.Helper()
+	ctx := context.Background()
+	event := mq.OrderEvent{RoutingKey: routingKey, OrderID: int(saga.OrderID), Data: data}
+	if err := c.checkout.HandleReply(ctx, event); err != nil {
+		t.Fatalf("%s: %v", routingKey, err)
+	}
+	saga, err := c.checkout.GetSaga(ctx, saga.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return saga
+}
+
+// commands devolve as routing keys publicadas por PublishTo, na ordem.
+func (c *checkoutTest) commands() []string {
+	var keys []string
+	for _, target := range c.publisher.Targets() {
+		if target.RoutingKey != "" {
+			keys = append(keys, target.RoutingKey)
+		}
+	}
+	return keys
+}
+
+func (c *checkoutTest) orderStatus(t *testing.T, saga *model.Saga) ordermodel.OrderStatus {
+	t.Helper()
+	order, err := c.orders.GetOrderByID(context.Background(), saga.OrderID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return order.Status
+}
+
+func assertCommands(t *testing.T, got []string, want ...string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("comandos = %v, esperado %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("comandos = %v, esperado %v", got, want)
+		}
+	}
+}
+
+var testCheckoutConfig = config.CheckoutConfig{
+	StockTimeout:   time.Minute,
+	PaymentTimeout: time.Minute,
+	RetryInterval:  time.Minute,
+}
+
+func TestCheckoutCompletes(t *testing.T) {
+	c := newCheckoutTest(t, testCheckoutConfig)
+
+	saga := c.start(t)
+	if saga.Status != model.SagaRunning || saga.Step(model.StepReserveStock).Status != model.StepWaiting {
+		t.Fatalf("saga = %+v, esperado aguardando o estoque", saga)
+	}
+	assertCommands(t, c.commands(), CommandReserveStock)
+
+	saga = c.reply(t, saga, "inventory.reserved", map[string]any{"reservation_id": "res-1"})
+	if saga.Step(model.StepReserveStock).Reference != "res-1" || saga.CurrentStep != model.StepRequestPayment {
+		t.Fatalf("saga = %+v, esperado reserva res-1 e pagamento em andamento", saga)
+	}
+	assertCommands(t, c.commands(), CommandReserveStock, CommandRequestPayment)
+
+	// Entregas repetidas não reenviam comandos
+	c.reply(t, saga, "inventory.reserved", map[string]any{"reservation_id": "res-2"})
+	saga = c.reply(t, saga, "payment.authorized", map[string]any{"payment_id": "pay-1"})
+	c.reply(t, saga, "payment.authorized", map[string]any{"payment_id": "pay-1"})
+
+	if saga.Status != model.SagaCompleted || saga.NextRunAt != nil || saga.Step(model.StepReserveStock).Reference != "res-1" {
+		t.Fatalf("saga = %+v, esperado completed", saga)
+	}
+	for _, step := range saga.Steps {
+		if step.Status != model.StepCompleted {
+			t.Errorf("passo %s = %s, esperado completed", step.Name, step.Status)
+		}
+	}
+	assertCommands(t, c.commands(), CommandReserveStock, CommandRequestPayment)
+	if status := c.orderStatus(t, saga); status != ordermodel.StatusConfirmed {
+		t.Errorf("pedido = %s, esperado confirmed", status)
+	}
+}
+
+func TestCheckoutCompensatesPaymentFailure(t *testing.T) {
+	c := newCheckoutTest(t, testCheckoutConfig)
+
+	saga := c.start(t)
+	saga = c.reply(t, saga, "inventory.reserved", map[string]any{"reservation_id": "res-1"})
+	saga = c.reply(t, saga, "payment.failed", map[string]any{"reason": "cartão recusado"})
+
+	if saga.Status != model.SagaFailed || saga.Error != "request_payment: payment.failed: cartão recusado" {
+		t.Fatalf("saga = %s (%q), esperado failed pelo pagamento", saga.Status, saga.Error)
+	}
+	if step := saga.Step(model.StepReserveStock); step.Status != model.StepCompensated || step.CompensatedAt == nil {
+		t.Errorf("reserva = %+v, esperado compensated", step)
+	}
+	if step := saga.Step(model.StepConfirmOrder); step.Status != model.StepPending {
+		t.Errorf("confirmação = %s, esperado pending", step.Status)
+	}
+
+	// O pagamento recusado não é compensado; a reserva é liberada pelo ID
+	assertCommands(t, c.commands(), CommandReserveStock, CommandRequestPayment, CommandReleaseStock)
+	release := c.publisher.EventsOfType(CommandReleaseStock)[0].Data.(map[string]any)
+	if release["reservation_id"] != "res-1" || release["saga_id"] != saga.ID {
+		t.Errorf("inventory.release = %v", release)
+	}
+	if status := c.orderStatus(t, saga); status != ordermodel.StatusFailed {
+		t.Errorf("pedido = %s, esperado failed", status)
+	}
+}
+
+func TestCheckoutTimeoutAfterRestart(t *testing.T) {
+	cfg := testCheckoutConfig
+	cfg.PaymentTimeout = 10 * time.Millisecond
+	c := newCheckoutTest(t, cfg)
+	ctx := context.Background()
+
+	saga := c.start(t)
+	saga = c.reply(t, saga, "inventory.reserved", map[string]any{"reservation_id": "res-1"})
+
+	// Outra instância retoma a saga pelo banco depois do prazo do pagamento
+	time.Sleep(20 * time.Millisecond)
+	resumed := c.restart()
+	if err := resumed.ResumeDue(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	saga, err := resumed.GetSaga(ctx, saga.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saga.Status != model.SagaFailed || saga.Step(model.StepRequestPayment).Error != "prazo esgotado" {
+		t.Fatalf("saga = %+v, esperado failed por prazo", saga)
+	}
+
+	// O pagamento sem resposta pode ter sido feito: também é cancelado
+	assertCommands(t, c.commands(),
+		CommandReserveStock, CommandRequestPayment, CommandVoidPayment, CommandReleaseStock)
+
+	// A autorização atrasada não reabre a saga
+	saga = c.reply(t, saga, "payment.authorized", map[string]any{"payment_id": "pay-1"})
+	if saga.Status != model.SagaFailed || len(c.commands()) != 4 {
+		t.Errorf("saga = %s com %d comandos depois da resposta atrasada", saga.Status, len(c.commands()))
+	}
+	if status := c.orderStatus(t, saga); status != ordermodel.StatusFailed {
+		t.Errorf("pedido = %s, esperado failed", status)
+	}
+}
+
+func TestCheckoutRetriesAfterPublishFailure(t *testing.T) {
+	c := newCheckoutTest(t, testCheckoutConfig)
+	ctx := context.Background()
+
+	// Broker fora: a saga é criada, mas o primeiro comando fica para depois
+	c.publisher.Err = mq.ErrPublisherClosed
+	saga := c.start(t)
+	if step := saga.Step(model.StepReserveStock); step.Status != model.StepPending || saga.NextRunAt == nil {
+		t.Fatalf("saga = %+v, esperado reserva pendente e reagendada", saga)
+	}
+
+	c.publisher.Err = nil
+	if err := c.checkout.ResumeDue(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(c.commands()) != 0 {
+		t.Fatalf("comandos = %v antes de RetryInterval", c.commands())
+	}
+
+	// Vencido o RetryInterval, ResumeDue envia o comando
+	if err := c.checkout.(*checkoutService).repo.Reschedule(ctx, saga.ID, time.Now().UTC()); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.checkout.ResumeDue(ctx); err != nil {
+		t.Fatal(err)
+	}
+	assertCommands(t, c.commands(), CommandReserveStock)
+}
+
+func TestCheckoutConfirmationRetriesOnDatabaseError(t *testing.T) {
+	c := newCheckoutTest(t, testCheckoutConfig)
+	ctx := context.Background()
+
+	saga := c.start(t)
+	saga = c.reply(t, saga, "inventory.reserved", map[string]any{"reservation_id": "res-1"})
+
+	// Banco instável na confirmação: a resposta volta à fila e o pagamento
+	// autorizado não é cancelado
+	c.flaky.advanceErr = errors.New("connection reset by peer")
+	authorized := mq.OrderEvent{RoutingKey: "payment.authorized", OrderID: int(saga.OrderID), Data: map[string]any{"payment_id": "pay-1"}}
+	if err := c.checkout.HandleReply(ctx, authorized); err == nil {
+		t.Fatal("HandleReply deveria devolver o erro do banco")
+	}
+	saga, err := c.checkout.GetSaga(ctx, saga.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saga.Status != model.SagaRunning || saga.Step(model.StepRequestPayment).Status != model.StepWaiting {
+		t.Fatalf("saga = %+v, esperado aguardando o pagamento", saga)
+	}
+	assertCommands(t, c.commands(), CommandReserveStock, CommandRequestPayment)
+
+	// Reentregue com o banco de volta, a saga termina
+	c.flaky.advanceErr = nil
+	saga = c.reply(t, saga, "payment.authorized", map[string]any{"payment_id": "pay-1"})
+	if saga.Status != model.SagaCompleted {
+		t.Fatalf("saga = %s (%q), esperado completed", saga.Status, saga.Error)
+	}
+	assertCommands(t, c.commands(), CommandReserveStock, CommandRequestPayment)
+	if status := c.orderStatus(t, saga); status != ordermodel.StatusConfirmed {
+		t.Errorf("pedido = %s, esperado confirmed", status)
+	}
+}
+
+// brokenSagas falha ao gravar a saga.
+type brokenSagas struct {
+	repository.SagaRepository
+}
+
+func (brokenSagas) Create(context.Context, *model.Saga) error {
+	return errors.New("disk full")
+}
+
+func TestCheckoutPublishesOrderCreatedAfterCommit(t *testing.T) {
+	c := newCheckoutTest(t, testCheckoutConfig)
+	ctx := context.Background()
+	req := ordermodel.CreateOrderRequest{
+		CustomerID: 7,
+		Items:      []ordermodel.CreateOrderItemRequest{{ProductID: 10, Name: "Teclado", Price: 150, Quantity: 2}},
+	}
+
+	// Sem a saga, o pedido é desfeito e o order.created não sai
+	broken := c.sagas(brokenSagas{})
+	if _, err := broken.StartCheckout(ctx, req); err == nil {
+		t.Fatal("StartCheckout deveria falhar sem gravar a saga")
+	}
+	if events := c.publisher.EventsOfType("created"); len(events) != 0 {
+		t.Fatalf("order.created publicado para pedido desfeito: %v", events)
+	}
+	if orders, _ := c.orders.GetOrdersByCustomer(ctx, 7, 10, 0); len(orders) != 0 {
+		t.Fatalf("%d pedidos gravados, esperado 0", len(orders))
+	}
+
+	saga := c.start(t)
+	events := c.publisher.EventsOfType("created")
+	if len(events) != 1 || uint(events[0].OrderID) != saga.OrderID {
+		t.Fatalf("order.created = %v, esperado um para o pedido %d", events, saga.OrderID)
+	}
+}
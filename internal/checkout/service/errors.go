@@ -0,0 +1,8 @@
+package service
+
+import "errors"
+
+// Erros de domínio retornados (com wrap) pelo CheckoutService.
+var (
+	ErrSagaNotFound = errors.New("saga não encontrada")
+)
@@ -0,0 +1,24 @@
+package service
+
+import (
+	"order-service/pkg/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	sagasFinished = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "checkout",
+		Name:      "sagas_finished_total",
+		Help:      "Sagas de checkout encerradas, por status (completed ou failed).",
+	}, []string{"status"})
+
+	stepTimeouts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "checkout",
+		Name:      "step_timeouts_total",
+		Help:      "Passos de checkout sem resposta no prazo.",
+	}, []string{"step"})
+)
@@ -0,0 +1,331 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/goccy/go-yaml"
+	"github.com/joho/godotenv"
+	"github.com/pelletier/go-toml/v2"
+)
+
+// field é um valor configurável, encontrado via tags em Config.
+type field struct {
+	path string
+	// Caminho na seção antiga do arquivo, se a seção foi renomeada
+	legacyPath string
+	env        string
+	legacy     string
+	def        string
+	secret     string
+	value      reflect.Value
+}
+
+type setting struct {
+	raw    string
+	source string
+}
+
+// Load monta a configuração em camadas, da menor para a maior prioridade:
+// defaults, arquivo YAML ou TOML (-config ou CONFIG_FILE), variáveis de
+// ambiente (incluindo o .env, se existir, e <VAR>_FILE para segredos) e
+// flags (-database.host=...). Todos os problemas encontrados voltam juntos
+// em um único erro.
+//
+// -print-config imprime a configuração efetiva sem segredos e encerra o processo.
+func Load(args []string) (*Config, error) {
+	// O .env é opcional: em containers as variáveis costumam vir do ambiente.
+	// Variáveis já definidas no ambiente não são sobrescritas.
+	if err := godotenv.Load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to load .env: %w", err)
+	}
+
+	cfg := &Config{sources: make(map[string]string)}
+	fields := fieldsOf(cfg)
+	settings := make(map[string]setting, len(fields))
+	var errs []error
+
+	for _, f := range fields {
+		if f.def != "" {
+			settings[f.path] = setting{raw: f.def, source: "default"}
+		}
+	}
+
+	flags := flag.NewFlagSet(filepath.Base(os.Args[0]), flag.ExitOnError)
+	configFile := flags.String("config", os.Getenv("CONFIG_FILE"), "arquivo de configuração (.yaml, .yml ou .toml)")
+	printConfig := flags.Bool("print-config", false, "imprime a configuração efetiva, sem segredos, e sai")
+	flagValues := make(map[string]string)
+	for _, f := range fields {
+		set := func(value string) error {
+			flagValues[f.path] = value
+			return nil
+		}
+		usage := fmt.Sprintf("sobrescreve %s", f.env)
+		if f.value.Kind() == reflect.Bool {
+			flags.BoolFunc(f.path, usage, set)
+		} else {
+			flags.Func(f.path, usage, set)
+		}
+	}
+	flags.Parse(args)
+	cfg.args = flags.Args()
+
+	if *configFile != "" {
+		values, err := readFile(*configFile)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		paths := make([]string, 0, len(values))
+		for path := range values {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+
+		for _, path := range paths {
+			raw := values[path]
+			f, ok := lookupField(fields, path)
+			if !ok {
+				errs = append(errs, fmt.Errorf("%s: unknown key %q", *configFile, path))
+				continue
+			}
+			// A chave da seção antiga só vale quando a atual não está no arquivo
+			if _, current := values[f.path]; path != f.path && current {
+				continue
+			}
+			settings[f.path] = setting{raw: raw, source: "file " + *configFile}
+		}
+	}
+
+	for _, f := range fields {
+		// O nome antigo só vale quando o atual não está definido
+		for _, env := range []string{f.env, f.legacy} {
+			if env == "" {
+				continue
+			}
+			value, fromFile := os.Getenv(env), os.Getenv(env+"_FILE")
+			switch {
+			case value != "" && fromFile != "":
+				errs = append(errs, fmt.Errorf("%s: set either %s or %s_FILE, not both", f.path, env, env))
+			case fromFile != "":
+				secret, err := os.ReadFile(fromFile)
+				if err != nil {
+					errs = append(errs, fmt.Errorf("%s: failed to read %s_FILE: %w", f.path, env, err))
+					break
+				}
+				settings[f.path] = setting{raw: strings.TrimRight(string(secret), "\r\n"), source: "env " + env + "_FILE"}
+			case value != "":
+				settings[f.path] = setting{raw: value, source: "env " + env}
+			default:
+				continue
+			}
+			break
+		}
+	}
+
+	for path, raw := range flagValues {
+		settings[path] = setting{raw: raw, source: "flag -" + path}
+	}
+
+	for _, f := range fields {
+		s, ok := settings[f.path]
+		if !ok {
+			continue
+		}
+		if err := assign(f.value, s.raw); err != nil {
+			errs = append(errs, fmt.Errorf("%s (%s): %w", f.path, s.source, err))
+			continue
+		}
+		cfg.sources[f.path] = s.source
+	}
+
+	applyEnvDefaults(cfg, func(path string) bool {
+		_, ok := cfg.sources[path]
+		return ok
+	})
+
+	if err := cfg.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	var err error
+	if len(errs) > 0 {
+		err = fmt.Errorf("invalid configuration:\n%w", errors.Join(errs...))
+	}
+
+	if *printConfig {
+		cfg.Print(os.Stdout)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	return cfg, err
+}
+
+// Print escreve a configuração efetiva, um valor por linha com a camada de
+// origem. Segredos saem como [REDACTED] e URLs sem a senha.
+func (c *Config) Print(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, f := range fieldsOf(c) {
+		source, ok := c.sources[f.path]
+		if !ok {
+			source = "default"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", f.path, f.display(), source)
+	}
+	tw.Flush()
+}
+
+func (f field) display() string {
+	value := fmt.Sprint(f.value.Interface())
+	switch {
+	case value == "":
+		return `""`
+	case f.secret == "true":
+		return "[REDACTED]"
+	case f.secret == "url":
+		u, err := url.Parse(value)
+		if err != nil {
+			return "[REDACTED]"
+		}
+		return u.Redacted()
+	}
+	return value
+}
+
+func fieldsOf(cfg *Config) []field {
+	var fields []field
+
+	root := reflect.ValueOf(cfg).Elem()
+	for i := 0; i < root.NumField(); i++ {
+		sectionTag := root.Type().Field(i).Tag
+		section, ok := sectionTag.Lookup("config")
+		if !ok {
+			continue
+		}
+		legacySection := sectionTag.Get("legacy")
+
+		group := root.Field(i)
+		for j := 0; j < group.NumField(); j++ {
+			tag := group.Type().Field(j).Tag
+			f := field{
+				path:   section + "." + tag.Get("config"),
+				env:    tag.Get("env"),
+				legacy: tag.Get("legacy"),
+				def:    tag.Get("default"),
+				secret: tag.Get("secret"),
+				value:  group.Field(j),
+			}
+			if legacySection != "" {
+				f.legacyPath = legacySection + "." + tag.Get("config")
+			}
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// lookupField encontra o campo pelo caminho atual ou pelo da seção antiga.
+func lookupField(fields []field, path string) (field, bool) {
+	for _, f := range fields {
+		if f.path == path || (f.legacyPath != "" && f.legacyPath == path) {
+			return f, true
+		}
+	}
+	return field{}, false
+}
+
+func assign(v reflect.Value, raw string) error {
+	if v.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", raw)
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", raw)
+		}
+		v.SetBool(b)
+	case reflect.Int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", raw)
+		}
+		v.SetInt(int64(n))
+	case reflect.Float64:
+		n, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", raw)
+		}
+		v.SetFloat(n)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// readFile lê um arquivo YAML ou TOML e achata as seções em caminhos
+// (server.port, database.host, ...).
+func readFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	tree := make(map[string]any)
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &tree)
+	case ".toml":
+		err = toml.Unmarshal(data, &tree)
+	default:
+		return nil, fmt.Errorf("unsupported config file format %q (use .yaml, .yml or .toml)", ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	values := make(map[string]string)
+	flatten("", tree, values)
+	return values, nil
+}
+
+func flatten(prefix string, node map[string]any, values map[string]string) {
+	for key, value := range node {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+
+		switch child := value.(type) {
+		case map[string]any:
+			flatten(path, child, values)
+		case nil:
+			values[path] = ""
+		default:
+			values[path] = fmt.Sprint(child)
+		}
+	}
+}
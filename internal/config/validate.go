@@ -0,0 +1,192 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Validate confere campos obrigatórios, faixas e valores permitidos e
+// retorna todos os problemas de uma vez.
+func (c *Config) Validate() error {
+	v := &validator{}
+
+	v.port("server.port", c.Server.Port)
+	v.port("server.grpc_port", c.Server.GRPCPort)
+	v.oneOf("server.gin_mode", c.Server.GinMode, "debug", "release", "test")
+	v.check(c.Server.MaxBatchSize >= 1, "server.max_batch_size", "must be at least 1")
+	v.check(c.Server.ReadinessTimeout > 0, "server.readiness_timeout", "must be positive")
+	v.check(c.Server.ReadinessCacheTTL >= 0, "server.readiness_cache_ttl", "must not be negative")
+	v.check(c.Server.ShutdownTimeout > 0, "server.shutdown_timeout", "must be positive")
+	v.check(c.Server.RequestTimeout >= 0, "server.request_timeout", "must not be negative")
+	v.check(c.Server.BatchRequestTimeout >= 0, "server.batch_request_timeout", "must not be negative")
+	v.check(c.Server.ShutdownDelay >= 0 && c.Server.ShutdownDelay < c.Server.ShutdownTimeout,
+		"server.shutdown_delay", "must be between 0 and server.shutdown_timeout")
+	for _, origin := range c.Server.AllowedOrigins() {
+		u, err := url.Parse(origin)
+		v.check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "" && u.Path == "",
+			"server.stream_allowed_origins", fmt.Sprintf("invalid origin %q", origin))
+	}
+
+	if v.oneOf("database.driver", c.Database.Driver, "postgres", "sqlite") && c.Database.Driver == "sqlite" {
+		v.required("database.sqlite_path", c.Database.SQLitePath)
+		v.check(c.Database.ReplicaHosts == "", "database.replica_hosts", "is not supported with sqlite")
+	} else {
+		v.required("database.host", c.Database.Host)
+		v.port("database.port", c.Database.Port)
+		v.required("database.user", c.Database.User)
+		v.required("database.password", c.Database.Password)
+		v.required("database.name", c.Database.DBName)
+		v.oneOf("database.ssl_mode", c.Database.SSLMode, "disable", "allow", "prefer", "require", "verify-ca", "verify-full")
+		if _, err := time.LoadLocation(c.Database.TimeZone); err != nil || c.Database.TimeZone == "" {
+			v.add("database.timezone", fmt.Sprintf("unknown time zone %q", c.Database.TimeZone))
+		}
+		v.file("database.ssl_root_cert", c.Database.SSLRootCert)
+		v.file("database.ssl_cert", c.Database.SSLCert)
+		v.file("database.ssl_key", c.Database.SSLKey)
+		v.check((c.Database.SSLCert == "") == (c.Database.SSLKey == ""), "database.ssl_key", "must be set together with database.ssl_cert")
+		for _, replica := range c.Database.Replicas() {
+			if _, port, err := net.SplitHostPort(replica); err != nil || port == "" {
+				v.add("database.replica_hosts", fmt.Sprintf("invalid replica %q", replica))
+			} else {
+				v.port("database.replica_hosts", port)
+			}
+		}
+	}
+	v.oneOf("database.migrations_mode", c.Database.MigrationsMode, "auto", "check", "off")
+	v.check(c.Database.MaxOpenConns >= 0, "database.max_open_conns", "must not be negative")
+	v.check(c.Database.MaxIdleConns >= 0 && (c.Database.MaxOpenConns == 0 || c.Database.MaxIdleConns <= c.Database.MaxOpenConns),
+		"database.max_idle_conns", "must be between 0 and database.max_open_conns")
+	v.check(c.Database.ConnMaxLifetime >= 0, "database.conn_max_lifetime", "must not be negative")
+	v.check(c.Database.ConnMaxIdleTime >= 0, "database.conn_max_idle_time", "must not be negative")
+	v.check(c.Database.ConnectTimeout == 0 || c.Database.ConnectTimeout >= time.Second, "database.connect_timeout", "must be 0 or at least 1s")
+	v.check(c.Database.StatementTimeout >= 0, "database.statement_timeout", "must not be negative")
+	v.check(c.Database.ConnectRetryTimeout >= 0, "database.connect_retry_timeout", "must not be negative")
+	v.check(c.Database.SlowQueryThreshold > 0, "database.slow_query_threshold", "must be positive")
+	v.check(c.Database.TxMaxAttempts >= 1, "database.tx_max_attempts", "must be at least 1")
+
+	if v.oneOf("mq.broker", c.MQ.Broker, "rabbitmq", "nats", "redis", "memory") && c.MQ.Broker != "memory" &&
+		v.required("mq.url", c.MQ.URL) {
+		schemes := map[string][]string{
+			"rabbitmq": {"amqp", "amqps"},
+			"nats":     {"nats", "tls"},
+			"redis":    {"redis", "rediss"},
+		}[c.MQ.Broker]
+		u, err := url.Parse(c.MQ.URL)
+		v.check(err == nil && slices.Contains(schemes, u.Scheme) && u.Host != "",
+			"mq.url", fmt.Sprintf("must be a URL with scheme %s for the %s broker", strings.Join(schemes, " or "), c.MQ.Broker))
+	}
+	v.required("mq.exchange", c.MQ.Exchange)
+	if c.MQ.Broker == "nats" {
+		// O exchange vira o nome da stream, que não aceita separadores de subject
+		v.check(!strings.ContainsAny(c.MQ.Exchange, ".*> \t/\\"), "mq.exchange", "must not contain '.', '*', '>', '/', '\\' or spaces with nats")
+	}
+	v.check(c.MQ.PublishTimeout >= 0, "mq.publish_timeout", "must not be negative")
+	v.check(c.MQ.Prefetch >= 1, "mq.prefetch", "must be at least 1")
+	v.check(c.MQ.AckWait >= time.Second, "mq.ack_wait", "must be at least 1s")
+	v.check(c.MQ.Retention >= time.Minute, "mq.retention", "must be at least 1m")
+	v.check(c.MQ.EventHistorySize >= 0, "mq.event_history_size", "must not be negative")
+	v.check(c.MQ.ReplayRate >= 1, "mq.replay_rate", "must be at least 1")
+	v.required("mq.status_events_queue", c.MQ.StatusEventsQueue)
+	v.required("mq.summary_queue", c.MQ.SummaryQueue)
+
+	v.required("webhook.queue", c.Webhook.Queue)
+	v.check(c.Webhook.PollInterval > 0, "webhook.poll_interval", "must be positive")
+	v.check(c.Webhook.RequestTimeout > 0, "webhook.request_timeout", "must be positive")
+	v.check(c.Webhook.MaxAttempts >= 1, "webhook.max_attempts", "must be at least 1")
+	v.check(c.Webhook.InitialBackoff > 0, "webhook.initial_backoff", "must be positive")
+	v.check(c.Webhook.MaxBackoff >= c.Webhook.InitialBackoff, "webhook.max_backoff", "must not be less than webhook.initial_backoff")
+	v.check(c.Webhook.DisableAfter >= 1, "webhook.disable_after", "must be at least 1")
+
+	v.required("checkout.queue", c.Checkout.Queue)
+	v.check(c.Checkout.StockTimeout > 0, "checkout.stock_timeout", "must be positive")
+	v.check(c.Checkout.PaymentTimeout > 0, "checkout.payment_timeout", "must be positive")
+	v.check(c.Checkout.PollInterval > 0, "checkout.poll_interval", "must be positive")
+	v.check(c.Checkout.RetryInterval > 0, "checkout.retry_interval", "must be positive")
+
+	v.check(c.EventStore.SnapshotEvery >= 0, "event_store.snapshot_every", "must not be negative")
+	v.check(c.EventStore.PublishInterval > 0, "event_store.publish_interval", "must be positive")
+
+	if v.oneOf("cache.backend", c.Cache.Backend, "none", "memory", "redis") {
+		switch c.Cache.Backend {
+		case "memory":
+			v.check(c.Cache.Size >= 1, "cache.size", "must be at least 1")
+		case "redis":
+			if v.required("cache.redis_url", c.Cache.RedisURL) {
+				u, err := url.Parse(c.Cache.RedisURL)
+				v.check(err == nil && (u.Scheme == "redis" || u.Scheme == "rediss") && u.Host != "",
+					"cache.redis_url", "must be a URL with scheme redis or rediss")
+			}
+		}
+	}
+	v.check(c.Cache.TTL > 0, "cache.ttl", "must be positive")
+
+	if v.oneOf("tracing.exporter", c.Tracing.Exporter, "none", "otlp", "stdout", "file") {
+		switch c.Tracing.Exporter {
+		case "otlp":
+			v.required("tracing.endpoint", c.Tracing.Endpoint)
+		case "file":
+			v.required("tracing.file", c.Tracing.FilePath)
+		}
+	}
+	v.check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sample_ratio", "must be between 0 and 1")
+
+	v.oneOf("log.level", c.Log.Level, "debug", "info", "warn", "error")
+	v.oneOf("log.format", c.Log.Format, "json", "text")
+
+	return errors.Join(v.errs...)
+}
+
+type validator struct {
+	errs []error
+}
+
+func (v *validator) add(path, problem string) {
+	v.errs = append(v.errs, fmt.Errorf("%s (%s): %s", path, envName(path), problem))
+}
+
+func (v *validator) check(ok bool, path, problem string) bool {
+	if !ok {
+		v.add(path, problem)
+	}
+	return ok
+}
+
+func (v *validator) required(path, value string) bool {
+	return v.check(value != "", path, "is required")
+}
+
+func (v *validator) oneOf(path, value string, allowed ...string) bool {
+	return v.check(slices.Contains(allowed, value), path, fmt.Sprintf("must be one of %v, got %q", allowed, value))
+}
+
+func (v *validator) port(path, value string) {
+	n, err := strconv.Atoi(value)
+	v.check(err == nil && n >= 1 && n <= 65535, path, fmt.Sprintf("invalid port %q", value))
+}
+
+// file confere, quando preenchido, se o arquivo existe.
+func (v *validator) file(path, value string) {
+	if value == "" {
+		return
+	}
+	_, err := os.Stat(value)
+	v.check(err == nil, path, fmt.Sprintf("file %q not found", value))
+}
+
+// envName devolve a variável de ambiente do caminho, para as mensagens
+// apontarem o nome que o operador de fato configura.
+func envName(path string) string {
+	for _, f := range fieldsOf(&Config{}) {
+		if f.path == path {
+			return f.env
+		}
+	}
+	return path
+}
@@ -0,0 +1,20 @@
+package handler
+
+import (
+	"strconv"
+
+	"order-service/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OrderContext anexa o :id da rota ao contexto da requisição, para que os
+// logs (inclusive o de acesso) saiam com order_id.
+func OrderContext() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if id, err := strconv.ParseUint(c.Param("id"), 10, 32); err == nil {
+			c.Request = c.Request.WithContext(logger.WithOrderID(c.Request.Context(), uint(id)))
+		}
+		c.Next()
+	}
+}
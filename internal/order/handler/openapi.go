@@ -0,0 +1,301 @@
+package handler
+
+import (
+	"net/http"
+
+	"order-service/internal/order/model"
+	"order-service/pkg/health"
+	"order-service/pkg/logger"
+	"order-service/pkg/mq"
+	"order-service/pkg/openapi"
+)
+
+// OpenAPISpec descreve as rotas registradas em cmd/order-service. Os schemas
+// são gerados a partir dos DTOs, então mudanças nas structs aparecem aqui
+// automaticamente; novas rotas precisam ser adicionadas manualmente.
+func OpenAPISpec(version string) *openapi.Document {
+	doc := openapi.NewDocument("Order Service API", version)
+
+	doc.RegisterEnum(model.OrderStatus(""),
+		model.StatusPending,
+		model.StatusConfirmed,
+		model.StatusPaid,
+		model.StatusShipped,
+		model.StatusDelivered,
+		model.StatusCancelled,
+		model.StatusFailed,
+	)
+	createOrder := doc.Register(model.CreateOrderRequest{})
+	order := doc.Register(model.OrderResponse{})
+	orderList := doc.Register(OrderListResponse{})
+	updateStatus := doc.Register(UpdateStatusRequest{})
+	doc.RegisterEnum(model.BatchMode(""), model.BatchAtomic, model.BatchPartial)
+	batchCreate := doc.Register(model.BatchCreateOrderRequest{})
+	batchUpdate := doc.Register(model.BatchUpdateStatusRequest{})
+	batchResult := doc.Register(model.BatchResponse{})
+	doc.RegisterEnum(model.ReplayStatus(""), model.ReplayRunning, model.ReplayCompleted, model.ReplayCancelled, model.ReplayFailed)
+	replayRequest := doc.Register(model.ReplayRequest{})
+	replay := doc.Register(model.ReplayResponse{})
+	errorResponse := doc.Register(ErrorResponse{})
+	orderEvent := doc.Register(mq.OrderEvent{})
+
+	idParam := openapi.PathParam("id", openapi.Integer())
+	errorContent := openapi.JSONContent(errorResponse)
+
+	doc.AddOperation(http.MethodGet, "/health", &openapi.Operation{
+		OperationID: "healthCheck",
+		Tags:        []string{"system"},
+		Responses: map[string]*openapi.Response{
+			"200": {Description: "Serviço disponível"},
+		},
+	})
+
+	doc.AddOperation(http.MethodGet, "/livez", &openapi.Operation{
+		OperationID: "livenessProbe",
+		Summary:     "Processo em execução (não consulta dependências)",
+		Tags:        []string{"system"},
+		Responses: map[string]*openapi.Response{
+			"200": {Description: "Processo ativo", Content: openapi.JSONContent(doc.Register(health.Liveness{}))},
+		},
+	})
+
+	readiness := openapi.JSONContent(doc.Register(health.Report{}))
+	doc.AddOperation(http.MethodGet, "/readyz", &openapi.Operation{
+		OperationID: "readinessProbe",
+		Summary:     "Postgres, RabbitMQ e demais verificações registradas",
+		Tags:        []string{"system"},
+		Responses: map[string]*openapi.Response{
+			"200": {Description: "Pronto para receber tráfego", Content: readiness},
+			"503": {Description: "Alguma dependência indisponível", Content: readiness},
+		},
+	})
+
+	doc.AddOperation(http.MethodGet, "/openapi.json", &openapi.Operation{
+		OperationID: "getOpenAPISpec",
+		Tags:        []string{"system"},
+		Responses: map[string]*openapi.Response{
+			"200": {Description: "Este documento"},
+		},
+	})
+
+	doc.AddOperation(http.MethodGet, "/metrics", &openapi.Operation{
+		OperationID: "getMetrics",
+		Summary:     "Métricas no formato de exposição do Prometheus",
+		Tags:        []string{"system"},
+		Responses: map[string]*openapi.Response{
+			"200": {Description: "Métricas", Content: map[string]*openapi.MediaType{
+				"text/plain": {Schema: openapi.String()},
+			}},
+		},
+	})
+
+	logLevel := openapi.JSONContent(doc.Register(logger.LevelRequest{}))
+
+	doc.AddOperation(http.MethodGet, "/debug/log-level", &openapi.Operation{
+		OperationID: "getLogLevel",
+		Tags:        []string{"system"},
+		Responses: map[string]*openapi.Response{
+			"200": {Description: "Nível de log atual", Content: logLevel},
+		},
+	})
+
+	doc.AddOperation(http.MethodPut, "/debug/log-level", &openapi.Operation{
+		OperationID: "setLogLevel",
+		Summary:     "Altera o nível de log em tempo de execução (debug, info, warn, error)",
+		Tags:        []string{"system"},
+		RequestBody: &openapi.RequestBody{Required: true, Content: logLevel},
+		Responses: map[string]*openapi.Response{
+			"200": {Description: "Nível de log aplicado", Content: logLevel},
+			"400": {Description: "Nível inválido", Content: errorContent},
+		},
+	})
+
+	doc.AddOperation(http.MethodPost, "/api/v1/orders", &openapi.Operation{
+		OperationID: "createOrder",
+		Summary:     "Cria um pedido",
+		Tags:        []string{"orders"},
+		RequestBody: &openapi.RequestBody{
+			Required: true,
+			Content:  openapi.JSONContent(createOrder),
+		},
+		Responses: map[string]*openapi.Response{
+			"201": {Description: "Pedido criado", Content: openapi.JSONContent(order)},
+			"400": {Description: "Dados inválidos", Content: errorContent},
+			"500": {Description: "Erro ao criar pedido", Content: errorContent},
+		},
+	})
+
+	doc.AddOperation(http.MethodGet, "/api/v1/orders", &openapi.Operation{
+		OperationID: "listOrdersByCustomer",
+		Summary:     "Lista os pedidos de um cliente, sem os items (read model atualizado pelos eventos)",
+		Description: "Mudança incompatível: cada elemento de orders é um OrderSummary (item_count e total_quantity, sem items), não mais um OrderResponse. Os items de um pedido estão em GET /api/v1/orders/{id}.",
+		Tags:        []string{"orders"},
+		Parameters: []openapi.Parameter{
+			openapi.QueryParam("customer_id", true, openapi.Integer()),
+			openapi.QueryParam("limit", false, openapi.Integer()),
+			openapi.QueryParam("offset", false, openapi.Integer()),
+		},
+		Responses: map[string]*openapi.Response{
+			"200": {Description: "Pedidos do cliente", Content: openapi.JSONContent(orderList)},
+			"400": {Description: "Parâmetros inválidos", Content: errorContent},
+			"500": {Description: "Erro ao buscar pedidos", Content: errorContent},
+		},
+	})
+
+	doc.AddOperation(http.MethodPost, "/api/v1/orders/batch", &openapi.Operation{
+		OperationID: "createOrdersBatch",
+		Summary:     "Cria vários pedidos (modo atomic ou partial)",
+		Tags:        []string{"orders"},
+		RequestBody: &openapi.RequestBody{
+			Required: true,
+			Content:  openapi.JSONContent(batchCreate),
+		},
+		Responses: map[string]*openapi.Response{
+			"201": {Description: "Todos os pedidos criados", Content: openapi.JSONContent(batchResult)},
+			"207": {Description: "Parte dos pedidos criada", Content: openapi.JSONContent(batchResult)},
+			"400": {Description: "Dados inválidos", Content: errorContent},
+			"413": {Description: "Lote muito grande", Content: errorContent},
+			"422": {Description: "Nenhum pedido criado", Content: openapi.JSONContent(batchResult)},
+			"500": {Description: "Erro ao criar pedidos", Content: errorContent},
+		},
+	})
+
+	doc.AddOperation(http.MethodPost, "/api/v1/orders/batch/status", &openapi.Operation{
+		OperationID: "updateOrderStatusesBatch",
+		Summary:     "Altera o status de vários pedidos (modo atomic ou partial)",
+		Tags:        []string{"orders"},
+		RequestBody: &openapi.RequestBody{
+			Required: true,
+			Content:  openapi.JSONContent(batchUpdate),
+		},
+		Responses: map[string]*openapi.Response{
+			"200": {Description: "Todos os status alterados", Content: openapi.JSONContent(batchResult)},
+			"207": {Description: "Parte dos status alterada", Content: openapi.JSONContent(batchResult)},
+			"400": {Description: "Dados inválidos", Content: errorContent},
+			"413": {Description: "Lote muito grande", Content: errorContent},
+			"422": {Description: "Nenhum status alterado", Content: openapi.JSONContent(batchResult)},
+			"500": {Description: "Erro ao atualizar status", Content: errorContent},
+		},
+	})
+
+	doc.AddOperation(http.MethodGet, "/api/v1/orders/:id", &openapi.Operation{
+		OperationID: "getOrder",
+		Summary:     "Busca um pedido",
+		Tags:        []string{"orders"},
+		Parameters:  []openapi.Parameter{idParam},
+		Responses: map[string]*openapi.Response{
+			"200": {Description: "Pedido", Content: openapi.JSONContent(order)},
+			"400": {Description: "ID inválido", Content: errorContent},
+			"404": {Description: "Pedido não encontrado", Content: errorContent},
+		},
+	})
+
+	doc.AddOperation(http.MethodPut, "/api/v1/orders/:id/status", &openapi.Operation{
+		OperationID: "updateOrderStatus",
+		Summary:     "Altera o status de um pedido",
+		Tags:        []string{"orders"},
+		Parameters:  []openapi.Parameter{idParam},
+		RequestBody: &openapi.RequestBody{
+			Required: true,
+			Content:  openapi.JSONContent(updateStatus),
+		},
+		Responses: map[string]*openapi.Response{
+			"200": {Description: "Pedido atualizado", Content: openapi.JSONContent(order)},
+			"400": {Description: "Transição inválida", Content: errorContent},
+		},
+	})
+
+	doc.AddOperation(http.MethodPut, "/api/v1/orders/:id/cancel", &openapi.Operation{
+		OperationID: "cancelOrder",
+		Summary:     "Cancela um pedido",
+		Tags:        []string{"orders"},
+		Parameters:  []openapi.Parameter{idParam},
+		Responses: map[string]*openapi.Response{
+			"204": {Description: "Pedido cancelado"},
+			"400": {Description: "Não é possível cancelar", Content: errorContent},
+		},
+	})
+
+	// Cliente autenticado, informado pelo gateway; os streams o exigem
+	customerScope := openapi.Parameter{
+		Name: "X-Customer-ID", In: "header", Required: true,
+		Description: "Cliente autenticado; só os pedidos dele podem ser acompanhados",
+		Schema:      openapi.String(),
+	}
+
+	doc.AddOperation(http.MethodGet, "/api/v1/orders/:id/events", &openapi.Operation{
+		OperationID: "streamOrderEvents",
+		Summary:     "Stream SSE dos eventos de um pedido (aceita Last-Event-ID)",
+		Tags:        []string{"orders", openapi.TagStreaming},
+		Parameters: []openapi.Parameter{
+			idParam,
+			customerScope,
+			{Name: "Last-Event-ID", In: "header", Schema: openapi.String()},
+			openapi.QueryParam("last_event_id", false, openapi.String()),
+		},
+		Responses: map[string]*openapi.Response{
+			"200": {Description: "Eventos do pedido", Content: map[string]*openapi.MediaType{
+				"text/event-stream": {Schema: orderEvent},
+			}},
+			"400": {Description: "ID inválido", Content: errorContent},
+			"401": {Description: "Sem X-Customer-ID", Content: errorContent},
+			"404": {Description: "Pedido não encontrado ou de outro cliente", Content: errorContent},
+			"500": {Description: "Erro ao buscar pedido", Content: errorContent},
+		},
+	})
+
+	doc.AddOperation(http.MethodGet, "/api/v1/customers/:customer_id/orders/ws", &openapi.Operation{
+		OperationID: "watchCustomerOrders",
+		Summary:     "WebSocket com os eventos dos pedidos de um cliente",
+		Tags:        []string{"orders", openapi.TagStreaming},
+		Parameters: []openapi.Parameter{
+			openapi.PathParam("customer_id", openapi.Integer()),
+			customerScope,
+			openapi.QueryParam("last_event_id", false, openapi.String()),
+		},
+		Responses: map[string]*openapi.Response{
+			"101": {Description: "Conexão WebSocket; cada mensagem é um OrderEvent"},
+			"400": {Description: "customer_id inválido", Content: errorContent},
+			"401": {Description: "Sem X-Customer-ID", Content: errorContent},
+			"403": {Description: "Cliente diferente do autenticado ou origem não permitida", Content: errorContent},
+		},
+	})
+
+	replayIDParam := openapi.PathParam("id", openapi.String())
+
+	doc.AddOperation(http.MethodPost, "/api/v1/admin/replays", &openapi.Operation{
+		OperationID: "startReplay",
+		Summary:     "Republica eventos order.created com o estado atual dos pedidos selecionados (header replay)",
+		Tags:        []string{"admin"},
+		RequestBody: &openapi.RequestBody{Required: true, Content: openapi.JSONContent(replayRequest)},
+		Responses: map[string]*openapi.Response{
+			"202": {Description: "Replay iniciado", Content: openapi.JSONContent(replay)},
+			"400": {Description: "Filtros ou rate inválidos", Content: errorContent},
+			"500": {Description: "Erro ao iniciar replay", Content: errorContent},
+		},
+	})
+
+	doc.AddOperation(http.MethodGet, "/api/v1/admin/replays/:id", &openapi.Operation{
+		OperationID: "getReplay",
+		Summary:     "Andamento de um replay iniciado nesta réplica",
+		Tags:        []string{"admin"},
+		Parameters:  []openapi.Parameter{replayIDParam},
+		Responses: map[string]*openapi.Response{
+			"200": {Description: "Replay", Content: openapi.JSONContent(replay)},
+			"404": {Description: "Replay não encontrado", Content: errorContent},
+		},
+	})
+
+	doc.AddOperation(http.MethodDelete, "/api/v1/admin/replays/:id", &openapi.Operation{
+		OperationID: "cancelReplay",
+		Summary:     "Interrompe um replay em andamento",
+		Tags:        []string{"admin"},
+		Parameters:  []openapi.Parameter{replayIDParam},
+		Responses: map[string]*openapi.Response{
+			"200": {Description: "Cancelamento solicitado", Content: openapi.JSONContent(replay)},
+			"404": {Description: "Replay não encontrado", Content: errorContent},
+		},
+	})
+
+	return doc
+}
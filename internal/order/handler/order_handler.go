@@ -1,22 +1,28 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
 	"order-service/internal/order/model"
 	"order-service/internal/order/service"
+	"order-service/pkg/deadline"
 
 	"github.com/gin-gonic/gin"
 )
 
 type OrderHandler struct {
-	orderService service.OrderService
+	orderService   service.OrderService
+	summaryService service.SummaryService
+	maxBatchSize   int
 }
 
-func NewOrderHandler(orderService service.OrderService) *OrderHandler {
+func NewOrderHandler(orderService service.OrderService, summaryService service.SummaryService, maxBatchSize int) *OrderHandler {
 	return &OrderHandler{
-		orderService: orderService,
+		orderService:   orderService,
+		summaryService: summaryService,
+		maxBatchSize:   maxBatchSize,
 	}
 }
 
@@ -31,8 +37,11 @@ func (h *OrderHandler) CreateOrder(c *gin.Context) {
 		return
 	}
 
-	order, err := h.orderService.CreateOrder(req)
+	order, err := h.orderService.CreateOrder(c.Request.Context(), req)
 	if err != nil {
+		if deadline.Respond(c, err) {
+			return
+		}
 		c.JSON(http.StatusInternalServerError, ErrorResponse{
 			Error:   "Erro ao criar pedido",
 			Message: err.Error(),
@@ -54,8 +63,18 @@ func (h *OrderHandler) GetOrder(c *gin.Context) {
 		return
 	}
 
-	order, err := h.orderService.GetOrderByID(uint(id))
+	order, err := h.orderService.GetOrderByID(c.Request.Context(), uint(id))
 	if err != nil {
+		if deadline.Respond(c, err) {
+			return
+		}
+		if !errors.Is(err, service.ErrOrderNotFound) {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:   "Erro ao buscar pedido",
+				Message: err.Error(),
+			})
+			return
+		}
 		c.JSON(http.StatusNotFound, ErrorResponse{
 			Error:   "Pedido não encontrado",
 			Message: err.Error(),
@@ -101,8 +120,11 @@ func (h *OrderHandler) GetOrdersByCustomer(c *gin.Context) {
 		}
 	}
 
-	orders, err := h.orderService.GetOrdersByCustomer(uint(customerID), limit, offset)
+	orders, err := h.summaryService.ListCustomerOrders(c.Request.Context(), uint(customerID), limit, offset)
 	if err != nil {
+		if deadline.Respond(c, err) {
+			return
+		}
 		c.JSON(http.StatusInternalServerError, ErrorResponse{
 			Error:   "Erro ao buscar pedidos",
 			Message: err.Error(),
@@ -138,8 +160,11 @@ func (h *OrderHandler) UpdateOrderStatus(c *gin.Context) {
 		return
 	}
 
-	order, err := h.orderService.UpdateOrderStatus(uint(id), req.Status)
+	order, err := h.orderService.UpdateOrderStatus(c.Request.Context(), uint(id), req.Status)
 	if err != nil {
+		if deadline.Respond(c, err) {
+			return
+		}
 		c.JSON(http.StatusBadRequest, ErrorResponse{
 			Error:   "Erro ao atualizar status",
 			Message: err.Error(),
@@ -161,7 +186,10 @@ func (h *OrderHandler) CancelOrder(c *gin.Context) {
 		return
 	}
 
-	if err := h.orderService.CancelOrder(uint(id)); err != nil {
+	if err := h.orderService.CancelOrder(c.Request.Context(), uint(id)); err != nil {
+		if deadline.Respond(c, err) {
+			return
+		}
 		c.JSON(http.StatusBadRequest, ErrorResponse{
 			Error:   "Erro ao cancelar pedido",
 			Message: err.Error(),
@@ -172,16 +200,96 @@ func (h *OrderHandler) CancelOrder(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
-type ErrorResponse struct {
-	Error   string `json:"error"`
-	Message string `json:"message"`
+func (h *OrderHandler) CreateOrdersBatch(c *gin.Context) {
+	var req model.BatchCreateOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Dados inválidos",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if !h.checkBatchSize(c, len(req.Orders)) {
+		return
+	}
+
+	result, err := h.orderService.CreateOrders(c.Request.Context(), req)
+	if err != nil {
+		if deadline.Respond(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Erro ao criar pedidos",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(batchStatus(result, http.StatusCreated), result)
 }
 
+func (h *OrderHandler) UpdateOrderStatusesBatch(c *gin.Context) {
+	var req model.BatchUpdateStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Dados inválidos",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if !h.checkBatchSize(c, len(req.Updates)) {
+		return
+	}
+
+	result, err := h.orderService.UpdateOrderStatuses(c.Request.Context(), req)
+	if err != nil {
+		if deadline.Respond(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Erro ao atualizar status",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(batchStatus(result, http.StatusOK), result)
+}
+
+func (h *OrderHandler) checkBatchSize(c *gin.Context, size int) bool {
+	if h.maxBatchSize > 0 && size > h.maxBatchSize {
+		c.JSON(http.StatusRequestEntityTooLarge, ErrorResponse{
+			Error:   "Lote muito grande",
+			Message: "o lote aceita no máximo " + strconv.Itoa(h.maxBatchSize) + " itens",
+		})
+		return false
+	}
+	return true
+}
+
+// batchStatus retorna success quando todos os itens foram aplicados, 422 quando
+// nenhum foi e 207 (Multi-Status) quando apenas parte do lote foi aplicada.
+func batchStatus(result *model.BatchResponse, success int) int {
+	switch {
+	case result.Failed == 0:
+		return success
+	case result.Succeeded == 0:
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusMultiStatus
+	}
+}
+
+// ErrorResponse é o corpo de erro comum às rotas da API.
+type ErrorResponse = deadline.ErrorResponse
+
 type OrderListResponse struct {
-	Orders []model.OrderResponse `json:"orders"`
-	Count  int                   `json:"count"`
-	Limit  int                   `json:"limit"`
-	Offset int                   `json:"offset"`
+	Orders []model.OrderSummary `json:"orders"`
+	Count  int                  `json:"count"`
+	Limit  int                  `json:"limit"`
+	Offset int                  `json:"offset"`
 }
 
 type UpdateStatusRequest struct {
@@ -0,0 +1,153 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"order-service/internal/order/model"
+	"order-service/internal/order/repository"
+	"order-service/internal/order/service"
+	"order-service/pkg/db/dbtest"
+	"order-service/pkg/deadline"
+	"order-service/pkg/mq/mqtest"
+	"order-service/pkg/transaction"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCreateOrdersBatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const (
+		valid   = `{"customer_id": 1, "items": [{"product_id": 10, "name": "Teclado", "price": 150, "quantity": 1}]}`
+		invalid = `{"customer_id": 2, "items": []}`
+	)
+	tests := []struct {
+		name      string
+		body      string
+		want      int
+		created   int
+		published int
+	}{
+		{"acima do limite", `{"orders": [` + strings.Repeat(valid+",", 3) + valid + `]}`, http.StatusRequestEntityTooLarge, 0, 0},
+		{"sem pedidos", `{"orders": []}`, http.StatusBadRequest, 0, 0},
+		{"modo desconhecido", `{"mode": "best_effort", "orders": [` + valid + `]}`, http.StatusBadRequest, 0, 0},
+		{"atômico com item inválido", `{"orders": [` + valid + `,` + invalid + `]}`, http.StatusUnprocessableEntity, 0, 0},
+		{"parcial com item inválido", `{"mode": "partial", "orders": [` + valid + `,` + invalid + `]}`, http.StatusMultiStatus, 1, 1},
+		{"parcial sem itens válidos", `{"mode": "partial", "orders": [` + invalid + `]}`, http.StatusUnprocessableEntity, 0, 0},
+		{"todos válidos", `{"orders": [` + valid + `,` + valid + `,` + valid + `]}`, http.StatusCreated, 3, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := repository.NewMemoryOrderRepository()
+			publisher := mqtest.NewPublisher()
+			orders := service.NewOrderService(repo, transaction.NewMemoryManager(3, repo), publisher)
+			r := gin.New()
+			r.POST("/api/v1/orders/batch", NewOrderHandler(orders, nil, 3).CreateOrdersBatch)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/orders/batch", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, esperado %d: %s", w.Code, tt.want, w.Body)
+			}
+			if n, _ := repo.Count(req.Context()); n != int64(tt.created) {
+				t.Errorf("%d pedidos gravados, esperado %d", n, tt.created)
+			}
+			// Os eventos do lote saem em uma única publicação
+			if publisher.Calls() != tt.published || len(publisher.Events()) != tt.created {
+				t.Errorf("%d publicações com %d eventos, esperado %d com %d",
+					publisher.Calls(), len(publisher.Events()), tt.published, tt.created)
+			}
+
+			if w.Code == http.StatusMultiStatus {
+				var resp model.BatchResponse
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+					t.Fatal(err)
+				}
+				if !resp.Results[0].Success || resp.Results[1].Success || resp.Results[1].Error == "" {
+					t.Errorf("resultados = %+v", resp.Results)
+				}
+			}
+		})
+	}
+}
+
+func TestBatchStatus(t *testing.T) {
+	tests := []struct {
+		succeeded, failed int
+		want              int
+	}{
+		{3, 0, http.StatusCreated},
+		{0, 3, http.StatusUnprocessableEntity},
+		{2, 1, http.StatusMultiStatus},
+	}
+	for _, tt := range tests {
+		got := batchStatus(&model.BatchResponse{Succeeded: tt.succeeded, Failed: tt.failed}, http.StatusCreated)
+		if got != tt.want {
+			t.Errorf("%d ok / %d falhas = %d, esperado %d", tt.succeeded, tt.failed, got, tt.want)
+		}
+	}
+}
+
+func TestUpdateOrderStatusesBatchSizeLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	// O limite é conferido antes de chegar ao service
+	r.POST("/api/v1/orders/batch/status", NewOrderHandler(nil, nil, 1).UpdateOrderStatusesBatch)
+
+	body := `{"updates": [{"id": 1, "status": "confirmed"}, {"id": 2, "status": "confirmed"}]}`
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/orders/batch/status", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("status = %d, esperado 413", w.Code)
+	}
+}
+
+func TestGetOrderStopsWithRequestContext(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	database := dbtest.NewSQLite(t)
+	repo := repository.NewOrderRepository(database)
+	orders := service.NewOrderService(repo, transaction.NewManager(database, 1), mqtest.NewPublisher())
+	order := &model.Order{CustomerID: 1, Status: model.StatusPending, TotalAmount: 150}
+	if err := repo.Create(context.Background(), order); err != nil {
+		t.Fatal(err)
+	}
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	tests := []struct {
+		name    string
+		timeout time.Duration
+		ctx     context.Context
+		want    int
+	}{
+		{"dentro do prazo", time.Minute, context.Background(), http.StatusOK},
+		// A query do repositório falha com o erro do contexto: 504/499, não 500
+		{"prazo estourado", time.Nanosecond, context.Background(), http.StatusGatewayTimeout},
+		{"cliente desconectado", time.Minute, canceled, deadline.StatusClientClosedRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			r.GET("/api/v1/orders/:id", deadline.Middleware(tt.timeout), NewOrderHandler(orders, nil, 1).GetOrder)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/orders/1", nil).WithContext(tt.ctx)
+			r.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, esperado %d: %s", w.Code, tt.want, w.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"order-service/internal/order/model"
+	"order-service/internal/order/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReplayHandler expõe os replays de eventos para administração. Cada
+// réplica conhece só os replays que ela mesma iniciou.
+type ReplayHandler struct {
+	replayService service.ReplayService
+}
+
+func NewReplayHandler(replayService service.ReplayService) *ReplayHandler {
+	return &ReplayHandler{replayService: replayService}
+}
+
+func (h *ReplayHandler) StartReplay(c *gin.Context) {
+	var req model.ReplayRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Dados inválidos",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	replay, err := h.replayService.StartReplay(c.Request.Context(), req)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, service.ErrInvalidReplay) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, ErrorResponse{
+			Error:   "Erro ao iniciar replay",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.Header("Location", "/api/v1/admin/replays/"+replay.ID)
+	c.JSON(http.StatusAccepted, replay)
+}
+
+func (h *ReplayHandler) GetReplay(c *gin.Context) {
+	replay, err := h.replayService.GetReplay(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "Replay não encontrado",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, replay)
+}
+
+func (h *ReplayHandler) CancelReplay(c *gin.Context) {
+	replay, err := h.replayService.CancelReplay(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "Replay não encontrado",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, replay)
+}
@@ -0,0 +1,286 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"order-service/internal/order/service"
+	"order-service/pkg/deadline"
+	"order-service/pkg/mq"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	heartbeatInterval = 15 * time.Second
+	writeTimeout      = 10 * time.Second
+)
+
+// StreamHandler entrega eventos de pedidos em tempo real via SSE e WebSocket.
+// Os eventos vêm do broadcaster, alimentado pelo exchange do RabbitMQ, então
+// qualquer réplica recebe as mudanças feitas pelas outras.
+type StreamHandler struct {
+	orderService service.OrderService
+	events       *mq.Broadcaster
+	upgrader     websocket.Upgrader
+}
+
+// NewStreamHandler cria o handler; allowedOrigins são as origens (ex.:
+// https://app.example.com) de outros domínios que podem abrir o WebSocket.
+func NewStreamHandler(orderService service.OrderService, events *mq.Broadcaster, allowedOrigins []string) *StreamHandler {
+	return &StreamHandler{
+		orderService: orderService,
+		events:       events,
+		upgrader: websocket.Upgrader{
+			CheckOrigin: checkOrigin(allowedOrigins),
+		},
+	}
+}
+
+// checkOrigin aceita o WebSocket sem Origin (clientes fora do navegador), da
+// mesma origem do serviço ou de uma das origens permitidas. As demais
+// recebem 403, para uma página de outro domínio não abrir o stream com as
+// credenciais do usuário.
+func checkOrigin(allowed []string) func(r *http.Request) bool {
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			return true
+		}
+		u, err := url.Parse(origin)
+		if err != nil {
+			return false
+		}
+		if strings.EqualFold(u.Host, r.Host) {
+			return true
+		}
+		for _, o := range allowed {
+			if strings.EqualFold(o, origin) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// OrderEvents é o stream SSE de um pedido. Suporta retomada via Last-Event-ID;
+// quando o ID não é conhecido, envia um evento "snapshot" com o estado atual.
+func (h *StreamHandler) OrderEvents(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "ID inválido",
+			Message: "ID deve ser um número",
+		})
+		return
+	}
+
+	customerID, ok := customerScope(c)
+	if !ok {
+		return
+	}
+
+	order, err := h.orderService.GetOrderByID(c.Request.Context(), uint(id))
+	// Pedido de outro cliente responde como inexistente, para o stream não
+	// revelar quais IDs existem
+	if errors.Is(err, service.ErrOrderNotFound) || (err == nil && order.CustomerID != customerID) {
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "Pedido não encontrado",
+			Message: service.ErrOrderNotFound.Error(),
+		})
+		return
+	}
+	if err != nil {
+		if deadline.Respond(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Erro ao buscar pedido",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+
+	replay, events, cancel, found := h.events.SubscribeFrom(lastEventID, func(event mq.OrderEvent) bool {
+		return uint(event.OrderID) == order.ID
+	})
+	defer cancel()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	if !found {
+		writeSSE(c.Writer, "", "snapshot", order)
+	}
+	for _, event := range replay {
+		writeSSE(c.Writer, event.ID, event.Type, event)
+	}
+	c.Writer.Flush()
+
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-ticker.C:
+			fmt.Fprint(c.Writer, ": heartbeat\n\n")
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			writeSSE(c.Writer, event.ID, event.Type, event)
+		}
+		c.Writer.Flush()
+	}
+}
+
+// CustomerOrders é o WebSocket com os eventos de todos os pedidos de um
+// cliente. Aceita ?last_event_id= para retomar; se o ID não for conhecido, envia
+// uma mensagem "resync" para o cliente recarregar a lista.
+func (h *StreamHandler) CustomerOrders(c *gin.Context) {
+	customerIDStr := c.Param("customer_id")
+	customerID, err := strconv.ParseUint(customerIDStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "customer_id inválido",
+			Message: "customer_id deve ser um número",
+		})
+		return
+	}
+
+	if !h.authorize(c, uint(customerID)) {
+		return
+	}
+
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// Upgrade já respondeu ao cliente
+		return
+	}
+	defer conn.Close()
+
+	lastEventID := c.Query("last_event_id")
+	replay, events, cancel, found := h.events.SubscribeFrom(lastEventID, func(event mq.OrderEvent) bool {
+		id, ok := event.CustomerID()
+		return ok && id == uint(customerID)
+	})
+	defer cancel()
+
+	// Leitura apenas para processar pong/close do cliente
+	closed := make(chan struct{})
+	conn.SetReadDeadline(time.Now().Add(2 * heartbeatInterval))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(2 * heartbeatInterval))
+	})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	if lastEventID != "" && !found {
+		if err := writeWS(conn, mq.OrderEvent{Type: "resync"}); err != nil {
+			return
+		}
+	}
+	for _, event := range replay {
+		if err := writeWS(conn, event); err != nil {
+			return
+		}
+	}
+
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-closed:
+			return
+		case <-ticker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeTimeout)); err != nil {
+				return
+			}
+		case event, ok := <-events:
+			if !ok {
+				// Broadcaster fechado: o serviço está desligando
+				conn.WriteControl(websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.CloseGoingAway, "servidor em desligamento"),
+					time.Now().Add(writeTimeout))
+				return
+			}
+			if err := writeWS(conn, event); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// customerScope lê o cliente autenticado, informado pelo gateway no header
+// X-Customer-ID. Sem o header (ou com um valor inválido) o stream é recusado.
+func customerScope(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.GetHeader("X-Customer-ID"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Cliente não identificado",
+			Message: "header X-Customer-ID obrigatório",
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// authorize só libera o stream dos pedidos do cliente autenticado.
+func (h *StreamHandler) authorize(c *gin.Context, customerID uint) bool {
+	scope, ok := customerScope(c)
+	if !ok {
+		return false
+	}
+
+	if scope != customerID {
+		c.JSON(http.StatusForbidden, ErrorResponse{
+			Error:   "Acesso negado",
+			Message: "pedidos pertencem a outro cliente",
+		})
+		return false
+	}
+	return true
+}
+
+func writeSSE(w io.Writer, id, eventType string, data any) {
+	body, err := json.Marshal(data)
+	if err != nil {
+		return
+	}
+
+	if id != "" {
+		fmt.Fprintf(w, "id: %s\n", id)
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", eventType, body)
+}
+
+func writeWS(conn *websocket.Conn, event mq.OrderEvent) error {
+	conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	return conn.WriteJSON(event)
+}
@@ -0,0 +1,315 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"order-service/internal/order/model"
+	"order-service/internal/order/repository"
+	"order-service/internal/order/service"
+	"order-service/pkg/deadline"
+	"order-service/pkg/mq"
+	"order-service/pkg/mq/mqtest"
+	"order-service/pkg/transaction"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+type streamTest struct {
+	server *httptest.Server
+	events *mq.Broadcaster
+	order  *model.OrderResponse
+}
+
+// newStreamTest sobe os streams com um pedido do cliente 1 e três eventos
+// dele (e1, e2, e3) no histórico do broadcaster.
+func newStreamTest(t *testing.T) *streamTest {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	repo := repository.NewMemoryOrderRepository()
+	orders := service.NewOrderService(repo, transaction.NewMemoryManager(3, repo), mqtest.NewPublisher())
+	order, err := orders.CreateOrder(context.Background(), model.CreateOrderRequest{
+		CustomerID: 1,
+		Items:      []model.CreateOrderItemRequest{{ProductID: 10, Name: "Teclado", Price: 150, Quantity: 2}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events := mq.NewBroadcaster(10)
+	h := NewStreamHandler(orders, events, []string{"https://app.example.com"})
+	r := gin.New()
+	r.GET("/api/v1/orders/:id/events", h.OrderEvents)
+	r.GET("/api/v1/customers/:customer_id/orders/ws", h.CustomerOrders)
+
+	st := &streamTest{server: httptest.NewServer(r), events: events, order: order}
+	t.Cleanup(func() {
+		events.Close()
+		st.server.Close()
+	})
+	for _, id := range []string{"e1", "e2", "e3"} {
+		st.broadcast(id)
+	}
+	return st
+}
+
+func (st *streamTest) broadcast(id string) {
+	st.events.Broadcast(mq.OrderEvent{
+		ID:      id,
+		Type:    "status_changed",
+		OrderID: int(st.order.ID),
+		Data:    map[string]any{"customer_id": float64(st.order.CustomerID)},
+	})
+}
+
+type sseEvent struct{ id, typ string }
+
+// readSSE lê o próximo evento do stream, ignorando os heartbeats.
+func readSSE(t *testing.T, r *bufio.Reader) sseEvent {
+	t.Helper()
+	var event sseEvent
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("stream encerrado: %v", err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "" && event.typ != "":
+			return event
+		case strings.HasPrefix(line, "id: "):
+			event.id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "event: "):
+			event.typ = strings.TrimPrefix(line, "event: ")
+		}
+	}
+}
+
+func (st *streamTest) openSSE(t *testing.T, customerID, lastEventID, query string) (*http.Response, *bufio.Reader) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+
+	url := st.server.URL + "/api/v1/orders/" + strconv.FormatUint(uint64(st.order.ID), 10) + "/events" + query
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if customerID != "" {
+		req.Header.Set("X-Customer-ID", customerID)
+	}
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp, bufio.NewReader(resp.Body)
+}
+
+func TestOrderEventsResumesFromLastEventID(t *testing.T) {
+	st := newStreamTest(t)
+
+	tests := []struct {
+		name        string
+		lastEventID string
+		query       string
+		want        []sseEvent
+	}{
+		{"header Last-Event-ID", "e1", "", []sseEvent{{"e2", "status_changed"}, {"e3", "status_changed"}}},
+		{"query last_event_id", "", "?last_event_id=e2", []sseEvent{{"e3", "status_changed"}}},
+		{"ID desconhecido envia snapshot", "sumiu", "", []sseEvent{{"", "snapshot"}}},
+		{"sem ID envia snapshot", "", "", []sseEvent{{"", "snapshot"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, body := st.openSSE(t, "1", tt.lastEventID, tt.query)
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("status = %d, esperado 200", resp.StatusCode)
+			}
+			for _, want := range tt.want {
+				if got := readSSE(t, body); got != want {
+					t.Fatalf("evento = %+v, esperado %+v", got, want)
+				}
+			}
+		})
+	}
+
+	// Depois da retomada, os eventos novos chegam pelo mesmo stream
+	_, body := st.openSSE(t, "1", "e3", "")
+	st.broadcast("e4")
+	if got := readSSE(t, body); got.id != "e4" {
+		t.Errorf("evento = %+v, esperado e4", got)
+	}
+}
+
+func TestOrderEventsRequiresCustomerScope(t *testing.T) {
+	st := newStreamTest(t)
+	own := strconv.FormatUint(uint64(st.order.ID), 10)
+
+	tests := []struct {
+		name       string
+		id         string
+		customerID string
+		want       int
+	}{
+		// O header é conferido antes da busca
+		{"sem X-Customer-ID", "999", "", http.StatusUnauthorized},
+		{"X-Customer-ID inválido", own, "abc", http.StatusUnauthorized},
+		// Pedido de outro cliente e inexistente são indistinguíveis
+		{"outro cliente", own, "2", http.StatusNotFound},
+		{"pedido inexistente", "999", "2", http.StatusNotFound},
+	}
+	bodies := map[int]string{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, st.server.URL+"/api/v1/orders/"+tt.id+"/events", nil)
+			if tt.customerID != "" {
+				req.Header.Set("X-Customer-ID", tt.customerID)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+
+			if resp.StatusCode != tt.want {
+				t.Errorf("status = %d, esperado %d", resp.StatusCode, tt.want)
+			}
+			if previous, ok := bodies[tt.want]; ok && previous != string(body) {
+				t.Errorf("corpo = %s, esperado %s", body, previous)
+			}
+			bodies[tt.want] = string(body)
+		})
+	}
+}
+
+// failingOrders falha todas as buscas de pedido com err.
+type failingOrders struct {
+	service.OrderService
+	err error
+}
+
+func (s failingOrders) GetOrderByID(context.Context, uint) (*model.OrderResponse, error) {
+	return nil, s.err
+}
+
+func TestOrderEventsLookupError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"erro do banco", errors.New("connection refused"), http.StatusInternalServerError},
+		{"cliente desconectado", context.Canceled, deadline.StatusClientClosedRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events := mq.NewBroadcaster(1)
+			t.Cleanup(events.Close)
+			h := NewStreamHandler(failingOrders{err: tt.err}, events, nil)
+			r := gin.New()
+			r.GET("/api/v1/orders/:id/events", h.OrderEvents)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/orders/1/events", nil)
+			req.Header.Set("X-Customer-ID", "1")
+			r.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, esperado %d: %s", w.Code, tt.want, w.Body)
+			}
+		})
+	}
+}
+
+func (st *streamTest) dialWS(t *testing.T, customerID, origin, query string) (*websocket.Conn, *http.Response, error) {
+	t.Helper()
+	url := "ws" + strings.TrimPrefix(st.server.URL, "http") + "/api/v1/customers/1/orders/ws" + query
+	header := http.Header{}
+	if customerID != "" {
+		header.Set("X-Customer-ID", customerID)
+	}
+	if origin != "" {
+		header.Set("Origin", origin)
+	}
+	conn, resp, err := websocket.DefaultDialer.Dial(url, header)
+	if conn != nil {
+		t.Cleanup(func() { conn.Close() })
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	}
+	return conn, resp, err
+}
+
+func TestCustomerOrdersResumesFromLastEventID(t *testing.T) {
+	st := newStreamTest(t)
+
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{"ID conhecido", "?last_event_id=e1", []string{"e2", "e3"}},
+		{"ID desconhecido envia resync", "?last_event_id=sumiu", []string{"resync"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, _, err := st.dialWS(t, "1", "", tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				var event mq.OrderEvent
+				if err := conn.ReadJSON(&event); err != nil {
+					t.Fatal(err)
+				}
+				got := event.ID
+				if event.Type == "resync" {
+					got = event.Type
+				}
+				if got != want {
+					t.Fatalf("mensagem = %s, esperado %s", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCustomerOrdersChecksScopeAndOrigin(t *testing.T) {
+	st := newStreamTest(t)
+
+	tests := []struct {
+		name       string
+		customerID string
+		origin     string
+		want       int
+	}{
+		{"sem X-Customer-ID", "", "", http.StatusUnauthorized},
+		{"outro cliente", "2", "", http.StatusForbidden},
+		{"origem não permitida", "1", "https://evil.example.com", http.StatusForbidden},
+		{"origem permitida", "1", "https://app.example.com", http.StatusSwitchingProtocols},
+		{"mesma origem", "1", st.server.URL, http.StatusSwitchingProtocols},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, resp, err := st.dialWS(t, tt.customerID, tt.origin, "")
+			if resp == nil {
+				t.Fatalf("sem resposta: %v", err)
+			}
+			if resp.StatusCode != tt.want {
+				t.Errorf("status = %d, esperado %d", resp.StatusCode, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package model
+
+type BatchMode string
+
+const (
+	// BatchAtomic persiste todos os itens ou nenhum
+	BatchAtomic BatchMode = "atomic"
+	// BatchPartial persiste os itens válidos e reporta o erro de cada item
+	BatchPartial BatchMode = "partial"
+)
+
+// Os itens não usam "dive": no modo partial a validação é feita por item no
+// service, para que um item inválido não rejeite o lote inteiro.
+type BatchCreateOrderRequest struct {
+	Mode   BatchMode            `json:"mode,omitempty" binding:"omitempty,oneof=atomic partial"`
+	Orders []CreateOrderRequest `json:"orders" binding:"required,min=1"`
+}
+
+type BatchUpdateStatusRequest struct {
+	Mode    BatchMode           `json:"mode,omitempty" binding:"omitempty,oneof=atomic partial"`
+	Updates []StatusUpdateEntry `json:"updates" binding:"required,min=1"`
+}
+
+type StatusUpdateEntry struct {
+	ID     uint        `json:"id"`
+	Status OrderStatus `json:"status"`
+}
+
+type BatchItemResult struct {
+	Index   int            `json:"index"`
+	Success bool           `json:"success"`
+	Order   *OrderResponse `json:"order,omitempty"`
+	Error   string         `json:"error,omitempty"`
+}
+
+type BatchResponse struct {
+	Mode      BatchMode         `json:"mode"`
+	Total     int               `json:"total"`
+	Succeeded int               `json:"succeeded"`
+	Failed    int               `json:"failed"`
+	Results   []BatchItemResult `json:"results"`
+}
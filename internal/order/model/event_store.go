@@ -0,0 +1,57 @@
+package model
+
+import "time"
+
+// DomainEventType é o tipo de um evento do event store (EVENT_STORE_ENABLED).
+type DomainEventType string
+
+const (
+	// Estado completo do pedido ao ser criado ou, para pedidos de antes do
+	// event store, na primeira alteração depois de habilitá-lo
+	EventOrderCreated   DomainEventType = "order_created"
+	EventItemAdded      DomainEventType = "item_added"
+	EventItemUpdated    DomainEventType = "item_updated"
+	EventItemRemoved    DomainEventType = "item_removed"
+	EventStatusChanged  DomainEventType = "status_changed"
+	EventOrderCancelled DomainEventType = "order_cancelled"
+	EventOrderDeleted   DomainEventType = "order_deleted"
+)
+
+// DomainEvent é uma linha de order_events. Version é sequencial por pedido,
+// a partir de 1; Data é o JSON do payload do tipo (Order em order_created,
+// OrderItem nos de item, ItemRemovedData e StatusChangedData).
+type DomainEvent struct {
+	ID          uint64          `json:"id" gorm:"primarykey"`
+	OrderID     uint            `json:"order_id" gorm:"not null"`
+	Version     int             `json:"version" gorm:"not null"`
+	Type        DomainEventType `json:"type" gorm:"type:varchar(30);not null"`
+	Data        string          `json:"data" gorm:"type:text;not null"`
+	CreatedAt   time.Time       `json:"created_at"`
+	PublishedAt *time.Time      `json:"published_at"`
+}
+
+func (DomainEvent) TableName() string {
+	return "order_events"
+}
+
+// OrderSnapshot guarda o estado do pedido (JSON de Order) na versão
+// indicada, para a reconstrução aplicar só os eventos posteriores.
+type OrderSnapshot struct {
+	OrderID   uint      `gorm:"primarykey;autoIncrement:false"`
+	Version   int       `gorm:"not null"`
+	State     string    `gorm:"type:text;not null"`
+	CreatedAt time.Time `gorm:"not null"`
+}
+
+func (OrderSnapshot) TableName() string {
+	return "order_snapshots"
+}
+
+type ItemRemovedData struct {
+	ItemID uint `json:"item_id"`
+}
+
+type StatusChangedData struct {
+	From OrderStatus `json:"from"`
+	To   OrderStatus `json:"to"`
+}
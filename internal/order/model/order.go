@@ -18,6 +18,15 @@ const (
 	StatusFailed    OrderStatus = "failed"
 )
 
+// Valid informa se s é um dos status acima.
+func (s OrderStatus) Valid() bool {
+	switch s {
+	case StatusPending, StatusConfirmed, StatusPaid, StatusShipped, StatusDelivered, StatusCancelled, StatusFailed:
+		return true
+	}
+	return false
+}
+
 type Order struct {
 	ID          uint           `json:"id" gorm:"primarykey"`
 	CustomerID  uint           `json:"customer_id" gorm:"not null"`
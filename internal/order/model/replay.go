@@ -0,0 +1,53 @@
+package model
+
+import "time"
+
+type ReplayStatus string
+
+const (
+	ReplayRunning   ReplayStatus = "running"
+	ReplayCompleted ReplayStatus = "completed"
+	ReplayCancelled ReplayStatus = "cancelled"
+	ReplayFailed    ReplayStatus = "failed"
+)
+
+// ReplayRequest seleciona os pedidos a republicar e o destino dos eventos.
+// Os filtros se combinam e ao menos um é obrigatório.
+type ReplayRequest struct {
+	// Pedidos com alguma mudança no intervalo: criados antes de to e
+	// alterados pela última vez a partir de from
+	From     *time.Time  `json:"from,omitempty"`
+	To       *time.Time  `json:"to,omitempty"`
+	OrderIDs []uint      `json:"order_ids,omitempty"`
+	Status   OrderStatus `json:"status,omitempty"`
+	// Routing key no lugar de order.created
+	RoutingKey string `json:"routing_key,omitempty"`
+	// Única fila que processa os eventos
+	Queue string `json:"queue,omitempty"`
+	// Eventos por segundo, até o máximo configurado (MQ_REPLAY_RATE)
+	Rate int `json:"rate,omitempty" binding:"omitempty,min=1"`
+}
+
+// ReplayFilter são os critérios de ReplayRequest usados na busca dos pedidos.
+type ReplayFilter struct {
+	From     *time.Time
+	To       *time.Time
+	OrderIDs []uint
+	Status   OrderStatus
+}
+
+func (r ReplayRequest) Filter() ReplayFilter {
+	return ReplayFilter{From: r.From, To: r.To, OrderIDs: r.OrderIDs, Status: r.Status}
+}
+
+// ReplayResponse é o andamento de um replay.
+type ReplayResponse struct {
+	ID         string        `json:"id"`
+	Status     ReplayStatus  `json:"status"`
+	Request    ReplayRequest `json:"request"`
+	Published  int           `json:"published"`
+	Failed     int           `json:"failed"`
+	Error      string        `json:"error,omitempty"`
+	StartedAt  time.Time     `json:"started_at"`
+	FinishedAt *time.Time    `json:"finished_at,omitempty"`
+}
@@ -0,0 +1,28 @@
+package model
+
+import "time"
+
+// OrderSummary é uma linha de customer_order_summary, o read model das
+// listagens por cliente: o pedido sem os items, só com as contagens.
+// Mantido a partir dos eventos order.*, fica alguns instantes atrás das
+// escritas.
+type OrderSummary struct {
+	ID         uint        `json:"id" gorm:"column:order_id;primarykey;autoIncrement:false"`
+	CustomerID uint        `json:"customer_id" gorm:"not null"`
+	Status     OrderStatus `json:"status" gorm:"type:varchar(20);not null"`
+	// Items distintos e soma das quantidades
+	ItemCount     int     `json:"item_count" gorm:"not null"`
+	TotalQuantity int     `json:"total_quantity" gorm:"not null"`
+	TotalAmount   float64 `json:"total_amount" gorm:"type:decimal(10,2)"`
+	// Vêm dos eventos; o GORM não as preenche (nem as deixaria de fora do
+	// upsert, no caso de created_at)
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime:false"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime:false"`
+	// Momento do evento mais recente aplicado; mudanças de status de
+	// eventos anteriores a ele chegaram fora de ordem e são ignoradas
+	LastEventAt time.Time `json:"-" gorm:"not null"`
+}
+
+func (OrderSummary) TableName() string {
+	return "customer_order_summary"
+}
@@ -0,0 +1,514 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"order-service/internal/order/model"
+	"order-service/pkg/transaction"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/plugin/dbresolver"
+)
+
+// EventStore dá acesso aos eventos de domínio gravados por
+// EventSourcedOrderRepository.
+type EventStore interface {
+	// Events devolve o histórico do pedido, em ordem de versão.
+	Events(ctx context.Context, orderID uint) ([]model.DomainEvent, error)
+	// ClaimUnpublished devolve até limit eventos ainda não publicados, na
+	// ordem em que foram gravados, bloqueados (FOR UPDATE) até o fim da
+	// transação em andamento.
+	ClaimUnpublished(ctx context.Context, limit int) ([]model.DomainEvent, error)
+	MarkPublished(ctx context.Context, ids []uint64, at time.Time) error
+}
+
+// ErrVersionConflict indica que outra transação gravou eventos do pedido
+// depois de ele ser lido. Marca a transação como conflito de serialização,
+// para o transaction.Manager repeti-la do início.
+var ErrVersionConflict = fmt.Errorf("order version conflict: %w", transaction.ErrSerialization)
+
+// EventSourcedOrderRepository grava cada alteração de pedido como eventos
+// de domínio em order_events, só acrescentados e com versão sequencial por
+// pedido, e reconstrói o pedido a partir do último snapshot e dos eventos
+// seguintes. As tabelas orders e order_items continuam sendo atualizadas na
+// mesma transação, como projeção do estado atual: listagens, contagens e as
+// chaves estrangeiras de outras tabelas seguem usando-as.
+//
+// Update registra items e status; os demais campos não mudam depois da
+// criação. Pedidos gravados antes do event store não têm eventos: são lidos
+// da projeção, e a primeira alteração registra o estado deles em um
+// order_created.
+type EventSourcedOrderRepository struct {
+	*orderRepository
+	transactions  transaction.Manager
+	snapshotEvery int
+}
+
+var (
+	_ OrderRepository = (*EventSourcedOrderRepository)(nil)
+	_ EventStore      = (*EventSourcedOrderRepository)(nil)
+)
+
+// NewEventSourcedOrderRepository cria o repositório; um snapshot é gravado
+// a cada snapshotEvery eventos do pedido (0 desliga os snapshots).
+func NewEventSourcedOrderRepository(db *gorm.DB, snapshotEvery int) *EventSourcedOrderRepository {
+	return &EventSourcedOrderRepository{
+		orderRepository: &orderRepository{db: db},
+		// Dentro da transação do service vira savepoint; os conflitos são
+		// repetidos pela transação mais externa
+		transactions:  transaction.NewManager(db, 1),
+		snapshotEvery: snapshotEvery,
+	}
+}
+
+// aggregate é um pedido reconstruído e a versão do último evento aplicado.
+type aggregate struct {
+	order           model.Order
+	version         int
+	snapshotVersion int
+	deleted         bool
+}
+
+// change é um evento ainda não gravado.
+type change struct {
+	kind model.DomainEventType
+	data any
+}
+
+func (r *EventSourcedOrderRepository) Create(ctx context.Context, order *model.Order) error {
+	return r.transactions.Do(ctx, func(ctx context.Context) error {
+		if err := r.orderRepository.Create(ctx, order); err != nil {
+			return err
+		}
+		return r.append(ctx, order.ID, &aggregate{}, now(), change{model.EventOrderCreated, order})
+	})
+}
+
+func (r *EventSourcedOrderRepository) CreateBatch(ctx context.Context, orders []model.Order, batchSize int) error {
+	return r.transactions.Do(ctx, func(ctx context.Context) error {
+		if err := r.orderRepository.CreateBatch(ctx, orders, batchSize); err != nil {
+			return err
+		}
+
+		createdAt := now()
+		events := make([]model.DomainEvent, len(orders))
+		for i := range orders {
+			event, err := newEvent(orders[i].ID, 1, createdAt, change{model.EventOrderCreated, &orders[i]})
+			if err != nil {
+				return err
+			}
+			events[i] = event
+		}
+		return r.insert(ctx, events, batchSize)
+	})
+}
+
+func (r *EventSourcedOrderRepository) GetByID(ctx context.Context, id uint) (*model.Order, error) {
+	orders, err := r.GetByIDs(ctx, []uint{id})
+	if err != nil {
+		return nil, err
+	}
+	if len(orders) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &orders[0], nil
+}
+
+func (r *EventSourcedOrderRepository) GetByIDs(ctx context.Context, ids []uint) ([]model.Order, error) {
+	aggregates, err := r.load(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	var orders []model.Order
+	var legacy []uint
+	for _, id := range ids {
+		agg, ok := aggregates[id]
+		switch {
+		case !ok:
+			legacy = append(legacy, id)
+		case !agg.deleted:
+			orders = append(orders, agg.order)
+		}
+	}
+	if len(legacy) > 0 {
+		found, err := r.orderRepository.GetByIDs(ctx, legacy)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, found...)
+	}
+	return orders, nil
+}
+
+// GetByIDForUpdate e GetByIDsForUpdate bloqueiam as linhas da projeção, o
+// que também serializa as alterações de cada pedido no event store.
+func (r *EventSourcedOrderRepository) GetByIDForUpdate(ctx context.Context, id uint) (*model.Order, error) {
+	err := transaction.DB(ctx, r.db).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id").First(&model.Order{}, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return r.GetByID(ctx, id)
+}
+
+func (r *EventSourcedOrderRepository) GetByIDsForUpdate(ctx context.Context, ids []uint) ([]model.Order, error) {
+	var locked []uint
+	err := transaction.DB(ctx, r.db).Clauses(clause.Locking{Strength: "UPDATE"}).Model(&model.Order{}).
+		Where("id IN ?", ids).
+		Order("id").
+		Pluck("id", &locked).Error
+	if err != nil {
+		return nil, err
+	}
+
+	orders, err := r.GetByIDs(ctx, locked)
+	slices.SortFunc(orders, func(a, b model.Order) int { return cmp.Compare(a.ID, b.ID) })
+	return orders, err
+}
+
+func (r *EventSourcedOrderRepository) Update(ctx context.Context, order *model.Order) error {
+	return r.transactions.Do(ctx, func(ctx context.Context) error {
+		agg, err := r.aggregate(ctx, order.ID)
+		if err != nil {
+			return err
+		}
+		if err := r.orderRepository.Update(ctx, order); err != nil {
+			return err
+		}
+
+		// Save não remove os items que saíram do pedido
+		kept := make([]uint, len(order.Items))
+		for i, item := range order.Items {
+			kept[i] = item.ID
+		}
+		removed := transaction.DB(ctx, r.db).Where("order_id = ?", order.ID)
+		if len(kept) > 0 {
+			removed = removed.Where("id NOT IN ?", kept)
+		}
+		if err := removed.Delete(&model.OrderItem{}).Error; err != nil {
+			return err
+		}
+
+		return r.append(ctx, order.ID, agg, order.UpdatedAt.UTC(), diff(agg.order, *order)...)
+	})
+}
+
+func (r *EventSourcedOrderRepository) UpdateStatus(ctx context.Context, id uint, status model.OrderStatus) error {
+	return r.transactions.Do(ctx, func(ctx context.Context) error {
+		return r.updateStatus(ctx, id, status)
+	})
+}
+
+func (r *EventSourcedOrderRepository) UpdateStatuses(ctx context.Context, statuses map[uint]model.OrderStatus) error {
+	ids := make([]uint, 0, len(statuses))
+	for id := range statuses {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	return r.transactions.Do(ctx, func(ctx context.Context) error {
+		for _, id := range ids {
+			if err := r.updateStatus(ctx, id, statuses[id]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *EventSourcedOrderRepository) updateStatus(ctx context.Context, id uint, status model.OrderStatus) error {
+	agg, err := r.aggregate(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// Como o UPDATE da implementação GORM, que não encontra a linha
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if agg.order.Status == status {
+		return nil
+	}
+
+	updatedAt := now()
+	err = transaction.DB(ctx, r.db).Model(&model.Order{}).
+		Where("id = ?", id).
+		Updates(map[string]any{"status": status, "updated_at": updatedAt}).Error
+	if err != nil {
+		return err
+	}
+	return r.append(ctx, id, agg, updatedAt, statusChange(agg.order.Status, status))
+}
+
+func (r *EventSourcedOrderRepository) Delete(ctx context.Context, id uint) error {
+	return r.transactions.Do(ctx, func(ctx context.Context) error {
+		agg, err := r.aggregate(ctx, id)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := r.orderRepository.Delete(ctx, id); err != nil {
+			return err
+		}
+		return r.append(ctx, id, agg, now(), change{model.EventOrderDeleted, struct{}{}})
+	})
+}
+
+func (r *EventSourcedOrderRepository) Events(ctx context.Context, orderID uint) ([]model.DomainEvent, error) {
+	var events []model.DomainEvent
+	err := transaction.DB(ctx, r.db).Clauses(dbresolver.Write).
+		Where("order_id = ?", orderID).
+		Order("version").
+		Find(&events).Error
+	return events, err
+}
+
+func (r *EventSourcedOrderRepository) ClaimUnpublished(ctx context.Context, limit int) ([]model.DomainEvent, error) {
+	var events []model.DomainEvent
+	err := transaction.DB(ctx, r.db).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("published_at IS NULL").
+		Order("id").
+		Limit(limit).
+		Find(&events).Error
+	return events, err
+}
+
+func (r *EventSourcedOrderRepository) MarkPublished(ctx context.Context, ids []uint64, at time.Time) error {
+	return transaction.DB(ctx, r.db).Model(&model.DomainEvent{}).
+		Where("id IN ?", ids).
+		Update("published_at", at).Error
+}
+
+// aggregate reconstrói o pedido para alterá-lo. Um pedido sem eventos
+// (gravado antes do event store) tem o estado atual registrado primeiro.
+func (r *EventSourcedOrderRepository) aggregate(ctx context.Context, id uint) (*aggregate, error) {
+	aggregates, err := r.load(ctx, []uint{id})
+	if err != nil {
+		return nil, err
+	}
+	if agg, ok := aggregates[id]; ok {
+		if agg.deleted {
+			return nil, gorm.ErrRecordNotFound
+		}
+		return agg, nil
+	}
+
+	order, err := r.orderRepository.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	agg := &aggregate{}
+	if err := r.append(ctx, id, agg, now(), change{model.EventOrderCreated, order}); err != nil {
+		return nil, err
+	}
+	return agg, nil
+}
+
+// load reconstrói os pedidos de ids que têm eventos, a partir do snapshot
+// de cada um (se houver) e dos eventos seguintes.
+func (r *EventSourcedOrderRepository) load(ctx context.Context, ids []uint) (map[uint]*aggregate, error) {
+	var snapshots []model.OrderSnapshot
+	err := transaction.DB(ctx, r.db).Clauses(dbresolver.Write).
+		Where("order_id IN ?", ids).
+		Find(&snapshots).Error
+	if err != nil {
+		return nil, err
+	}
+
+	aggregates := make(map[uint]*aggregate, len(ids))
+	for _, snapshot := range snapshots {
+		agg := &aggregate{version: snapshot.Version, snapshotVersion: snapshot.Version}
+		if err := json.Unmarshal([]byte(snapshot.State), &agg.order); err != nil {
+			return nil, fmt.Errorf("failed to decode snapshot of order %d: %w", snapshot.OrderID, err)
+		}
+		aggregates[snapshot.OrderID] = agg
+	}
+
+	var events []model.DomainEvent
+	err = transaction.DB(ctx, r.db).Clauses(dbresolver.Write).
+		Where("order_id IN ?", ids).
+		Where("version > COALESCE((SELECT s.version FROM order_snapshots s WHERE s.order_id = order_events.order_id), 0)").
+		Order("order_id, version").
+		Find(&events).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, event := range events {
+		agg, ok := aggregates[event.OrderID]
+		if !ok {
+			agg = &aggregate{}
+			aggregates[event.OrderID] = agg
+		}
+		if err := agg.apply(event); err != nil {
+			return nil, err
+		}
+	}
+	return aggregates, nil
+}
+
+// append grava changes como as próximas versões de agg, aplica-as em agg
+// e, a cada snapshotEvery versões, grava um snapshot do resultado.
+func (r *EventSourcedOrderRepository) append(ctx context.Context, orderID uint, agg *aggregate, at time.Time, changes ...change) error {
+	if len(changes) == 0 {
+		return nil
+	}
+
+	events := make([]model.DomainEvent, len(changes))
+	for i, c := range changes {
+		event, err := newEvent(orderID, agg.version+i+1, at, c)
+		if err != nil {
+			return err
+		}
+		events[i] = event
+	}
+	if err := r.insert(ctx, events, len(events)); err != nil {
+		return err
+	}
+	for _, event := range events {
+		if err := agg.apply(event); err != nil {
+			return err
+		}
+	}
+
+	if r.snapshotEvery <= 0 || agg.deleted || agg.version-agg.snapshotVersion < r.snapshotEvery {
+		return nil
+	}
+	state, err := json.Marshal(agg.order)
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot of order %d: %w", orderID, err)
+	}
+	snapshot := model.OrderSnapshot{OrderID: orderID, Version: agg.version, State: string(state), CreatedAt: at}
+	err = transaction.DB(ctx, r.db).Clauses(clause.OnConflict{UpdateAll: true}).Create(&snapshot).Error
+	if err != nil {
+		return err
+	}
+	agg.snapshotVersion = agg.version
+	return nil
+}
+
+// insert grava os eventos; uma versão já existente vira ErrVersionConflict.
+func (r *EventSourcedOrderRepository) insert(ctx context.Context, events []model.DomainEvent, batchSize int) error {
+	db := transaction.DB(ctx, r.db)
+	err := db.CreateInBatches(&events, batchSize).Error
+	if translator, ok := db.Dialector.(gorm.ErrorTranslator); ok && err != nil {
+		if errors.Is(translator.Translate(err), gorm.ErrDuplicatedKey) {
+			return fmt.Errorf("%w: order %d", ErrVersionConflict, events[0].OrderID)
+		}
+	}
+	return err
+}
+
+func newEvent(orderID uint, version int, at time.Time, c change) (model.DomainEvent, error) {
+	data, err := json.Marshal(c.data)
+	if err != nil {
+		return model.DomainEvent{}, fmt.Errorf("failed to encode %s event of order %d: %w", c.kind, orderID, err)
+	}
+	return model.DomainEvent{
+		OrderID:   orderID,
+		Version:   version,
+		Type:      c.kind,
+		Data:      string(data),
+		CreatedAt: at,
+	}, nil
+}
+
+// apply aplica o evento seguinte ao estado.
+func (a *aggregate) apply(event model.DomainEvent) error {
+	if event.Version != a.version+1 {
+		return fmt.Errorf("order %d: event version %d after version %d", event.OrderID, event.Version, a.version)
+	}
+
+	data := []byte(event.Data)
+	var err error
+	switch event.Type {
+	case model.EventOrderCreated:
+		a.order = model.Order{}
+		err = json.Unmarshal(data, &a.order)
+	case model.EventItemAdded:
+		var item model.OrderItem
+		err = json.Unmarshal(data, &item)
+		a.order.Items = append(a.order.Items, item)
+	case model.EventItemUpdated:
+		var item model.OrderItem
+		err = json.Unmarshal(data, &item)
+		if i := a.item(item.ID); i >= 0 {
+			a.order.Items[i] = item
+		}
+	case model.EventItemRemoved:
+		var removed model.ItemRemovedData
+		err = json.Unmarshal(data, &removed)
+		if i := a.item(removed.ItemID); i >= 0 {
+			a.order.Items = slices.Delete(a.order.Items, i, i+1)
+		}
+	case model.EventStatusChanged, model.EventOrderCancelled:
+		var status model.StatusChangedData
+		err = json.Unmarshal(data, &status)
+		a.order.Status = status.To
+	case model.EventOrderDeleted:
+		a.deleted = true
+	default:
+		err = fmt.Errorf("unknown event type %q", event.Type)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to apply event %d of order %d: %w", event.Version, event.OrderID, err)
+	}
+
+	a.version = event.Version
+	if event.Type != model.EventOrderCreated {
+		calculateTotals(&a.order)
+		a.order.UpdatedAt = event.CreatedAt
+	}
+	return nil
+}
+
+func (a *aggregate) item(id uint) int {
+	return slices.IndexFunc(a.order.Items, func(item model.OrderItem) bool { return item.ID == id })
+}
+
+// diff descreve em eventos o que Update muda de before para after.
+func diff(before, after model.Order) []change {
+	var changes []change
+	previous := make(map[uint]model.OrderItem, len(before.Items))
+	for _, item := range before.Items {
+		previous[item.ID] = item
+	}
+
+	for _, item := range after.Items {
+		old, ok := previous[item.ID]
+		switch {
+		case !ok:
+			changes = append(changes, change{model.EventItemAdded, item})
+		case old.ProductID != item.ProductID || old.Name != item.Name || old.Price != item.Price || old.Quantity != item.Quantity:
+			changes = append(changes, change{model.EventItemUpdated, item})
+		}
+		delete(previous, item.ID)
+	}
+	for _, item := range before.Items {
+		if _, ok := previous[item.ID]; ok {
+			changes = append(changes, change{model.EventItemRemoved, model.ItemRemovedData{ItemID: item.ID}})
+		}
+	}
+
+	if before.Status != after.Status {
+		changes = append(changes, statusChange(before.Status, after.Status))
+	}
+	return changes
+}
+
+func statusChange(from, to model.OrderStatus) change {
+	kind := model.EventStatusChanged
+	if to == model.StatusCancelled {
+		kind = model.EventOrderCancelled
+	}
+	return change{kind, model.StatusChangedData{From: from, To: to}}
+}
@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"order-service/internal/order/model"
+	"order-service/pkg/db/dbtest"
+	"order-service/pkg/transaction"
+)
+
+func eventTypes(t *testing.T, store EventStore, orderID uint) []model.DomainEventType {
+	t.Helper()
+	events, err := store.Events(context.Background(), orderID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	types := make([]model.DomainEventType, len(events))
+	for i, event := range events {
+		if event.Version != i+1 {
+			t.Fatalf("evento %d com versão %d", i, event.Version)
+		}
+		types[i] = event.Type
+	}
+	return types
+}
+
+func assertEventTypes(t *testing.T, got []model.DomainEventType, want ...model.DomainEventType) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("eventos = %v, esperado %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("eventos = %v, esperado %v", got, want)
+		}
+	}
+}
+
+func TestEventSourcedHistoryAndSnapshots(t *testing.T) {
+	ctx := context.Background()
+	database := dbtest.NewSQLite(t)
+	repo := NewEventSourcedOrderRepository(database, 3)
+
+	order := newTestOrder(1, 10, 20)
+	if err := repo.Create(ctx, order); err != nil {
+		t.Fatal(err)
+	}
+	order.Items = append(order.Items[1:], model.OrderItem{ProductID: 300, Name: "Extra", Price: 5, Quantity: 1})
+	order.Items[0].Quantity = 3
+	if err := repo.Update(ctx, order); err != nil {
+		t.Fatal(err)
+	}
+	for _, status := range []model.OrderStatus{model.StatusConfirmed, model.StatusConfirmed, model.StatusCancelled} {
+		if err := repo.UpdateStatus(ctx, order.ID, status); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Status repetido não gera evento
+	assertEventTypes(t, eventTypes(t, repo, order.ID),
+		model.EventOrderCreated, model.EventItemUpdated, model.EventItemAdded, model.EventItemRemoved,
+		model.EventStatusChanged, model.EventOrderCancelled)
+
+	// Snapshot ao completar 3 eventos: depois do Update (versão 4); os de
+	// status ficam para a reconstrução
+	var snapshot model.OrderSnapshot
+	if err := database.First(&snapshot, order.ID).Error; err != nil || snapshot.Version != 4 {
+		t.Fatalf("snapshot = versão %d (%v), esperado 4", snapshot.Version, err)
+	}
+
+	// A reconstrução (snapshot e eventos seguintes) e a projeção concordam
+	rebuilt, err := repo.GetByID(ctx, order.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	projected, err := NewOrderRepository(database).GetByID(ctx, order.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rebuilt.Status != model.StatusCancelled || rebuilt.TotalAmount != 65 || len(rebuilt.Items) != 2 {
+		t.Errorf("reconstruído = %s, total %.2f, %d items; esperado cancelled, 65.00, 2 items",
+			rebuilt.Status, rebuilt.TotalAmount, len(rebuilt.Items))
+	}
+	if projected.Status != rebuilt.Status || projected.TotalAmount != rebuilt.TotalAmount || len(projected.Items) != len(rebuilt.Items) {
+		t.Errorf("projeção = %s, total %.2f, %d items; diferente da reconstrução",
+			projected.Status, projected.TotalAmount, len(projected.Items))
+	}
+
+	// Sem snapshot, os mesmos eventos levam ao mesmo estado
+	if err := database.Delete(&model.OrderSnapshot{}, order.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	replayed, err := repo.GetByID(ctx, order.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replayed.Status != rebuilt.Status || replayed.TotalAmount != rebuilt.TotalAmount || !replayed.UpdatedAt.Equal(rebuilt.UpdatedAt) {
+		t.Errorf("sem snapshot = %+v, com snapshot = %+v", replayed, rebuilt)
+	}
+}
+
+func TestEventSourcedAppendOnly(t *testing.T) {
+	ctx := context.Background()
+	database := dbtest.NewSQLite(t)
+	repo := NewEventSourcedOrderRepository(database, 0)
+
+	order := newTestOrder(1, 10)
+	if err := repo.Create(ctx, order); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := database.Where("order_id = ?", order.ID).Delete(&model.DomainEvent{}).Error; err == nil {
+		t.Error("DELETE em order_events deveria falhar")
+	}
+	if err := database.Model(&model.DomainEvent{}).Where("order_id = ?", order.ID).Update("data", "{}").Error; err == nil {
+		t.Error("UPDATE de data em order_events deveria falhar")
+	}
+
+	// Versão já gravada, como por uma transação concorrente
+	conflict := model.DomainEvent{OrderID: order.ID, Version: 1, Type: model.EventOrderDeleted, Data: "{}", CreatedAt: time.Now()}
+	err := repo.insert(ctx, []model.DomainEvent{conflict}, 1)
+	if !errors.Is(err, ErrVersionConflict) || !transaction.IsSerializationFailure(err) {
+		t.Fatalf("err = %v, esperado ErrVersionConflict repetível", err)
+	}
+}
+
+func TestEventSourcedLegacyOrder(t *testing.T) {
+	ctx := context.Background()
+	database := dbtest.NewSQLite(t)
+
+	// Pedido gravado antes do event store
+	order := newTestOrder(1, 10)
+	if err := NewOrderRepository(database).Create(ctx, order); err != nil {
+		t.Fatal(err)
+	}
+
+	repo := NewEventSourcedOrderRepository(database, 0)
+	if got, err := repo.GetByID(ctx, order.ID); err != nil || got.TotalAmount != 20 {
+		t.Fatalf("GetByID = %+v, %v; esperado o pedido da projeção", got, err)
+	}
+	if err := repo.UpdateStatus(ctx, order.ID, model.StatusConfirmed); err != nil {
+		t.Fatal(err)
+	}
+	assertEventTypes(t, eventTypes(t, repo, order.ID), model.EventOrderCreated, model.EventStatusChanged)
+
+	if err := repo.Delete(ctx, order.ID); err != nil {
+		t.Fatal(err)
+	}
+	assertEventTypes(t, eventTypes(t, repo, order.ID),
+		model.EventOrderCreated, model.EventStatusChanged, model.EventOrderDeleted)
+}
@@ -0,0 +1,303 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"order-service/internal/order/model"
+
+	"gorm.io/gorm"
+)
+
+// MemoryOrderRepository é um OrderRepository em memória para testes, com a
+// mesma semântica da implementação GORM: IDs sequenciais, items carregados
+// junto, ordenação, soft delete e valores monetários arredondados. Os pedidos
+// devolvidos são cópias.
+//
+// Implementa transaction.Snapshotter, para ser usado com
+// transaction.NewMemoryManager.
+type MemoryOrderRepository struct {
+	mu        sync.Mutex
+	orders    map[uint]model.Order
+	nextOrder uint
+	nextItem  uint
+}
+
+var _ OrderRepository = (*MemoryOrderRepository)(nil)
+
+func NewMemoryOrderRepository() *MemoryOrderRepository {
+	return &MemoryOrderRepository{
+		orders:    make(map[uint]model.Order),
+		nextOrder: 1,
+		nextItem:  1,
+	}
+}
+
+// Snapshot guarda o estado atual e devolve a função que o restaura. Os IDs
+// já entregues não voltam, como nas sequences do Postgres.
+func (r *MemoryOrderRepository) Snapshot() func() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	saved := make(map[uint]model.Order, len(r.orders))
+	for id, order := range r.orders {
+		saved[id] = cloneOrder(order)
+	}
+	return func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.orders = saved
+	}
+}
+
+func (r *MemoryOrderRepository) Create(_ context.Context, order *model.Order) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.insert(order, now())
+	return nil
+}
+
+func (r *MemoryOrderRepository) CreateBatch(_ context.Context, orders []model.Order, _ int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	createdAt := now()
+	for i := range orders {
+		r.insert(&orders[i], createdAt)
+	}
+	return nil
+}
+
+// insert atribui IDs (se ausentes), timestamps e totais em order e guarda
+// uma cópia.
+func (r *MemoryOrderRepository) insert(order *model.Order, createdAt time.Time) {
+	if order.ID == 0 {
+		order.ID = r.nextOrder
+		r.nextOrder++
+	}
+	if order.Status == "" {
+		order.Status = model.StatusPending
+	}
+	order.CreatedAt, order.UpdatedAt = createdAt, createdAt
+
+	for i := range order.Items {
+		item := &order.Items[i]
+		item.ID = r.nextItem
+		r.nextItem++
+		item.OrderID = order.ID
+		item.CreatedAt, item.UpdatedAt = createdAt, createdAt
+	}
+	calculateTotals(order)
+
+	r.orders[order.ID] = cloneOrder(*order)
+}
+
+func (r *MemoryOrderRepository) GetByID(_ context.Context, id uint) (*model.Order, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	order, ok := r.find(id)
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &order, nil
+}
+
+func (r *MemoryOrderRepository) GetByIDs(_ context.Context, ids []uint) ([]model.Order, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var orders []model.Order
+	for _, id := range sortedUnique(ids) {
+		if order, ok := r.find(id); ok {
+			orders = append(orders, order)
+		}
+	}
+	return orders, nil
+}
+
+// GetByIDForUpdate não bloqueia nada: transaction.NewMemoryManager já
+// executa uma transação por vez.
+func (r *MemoryOrderRepository) GetByIDForUpdate(ctx context.Context, id uint) (*model.Order, error) {
+	return r.GetByID(ctx, id)
+}
+
+func (r *MemoryOrderRepository) GetByIDsForUpdate(ctx context.Context, ids []uint) ([]model.Order, error) {
+	return r.GetByIDs(ctx, ids)
+}
+
+func (r *MemoryOrderRepository) GetByCustomerID(_ context.Context, customerID uint, limit, offset int) ([]model.Order, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var orders []model.Order
+	for _, order := range r.orders {
+		if order.CustomerID == customerID && !order.DeletedAt.Valid {
+			orders = append(orders, cloneOrder(order))
+		}
+	}
+	// created_at DESC; o ID desempata pedidos criados no mesmo instante
+	slices.SortFunc(orders, func(a, b model.Order) int {
+		if c := b.CreatedAt.Compare(a.CreatedAt); c != 0 {
+			return c
+		}
+		return int(b.ID) - int(a.ID)
+	})
+
+	// Mesma leitura do GORM: offset <= 0 é ignorado e limit negativo não limita
+	if offset > 0 {
+		orders = orders[min(offset, len(orders)):]
+	}
+	if limit >= 0 {
+		orders = orders[:min(limit, len(orders))]
+	}
+	return orders, nil
+}
+
+// Update segue o Save do GORM: grava os campos do pedido e insere os items
+// novos (sem ID); items já existentes não são alterados nem removidos.
+func (r *MemoryOrderRepository) Update(_ context.Context, order *model.Order) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	calculateTotals(order)
+
+	// Como no Save, um pedido inexistente é inserido
+	current, ok := r.orders[order.ID]
+	if !ok {
+		r.insert(order, now())
+		return nil
+	}
+
+	updatedAt := now()
+	order.UpdatedAt = updatedAt
+	items := current.Items
+	for i := range order.Items {
+		item := &order.Items[i]
+		if item.ID != 0 {
+			continue
+		}
+		item.ID = r.nextItem
+		r.nextItem++
+		item.OrderID = order.ID
+		item.CreatedAt, item.UpdatedAt = updatedAt, updatedAt
+		items = append(items, *item)
+	}
+
+	updated := *order
+	updated.Items = items
+	r.orders[order.ID] = cloneOrder(updated)
+	return nil
+}
+
+func (r *MemoryOrderRepository) UpdateStatus(_ context.Context, id uint, status model.OrderStatus) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.setStatus(id, status, now())
+	return nil
+}
+
+func (r *MemoryOrderRepository) UpdateStatuses(_ context.Context, statuses map[uint]model.OrderStatus) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	updatedAt := now()
+	for id, status := range statuses {
+		r.setStatus(id, status, updatedAt)
+	}
+	return nil
+}
+
+func (r *MemoryOrderRepository) setStatus(id uint, status model.OrderStatus, updatedAt time.Time) {
+	order, ok := r.orders[id]
+	if !ok || order.DeletedAt.Valid {
+		return
+	}
+	order.Status = status
+	order.UpdatedAt = updatedAt
+	r.orders[id] = order
+}
+
+// Delete faz soft delete apenas do pedido, como o GORM: os items ficam.
+func (r *MemoryOrderRepository) Delete(_ context.Context, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	order, ok := r.orders[id]
+	if !ok || order.DeletedAt.Valid {
+		return nil
+	}
+	order.DeletedAt = gorm.DeletedAt{Time: now(), Valid: true}
+	r.orders[id] = order
+	return nil
+}
+
+func (r *MemoryOrderRepository) Count(_ context.Context) (int64, error) {
+	return r.count(func(model.Order) bool { return true }), nil
+}
+
+func (r *MemoryOrderRepository) CountByCustomer(_ context.Context, customerID uint) (int64, error) {
+	return r.count(func(order model.Order) bool { return order.CustomerID == customerID }), nil
+}
+
+func (r *MemoryOrderRepository) ListForReplay(_ context.Context, filter model.ReplayFilter, afterID uint, limit int) ([]model.Order, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var orders []model.Order
+	for _, order := range r.orders {
+		switch {
+		case order.ID <= afterID || order.DeletedAt.Valid:
+		case filter.From != nil && order.UpdatedAt.Before(*filter.From):
+		case filter.To != nil && !order.CreatedAt.Before(*filter.To):
+		case len(filter.OrderIDs) > 0 && !slices.Contains(filter.OrderIDs, order.ID):
+		case filter.Status != "" && order.Status != filter.Status:
+		default:
+			orders = append(orders, cloneOrder(order))
+		}
+	}
+	slices.SortFunc(orders, func(a, b model.Order) int { return int(a.ID) - int(b.ID) })
+	return orders[:min(limit, len(orders))], nil
+}
+
+func (r *MemoryOrderRepository) count(match func(model.Order) bool) int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var count int64
+	for _, order := range r.orders {
+		if !order.DeletedAt.Valid && match(order) {
+			count++
+		}
+	}
+	return count
+}
+
+func (r *MemoryOrderRepository) find(id uint) (model.Order, bool) {
+	order, ok := r.orders[id]
+	if !ok || order.DeletedAt.Valid {
+		return model.Order{}, false
+	}
+	return cloneOrder(order), true
+}
+
+func cloneOrder(order model.Order) model.Order {
+	order.Items = slices.Clone(order.Items)
+	return order
+}
+
+func sortedUnique(ids []uint) []uint {
+	ids = slices.Clone(ids)
+	slices.Sort(ids)
+	return slices.Compact(ids)
+}
+
+// now imita o NowFunc do GORM (UTC) com a precisão de microssegundos do
+// TIMESTAMPTZ.
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
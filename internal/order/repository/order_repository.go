@@ -1,20 +1,41 @@
 package repository
 
 import (
+	"context"
+	"math"
+	"strconv"
+
 	"order-service/internal/order/model"
+	"order-service/pkg/transaction"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/plugin/dbresolver"
 )
 
 type OrderRepository interface {
-	Create(order *model.Order) error
-	GetByID(id uint) (*model.Order, error)
-	GetByCustomerID(customerID uint, limit, offset int) ([]model.Order, error)
-	Update(order *model.Order) error
-	UpdateStatus(id uint, status model.OrderStatus) error
-	Delete(id uint) error
-	Count() (int64, error)
-	CountByCustomer(customerID uint) (int64, error)
+	Create(ctx context.Context, order *model.Order) error
+	// CreateBatch insere os pedidos (e itens) em lotes de batchSize dentro de
+	// uma única transação.
+	CreateBatch(ctx context.Context, orders []model.Order, batchSize int) error
+	GetByID(ctx context.Context, id uint) (*model.Order, error)
+	GetByIDs(ctx context.Context, ids []uint) ([]model.Order, error)
+	// GetByIDForUpdate e GetByIDsForUpdate bloqueiam as linhas (FOR UPDATE)
+	// até o fim da transação em andamento; fora de uma, equivalem às versões
+	// sem bloqueio.
+	GetByIDForUpdate(ctx context.Context, id uint) (*model.Order, error)
+	GetByIDsForUpdate(ctx context.Context, ids []uint) ([]model.Order, error)
+	GetByCustomerID(ctx context.Context, customerID uint, limit, offset int) ([]model.Order, error)
+	Update(ctx context.Context, order *model.Order) error
+	UpdateStatus(ctx context.Context, id uint, status model.OrderStatus) error
+	// UpdateStatuses aplica vários status em uma transação, com um UPDATE por status.
+	UpdateStatuses(ctx context.Context, statuses map[uint]model.OrderStatus) error
+	Delete(ctx context.Context, id uint) error
+	Count(ctx context.Context) (int64, error)
+	CountByCustomer(ctx context.Context, customerID uint) (int64, error)
+	// ListForReplay devolve até limit pedidos do filtro com ID maior que
+	// afterID, em ordem de ID, para percorrer a seleção em páginas.
+	ListForReplay(ctx context.Context, filter model.ReplayFilter, afterID uint, limit int) ([]model.Order, error)
 }
 
 type orderRepository struct {
@@ -25,30 +46,64 @@ func NewOrderRepository(db *gorm.DB) OrderRepository {
 	return &orderRepository{db: db}
 }
 
-func (r *orderRepository) Create(order *model.Order) error {
-	// Calcular subtotais dos items
-	for i := range order.Items {
-		order.Items[i].CalculateSubtotal()
+func (r *orderRepository) Create(ctx context.Context, order *model.Order) error {
+	calculateTotals(order)
+	return transaction.DB(ctx, r.db).Create(order).Error
+}
+
+func (r *orderRepository) CreateBatch(ctx context.Context, orders []model.Order, batchSize int) error {
+	for i := range orders {
+		calculateTotals(&orders[i])
 	}
 
-	// Calcular total do pedido
-	order.CalculateTotal()
+	return transaction.DB(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		return tx.CreateInBatches(&orders, batchSize).Error
+	})
+}
+
+func (r *orderRepository) GetByID(ctx context.Context, id uint) (*model.Order, error) {
+	var order model.Order
+	// Primário: o pedido costuma ser lido para ser alterado em seguida, e
+	// uma réplica atrasada devolveria o status anterior
+	err := transaction.DB(ctx, r.db).Clauses(dbresolver.Write).Preload("Items").First(&order, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+func (r *orderRepository) GetByIDs(ctx context.Context, ids []uint) ([]model.Order, error) {
+	var orders []model.Order
+	err := transaction.DB(ctx, r.db).Clauses(dbresolver.Write).Preload("Items").
+		Where("id IN ?", ids).
+		Find(&orders).Error
 
-	return r.db.Create(order).Error
+	return orders, err
 }
 
-func (r *orderRepository) GetByID(id uint) (*model.Order, error) {
+func (r *orderRepository) GetByIDForUpdate(ctx context.Context, id uint) (*model.Order, error) {
 	var order model.Order
-	err := r.db.Preload("Items").First(&order, id).Error
+	err := transaction.DB(ctx, r.db).Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Items").First(&order, id).Error
 	if err != nil {
 		return nil, err
 	}
 	return &order, nil
 }
 
-func (r *orderRepository) GetByCustomerID(customerID uint, limit, offset int) ([]model.Order, error) {
+func (r *orderRepository) GetByIDsForUpdate(ctx context.Context, ids []uint) ([]model.Order, error) {
+	var orders []model.Order
+	// Sempre na mesma ordem, para transações concorrentes não se travarem
+	err := transaction.DB(ctx, r.db).Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Items").
+		Where("id IN ?", ids).
+		Order("id").
+		Find(&orders).Error
+
+	return orders, err
+}
+
+func (r *orderRepository) GetByCustomerID(ctx context.Context, customerID uint, limit, offset int) ([]model.Order, error) {
 	var orders []model.Order
-	err := r.db.Preload("Items").
+	err := transaction.DB(ctx, r.db).Preload("Items").
 		Where("customer_id = ?", customerID).
 		Limit(limit).
 		Offset(offset).
@@ -58,36 +113,91 @@ func (r *orderRepository) GetByCustomerID(customerID uint, limit, offset int) ([
 	return orders, err
 }
 
-func (r *orderRepository) Update(order *model.Order) error {
-	// Recalcular totais antes de salvar
-	for i := range order.Items {
-		order.Items[i].CalculateSubtotal()
-	}
-	order.CalculateTotal()
-
-	return r.db.Save(order).Error
+func (r *orderRepository) Update(ctx context.Context, order *model.Order) error {
+	calculateTotals(order)
+	return transaction.DB(ctx, r.db).Save(order).Error
 }
 
-func (r *orderRepository) UpdateStatus(id uint, status model.OrderStatus) error {
-	return r.db.Model(&model.Order{}).
+func (r *orderRepository) UpdateStatus(ctx context.Context, id uint, status model.OrderStatus) error {
+	return transaction.DB(ctx, r.db).Model(&model.Order{}).
 		Where("id = ?", id).
 		Update("status", status).Error
 }
 
-func (r *orderRepository) Delete(id uint) error {
-	return r.db.Delete(&model.Order{}, id).Error
+func (r *orderRepository) UpdateStatuses(ctx context.Context, statuses map[uint]model.OrderStatus) error {
+	byStatus := make(map[model.OrderStatus][]uint)
+	for id, status := range statuses {
+		byStatus[status] = append(byStatus[status], id)
+	}
+
+	return transaction.DB(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		for status, ids := range byStatus {
+			err := tx.Model(&model.Order{}).
+				Where("id IN ?", ids).
+				Update("status", status).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *orderRepository) Delete(ctx context.Context, id uint) error {
+	return transaction.DB(ctx, r.db).Delete(&model.Order{}, id).Error
 }
 
-func (r *orderRepository) Count() (int64, error) {
+func (r *orderRepository) Count(ctx context.Context) (int64, error) {
 	var count int64
-	err := r.db.Model(&model.Order{}).Count(&count).Error
+	err := transaction.DB(ctx, r.db).Model(&model.Order{}).Count(&count).Error
 	return count, err
 }
 
-func (r *orderRepository) CountByCustomer(customerID uint) (int64, error) {
+func (r *orderRepository) CountByCustomer(ctx context.Context, customerID uint) (int64, error) {
 	var count int64
-	err := r.db.Model(&model.Order{}).
+	err := transaction.DB(ctx, r.db).Model(&model.Order{}).
 		Where("customer_id = ?", customerID).
 		Count(&count).Error
 	return count, err
 }
+
+func (r *orderRepository) ListForReplay(ctx context.Context, filter model.ReplayFilter, afterID uint, limit int) ([]model.Order, error) {
+	query := transaction.DB(ctx, r.db).Preload("Items").Where("id > ?", afterID)
+	if filter.From != nil {
+		query = query.Where("updated_at >= ?", filter.From.UTC())
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", filter.To.UTC())
+	}
+	if len(filter.OrderIDs) > 0 {
+		query = query.Where("id IN ?", filter.OrderIDs)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	var orders []model.Order
+	err := query.Order("id").Limit(limit).Find(&orders).Error
+	return orders, err
+}
+
+// calculateTotals calcula subtotais e total e arredonda os valores como o
+// decimal(10,2) do Postgres, para o SQLite (que não impõe a precisão) gravar
+// o mesmo.
+func calculateTotals(order *model.Order) {
+	for i := range order.Items {
+		item := &order.Items[i]
+		item.CalculateSubtotal()
+		item.Price = roundCents(item.Price)
+		item.Subtotal = roundCents(item.Subtotal)
+	}
+	order.CalculateTotal()
+	order.TotalAmount = roundCents(order.TotalAmount)
+}
+
+// roundCents arredonda como o Postgres ao gravar em decimal(10,2): a partir
+// da representação decimal curta (10.005 vira 10.01, não 10.00).
+func roundCents(value float64) float64 {
+	cents, _ := strconv.ParseFloat(strconv.FormatFloat(value*100, 'g', 15, 64), 64)
+	return math.Round(cents) / 100
+}
@@ -0,0 +1,349 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	"order-service/internal/order/model"
+	"order-service/pkg/db"
+	"order-service/pkg/db/dbtest"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestMemoryOrderRepository(t *testing.T) {
+	testOrderRepository(t, func(t *testing.T) OrderRepository {
+		return NewMemoryOrderRepository()
+	})
+}
+
+// TestSQLiteOrderRepository roda o contrato contra a implementação GORM com
+// um SQLite em memória novo a cada caso.
+func TestSQLiteOrderRepository(t *testing.T) {
+	testOrderRepository(t, func(t *testing.T) OrderRepository {
+		return NewOrderRepository(dbtest.NewSQLite(t))
+	})
+}
+
+func TestSQLiteEventSourcedOrderRepository(t *testing.T) {
+	testOrderRepository(t, func(t *testing.T) OrderRepository {
+		return NewEventSourcedOrderRepository(dbtest.NewSQLite(t), 2)
+	})
+}
+
+// TestGormOrderRepository roda o mesmo contrato contra o Postgres de
+// TEST_DATABASE_DSN (ex.: "host=localhost user=orders_user password=orders_pass
+// dbname=orders_test sslmode=disable"). As tabelas são esvaziadas a cada caso.
+func TestGormOrderRepository(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_DSN não definido")
+	}
+
+	database, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := database.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	if err := db.Migrate(context.Background(), sqlDB, db.DriverPostgres, db.MigrationsAuto); err != nil {
+		t.Fatal(err)
+	}
+
+	testOrderRepository(t, func(t *testing.T) OrderRepository {
+		if err := database.Exec("TRUNCATE orders, order_items RESTART IDENTITY CASCADE").Error; err != nil {
+			t.Fatal(err)
+		}
+		return NewOrderRepository(database)
+	})
+}
+
+func newTestOrder(customerID uint, prices ...float64) *model.Order {
+	order := &model.Order{CustomerID: customerID}
+	for i, price := range prices {
+		order.Items = append(order.Items, model.OrderItem{
+			ProductID: uint(100 + i),
+			Name:      "Produto",
+			Price:     price,
+			Quantity:  2,
+		})
+	}
+	return order
+}
+
+// testOrderRepository é o contrato de OrderRepository; newRepo devolve um
+// repositório vazio.
+func testOrderRepository(t *testing.T, newRepo func(t *testing.T) OrderRepository) {
+	ctx := context.Background()
+
+	t.Run("Create", func(t *testing.T) {
+		repo := newRepo(t)
+		order := newTestOrder(1, 10, 2.5)
+		if err := repo.Create(ctx, order); err != nil {
+			t.Fatal(err)
+		}
+		if order.ID == 0 || order.Items[0].ID == 0 || order.Items[1].ID == 0 {
+			t.Fatalf("IDs não atribuídos: pedido %d, items %d e %d", order.ID, order.Items[0].ID, order.Items[1].ID)
+		}
+
+		got, err := repo.GetByID(ctx, order.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Status != model.StatusPending || got.TotalAmount != 25 || got.CreatedAt.IsZero() {
+			t.Errorf("pedido = %s, total %.2f, criado em %s; esperado pending, 25.00", got.Status, got.TotalAmount, got.CreatedAt)
+		}
+		hasSubtotal := slices.ContainsFunc(got.Items, func(item model.OrderItem) bool { return item.Subtotal == 5 })
+		if len(got.Items) != 2 || got.Items[0].OrderID != order.ID || !hasSubtotal {
+			t.Errorf("items = %+v", got.Items)
+		}
+	})
+
+	t.Run("GetByIDNotFound", func(t *testing.T) {
+		repo := newRepo(t)
+		if _, err := repo.GetByID(ctx, 42); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Fatalf("err = %v, esperado gorm.ErrRecordNotFound", err)
+		}
+	})
+
+	t.Run("DecimalRounding", func(t *testing.T) {
+		repo := newRepo(t)
+		order := newTestOrder(1, 19.999)
+		if err := repo.Create(ctx, order); err != nil {
+			t.Fatal(err)
+		}
+		got, err := repo.GetByID(ctx, order.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Items[0].Price != 20 || got.TotalAmount != 40 {
+			t.Errorf("preço %v, total %v; esperado 20 e 40", got.Items[0].Price, got.TotalAmount)
+		}
+	})
+
+	t.Run("CreateBatchAndGetByIDs", func(t *testing.T) {
+		repo := newRepo(t)
+		orders := []model.Order{*newTestOrder(1, 1), *newTestOrder(2, 2), *newTestOrder(3, 3)}
+		if err := repo.CreateBatch(ctx, orders, 2); err != nil {
+			t.Fatal(err)
+		}
+
+		ids := []uint{orders[2].ID, orders[0].ID, 999}
+		got, err := repo.GetByIDs(ctx, ids)
+		if err != nil {
+			t.Fatal(err)
+		}
+		gotIDs := make([]uint, len(got))
+		for i, order := range got {
+			gotIDs[i] = order.ID
+			if len(order.Items) != 1 {
+				t.Errorf("pedido %d com %d items, esperado 1", order.ID, len(order.Items))
+			}
+		}
+		slices.Sort(gotIDs)
+		if want := []uint{orders[0].ID, orders[2].ID}; !slices.Equal(gotIDs, want) {
+			t.Errorf("ids = %v, esperado %v", gotIDs, want)
+		}
+
+		locked, err := repo.GetByIDsForUpdate(ctx, ids)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(locked) != 2 || locked[0].ID > locked[1].ID {
+			t.Errorf("GetByIDsForUpdate deveria devolver 2 pedidos ordenados por id: %v", locked)
+		}
+	})
+
+	t.Run("GetByCustomerID", func(t *testing.T) {
+		repo := newRepo(t)
+		var ids []uint
+		for range 3 {
+			order := newTestOrder(7, 1)
+			if err := repo.Create(ctx, order); err != nil {
+				t.Fatal(err)
+			}
+			ids = append(ids, order.ID)
+		}
+		if err := repo.Create(ctx, newTestOrder(8, 1)); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := repo.GetByCustomerID(ctx, 7, 2, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 2 || got[0].ID != ids[2] || got[1].ID != ids[1] || len(got[0].Items) != 1 {
+			t.Fatalf("primeira página = %v, esperado pedidos %d e %d (mais recente primeiro)", got, ids[2], ids[1])
+		}
+
+		got, err = repo.GetByCustomerID(ctx, 7, 2, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 1 || got[0].ID != ids[0] {
+			t.Fatalf("segunda página = %v, esperado pedido %d", got, ids[0])
+		}
+	})
+
+	t.Run("ListForReplay", func(t *testing.T) {
+		repo := newRepo(t)
+		var ids []uint
+		for range 4 {
+			order := newTestOrder(1, 1)
+			if err := repo.Create(ctx, order); err != nil {
+				t.Fatal(err)
+			}
+			ids = append(ids, order.ID)
+		}
+		if err := repo.Delete(ctx, ids[3]); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(5 * time.Millisecond)
+		// Na precisão dos timestamps gravados
+		since := time.Now().Truncate(time.Millisecond)
+		if err := repo.UpdateStatus(ctx, ids[1], model.StatusConfirmed); err != nil {
+			t.Fatal(err)
+		}
+
+		list := func(filter model.ReplayFilter, afterID uint, limit int) []uint {
+			t.Helper()
+			orders, err := repo.ListForReplay(ctx, filter, afterID, limit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []uint
+			for _, order := range orders {
+				if len(order.Items) != 1 {
+					t.Errorf("pedido %d sem items", order.ID)
+				}
+				got = append(got, order.ID)
+			}
+			return got
+		}
+
+		if got := list(model.ReplayFilter{}, 0, 2); !slices.Equal(got, ids[:2]) {
+			t.Errorf("primeira página = %v, esperado %v", got, ids[:2])
+		}
+		if got := list(model.ReplayFilter{}, ids[1], 2); !slices.Equal(got, ids[2:3]) {
+			t.Errorf("segunda página = %v, esperado %v (sem o removido)", got, ids[2:3])
+		}
+		if got := list(model.ReplayFilter{From: &since}, 0, 10); !slices.Equal(got, ids[1:2]) {
+			t.Errorf("alterados desde %s = %v, esperado %v", since, got, ids[1:2])
+		}
+		if got := list(model.ReplayFilter{To: &since}, 0, 10); !slices.Equal(got, ids[:3]) {
+			t.Errorf("criados antes de %s = %v, esperado %v", since, got, ids[:3])
+		}
+		filter := model.ReplayFilter{OrderIDs: []uint{ids[0], ids[1]}, Status: model.StatusPending}
+		if got := list(filter, 0, 10); !slices.Equal(got, ids[:1]) {
+			t.Errorf("pedidos %v pending = %v, esperado %v", filter.OrderIDs, got, ids[:1])
+		}
+	})
+
+	t.Run("UpdateStatus", func(t *testing.T) {
+		repo := newRepo(t)
+		first, second := newTestOrder(1, 1), newTestOrder(1, 1)
+		for _, order := range []*model.Order{first, second} {
+			if err := repo.Create(ctx, order); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if err := repo.UpdateStatus(ctx, first.ID, model.StatusConfirmed); err != nil {
+			t.Fatal(err)
+		}
+		err := repo.UpdateStatuses(ctx, map[uint]model.OrderStatus{
+			first.ID:  model.StatusPaid,
+			second.ID: model.StatusCancelled,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for id, want := range map[uint]model.OrderStatus{first.ID: model.StatusPaid, second.ID: model.StatusCancelled} {
+			got, err := repo.GetByIDForUpdate(ctx, id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Status != want {
+				t.Errorf("pedido %d = %s, esperado %s", id, got.Status, want)
+			}
+		}
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		repo := newRepo(t)
+		order := newTestOrder(1, 10)
+		if err := repo.Create(ctx, order); err != nil {
+			t.Fatal(err)
+		}
+
+		order.Status = model.StatusConfirmed
+		order.Items = append(order.Items, model.OrderItem{ProductID: 200, Name: "Extra", Price: 5, Quantity: 1})
+		if err := repo.Update(ctx, order); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := repo.GetByID(ctx, order.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Status != model.StatusConfirmed || got.TotalAmount != 25 || len(got.Items) != 2 {
+			t.Errorf("pedido = %s, total %.2f, %d items; esperado confirmed, 25.00, 2 items",
+				got.Status, got.TotalAmount, len(got.Items))
+		}
+		if got.UpdatedAt.Before(got.CreatedAt) {
+			t.Errorf("updated_at %s antes de created_at %s", got.UpdatedAt, got.CreatedAt)
+		}
+	})
+
+	t.Run("SoftDelete", func(t *testing.T) {
+		repo := newRepo(t)
+		kept, deleted := newTestOrder(1, 1), newTestOrder(1, 1)
+		for _, order := range []*model.Order{kept, deleted} {
+			if err := repo.Create(ctx, order); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := repo.Create(ctx, newTestOrder(2, 1)); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := repo.Delete(ctx, deleted.ID); err != nil {
+			t.Fatal(err)
+		}
+		if err := repo.Delete(ctx, deleted.ID); err != nil {
+			t.Fatalf("segundo Delete: %v", err)
+		}
+
+		if _, err := repo.GetByID(ctx, deleted.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Errorf("GetByID do pedido removido: err = %v", err)
+		}
+		if got, _ := repo.GetByIDs(ctx, []uint{kept.ID, deleted.ID}); len(got) != 1 {
+			t.Errorf("GetByIDs devolveu %d pedidos, esperado 1", len(got))
+		}
+		if got, _ := repo.GetByCustomerID(ctx, 1, 10, 0); len(got) != 1 || got[0].ID != kept.ID {
+			t.Errorf("GetByCustomerID = %v, esperado só o pedido %d", got, kept.ID)
+		}
+
+		// Status de pedido removido não muda
+		if err := repo.UpdateStatus(ctx, deleted.ID, model.StatusConfirmed); err != nil {
+			t.Fatal(err)
+		}
+
+		if count, _ := repo.Count(ctx); count != 2 {
+			t.Errorf("Count = %d, esperado 2", count)
+		}
+		if count, _ := repo.CountByCustomer(ctx, 1); count != 1 {
+			t.Errorf("CountByCustomer = %d, esperado 1", count)
+		}
+	})
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"order-service/internal/order/model"
+	"order-service/pkg/transaction"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SummaryRepository grava e consulta o read model customer_order_summary.
+type SummaryRepository interface {
+	// GetForUpdate devolve o resumo do pedido bloqueado até o fim da
+	// transação em andamento, ou gorm.ErrRecordNotFound.
+	GetForUpdate(ctx context.Context, orderID uint) (*model.OrderSummary, error)
+	Save(ctx context.Context, summary *model.OrderSummary) error
+	ListByCustomer(ctx context.Context, customerID uint, limit, offset int) ([]model.OrderSummary, error)
+	// Rebuild substitui o read model pelo estado atual de orders, com os
+	// eventos até at considerados aplicados, e devolve quantos pedidos gravou.
+	Rebuild(ctx context.Context, at time.Time) (int64, error)
+}
+
+type summaryRepository struct {
+	db *gorm.DB
+}
+
+func NewSummaryRepository(db *gorm.DB) SummaryRepository {
+	return &summaryRepository{db: db}
+}
+
+func (r *summaryRepository) GetForUpdate(ctx context.Context, orderID uint) (*model.OrderSummary, error) {
+	var summary model.OrderSummary
+	err := transaction.DB(ctx, r.db).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("order_id = ?", orderID).
+		First(&summary).Error
+	if err != nil {
+		return nil, err
+	}
+	return &summary, nil
+}
+
+func (r *summaryRepository) Save(ctx context.Context, summary *model.OrderSummary) error {
+	summary.TotalAmount = roundCents(summary.TotalAmount)
+	return transaction.DB(ctx, r.db).Clauses(clause.OnConflict{UpdateAll: true}).Create(summary).Error
+}
+
+func (r *summaryRepository) ListByCustomer(ctx context.Context, customerID uint, limit, offset int) ([]model.OrderSummary, error) {
+	var summaries []model.OrderSummary
+	err := transaction.DB(ctx, r.db).
+		Where("customer_id = ?", customerID).
+		Order("created_at DESC, order_id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&summaries).Error
+
+	return summaries, err
+}
+
+func (r *summaryRepository) Rebuild(ctx context.Context, at time.Time) (int64, error) {
+	var rebuilt int64
+	err := transaction.DB(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM customer_order_summary").Error; err != nil {
+			return err
+		}
+		result := tx.Exec(`INSERT INTO customer_order_summary
+			(order_id, customer_id, status, item_count, total_quantity, total_amount, created_at, updated_at, last_event_at)
+			SELECT o.id, o.customer_id, COALESCE(o.status, 'pending'), COUNT(i.id), COALESCE(SUM(i.quantity), 0), o.total_amount,
+				o.created_at, o.updated_at, ?
+			FROM orders o
+			LEFT JOIN order_items i ON i.order_id = o.id AND i.deleted_at IS NULL
+			WHERE o.deleted_at IS NULL
+			GROUP BY o.id, o.customer_id, o.status, o.total_amount, o.created_at, o.updated_at`, at)
+		rebuilt = result.RowsAffected
+		return result.Error
+	})
+	return rebuilt, err
+}
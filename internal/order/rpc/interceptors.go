@@ -0,0 +1,121 @@
+package rpc
+
+import (
+	"context"
+	"log/slog"
+	"runtime/debug"
+	"strconv"
+	"time"
+
+	"order-service/pkg/logger"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Ordem dos interceptors: o log/métrica fica por fora, então também registra
+// as chamadas que terminaram em pânico (Internal).
+func serverOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(observeUnary, recoverUnary),
+		grpc.ChainStreamInterceptor(observeStream, recoverStream),
+	}
+}
+
+// requestContext é o equivalente do logger.Middleware do HTTP: request ID
+// (metadata x-request-id, gerado se ausente) e cliente no contexto.
+func requestContext(ctx context.Context) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	requestID := first(md, "x-request-id")
+	if requestID == "" {
+		requestID = logger.NewRequestID()
+	}
+	ctx = logger.WithRequestID(ctx, requestID)
+	if customerID, err := strconv.ParseUint(first(md, customerIDKey), 10, 32); err == nil {
+		ctx = logger.WithCustomerID(ctx, uint(customerID))
+	}
+	return ctx
+}
+
+func first(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// observe registra a linha de acesso e a duração da chamada.
+func observe(ctx context.Context, method string, start time.Time, err error) {
+	code := status.Code(err)
+	duration := time.Since(start)
+	grpcRequestDuration.WithLabelValues(method, code.String()).Observe(duration.Seconds())
+
+	level := slog.LevelInfo
+	switch code {
+	case codes.OK, codes.Canceled:
+	case codes.Internal, codes.Unknown, codes.DataLoss, codes.Unavailable:
+		level = slog.LevelError
+	default:
+		level = slog.LevelWarn
+	}
+
+	attrs := []any{"method", method, "code", code.String(), "duration", duration}
+	if err != nil {
+		attrs = append(attrs, "error", status.Convert(err).Message())
+	}
+	slog.Log(ctx, level, "Chamada gRPC", attrs...)
+}
+
+func observeUnary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+	ctx = requestContext(ctx)
+	resp, err := handler(ctx, req)
+	observe(ctx, info.FullMethod, start, err)
+	return resp, err
+}
+
+func observeStream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	ctx := requestContext(ss.Context())
+	err := handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	observe(ctx, info.FullMethod, start, err)
+	return err
+}
+
+// contextStream troca o contexto do stream pelo de requestContext.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
+
+// recovered converte um pânico do handler em Internal, com o stack no log,
+// em vez de derrubar o processo.
+func recovered(ctx context.Context, method string, p any) error {
+	slog.ErrorContext(ctx, "Pânico em chamada gRPC", "method", method, "panic", p, "stack", string(debug.Stack()))
+	return status.Error(codes.Internal, "erro interno")
+}
+
+func recoverUnary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = recovered(ctx, info.FullMethod, p)
+		}
+	}()
+	return handler(ctx, req)
+}
+
+func recoverStream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = recovered(ss.Context(), info.FullMethod, p)
+		}
+	}()
+	return handler(srv, ss)
+}
@@ -0,0 +1,16 @@
+package rpc
+
+import (
+	"order-service/pkg/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var grpcRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: metrics.Namespace,
+	Subsystem: "grpc",
+	Name:      "request_duration_seconds",
+	Help:      "Duração das chamadas gRPC por método e código.",
+	Buckets:   prometheus.DefBuckets,
+}, []string{"method", "code"})
@@ -0,0 +1,275 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	orderv1 "order-service/api/order/v1"
+	"order-service/internal/order/model"
+	"order-service/internal/order/service"
+	"order-service/pkg/mq"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type OrderServer struct {
+	orderv1.UnimplementedOrderServiceServer
+	orderService service.OrderService
+	summaries    service.SummaryService
+	events       *mq.Broadcaster
+}
+
+func NewOrderServer(orderService service.OrderService, summaries service.SummaryService, events *mq.Broadcaster) *OrderServer {
+	return &OrderServer{
+		orderService: orderService,
+		summaries:    summaries,
+		events:       events,
+	}
+}
+
+func (s *OrderServer) CreateOrder(ctx context.Context, req *orderv1.CreateOrderRequest) (*orderv1.Order, error) {
+	if req.GetCustomerId() == 0 {
+		return nil, status.Error(codes.InvalidArgument, "customer_id é obrigatório")
+	}
+
+	createReq := model.CreateOrderRequest{
+		CustomerID: uint(req.GetCustomerId()),
+		Items:      make([]model.CreateOrderItemRequest, len(req.GetItems())),
+	}
+	for i, item := range req.GetItems() {
+		if item.GetProductId() == 0 || item.GetName() == "" || item.GetPrice() < 0 || item.GetQuantity() < 1 {
+			return nil, status.Errorf(codes.InvalidArgument, "item %d inválido", i)
+		}
+		createReq.Items[i] = model.CreateOrderItemRequest{
+			ProductID: uint(item.GetProductId()),
+			Name:      item.GetName(),
+			Price:     item.GetPrice(),
+			Quantity:  int(item.GetQuantity()),
+		}
+	}
+
+	order, err := s.orderService.CreateOrder(ctx, createReq)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toProto(order), nil
+}
+
+func (s *OrderServer) GetOrder(ctx context.Context, req *orderv1.GetOrderRequest) (*orderv1.Order, error) {
+	order, err := s.orderService.GetOrderByID(ctx, uint(req.GetId()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toProto(order), nil
+}
+
+func (s *OrderServer) ListOrders(ctx context.Context, req *orderv1.ListOrdersRequest) (*orderv1.ListOrdersResponse, error) {
+	if req.GetCustomerId() == 0 {
+		return nil, status.Error(codes.InvalidArgument, "customer_id é obrigatório")
+	}
+
+	// Mesmos padrões de paginação do handler HTTP
+	limit := int(req.GetLimit())
+	if limit <= 0 {
+		limit = 10
+	}
+	offset := max(int(req.GetOffset()), 0)
+
+	// Read model das listagens, como o GET /api/v1/orders?customer_id=
+	orders, err := s.summaries.ListCustomerOrders(ctx, uint(req.GetCustomerId()), limit, offset)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	resp := &orderv1.ListOrdersResponse{
+		Orders: make([]*orderv1.OrderSummary, len(orders)),
+		Count:  int32(len(orders)),
+		Limit:  int32(limit),
+		Offset: int32(offset),
+	}
+	for i := range orders {
+		resp.Orders[i] = toProtoSummary(&orders[i])
+	}
+	return resp, nil
+}
+
+func (s *OrderServer) UpdateOrderStatus(ctx context.Context, req *orderv1.UpdateOrderStatusRequest) (*orderv1.Order, error) {
+	newStatus, ok := fromProtoStatus(req.GetStatus())
+	if !ok {
+		return nil, status.Error(codes.InvalidArgument, "status inválido")
+	}
+
+	order, err := s.orderService.UpdateOrderStatus(ctx, uint(req.GetId()), newStatus)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toProto(order), nil
+}
+
+func (s *OrderServer) CancelOrder(ctx context.Context, req *orderv1.CancelOrderRequest) (*orderv1.CancelOrderResponse, error) {
+	if err := s.orderService.CancelOrder(ctx, uint(req.GetId())); err != nil {
+		return nil, toStatus(err)
+	}
+	return &orderv1.CancelOrderResponse{}, nil
+}
+
+func (s *OrderServer) WatchOrder(req *orderv1.WatchOrderRequest, stream orderv1.OrderService_WatchOrderServer) error {
+	ctx := stream.Context()
+	orderID := uint(req.GetId())
+	customerID, err := customerScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Assinar antes do snapshot para não perder eventos entre os dois
+	events, unsubscribe := s.events.Subscribe(func(event mq.OrderEvent) bool {
+		return uint(event.OrderID) == orderID
+	})
+	defer unsubscribe()
+
+	order, err := s.orderService.GetOrderByID(ctx, orderID)
+	// Pedido de outro cliente responde como inexistente, para o stream não
+	// revelar quais IDs existem
+	if errors.Is(err, service.ErrOrderNotFound) || (err == nil && order.CustomerID != customerID) {
+		return status.Error(codes.NotFound, service.ErrOrderNotFound.Error())
+	}
+	if err != nil {
+		return toStatus(err)
+	}
+	if err := stream.Send(toUpdate("snapshot", order)); err != nil {
+		return err
+	}
+
+	for !isFinal(order.Status) {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-events:
+			if !ok {
+				return status.Error(codes.Unavailable, "servidor em desligamento")
+			}
+			order, err = s.orderService.GetOrderByID(ctx, orderID)
+			if err != nil {
+				return toStatus(err)
+			}
+			if err := stream.Send(toUpdate(event.Type, order)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// customerIDKey é o metadata com o cliente autenticado, o equivalente do
+// header X-Customer-ID dos streams HTTP.
+const customerIDKey = "x-customer-id"
+
+// customerScope lê o cliente autenticado do metadata x-customer-id; sem ele
+// (ou com um valor inválido) o stream é recusado.
+func customerScope(ctx context.Context) (uint, error) {
+	var scope string
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get(customerIDKey); len(values) > 0 {
+		scope = values[0]
+	}
+	id, err := strconv.ParseUint(scope, 10, 32)
+	if err != nil {
+		return 0, status.Error(codes.Unauthenticated, "metadata x-customer-id obrigatório")
+	}
+	return uint(id), nil
+}
+
+func isFinal(s model.OrderStatus) bool {
+	return s == model.StatusDelivered || s == model.StatusCancelled || s == model.StatusFailed
+}
+
+// toStatus traduz os erros de domínio do service para códigos gRPC. Prazo
+// do cliente ou cancelamento vêm primeiro; falhas do banco viram Internal.
+func toStatus(err error) error {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled):
+		return status.FromContextError(err).Err()
+	case errors.Is(err, service.ErrOrderNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, service.ErrEmptyOrder), errors.Is(err, service.ErrInvalidOrder):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, service.ErrInvalidStatusTransition),
+		errors.Is(err, service.ErrOrderNotCancellable):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+func toUpdate(eventType string, order *model.OrderResponse) *orderv1.OrderUpdate {
+	return &orderv1.OrderUpdate{
+		EventType:  eventType,
+		Order:      toProto(order),
+		OccurredAt: timestamppb.Now(),
+	}
+}
+
+func toProto(o *model.OrderResponse) *orderv1.Order {
+	items := make([]*orderv1.OrderItem, len(o.Items))
+	for i, item := range o.Items {
+		items[i] = &orderv1.OrderItem{
+			Id:        uint64(item.ID),
+			ProductId: uint64(item.ProductID),
+			Name:      item.Name,
+			Price:     item.Price,
+			Quantity:  int32(item.Quantity),
+			Subtotal:  item.Subtotal,
+		}
+	}
+
+	return &orderv1.Order{
+		Id:          uint64(o.ID),
+		CustomerId:  uint64(o.CustomerID),
+		Status:      toProtoStatus(o.Status),
+		TotalAmount: o.TotalAmount,
+		Items:       items,
+		CreatedAt:   timestamppb.New(o.CreatedAt),
+		UpdatedAt:   timestamppb.New(o.UpdatedAt),
+	}
+}
+
+func toProtoSummary(o *model.OrderSummary) *orderv1.OrderSummary {
+	return &orderv1.OrderSummary{
+		Id:            uint64(o.ID),
+		CustomerId:    uint64(o.CustomerID),
+		Status:        toProtoStatus(o.Status),
+		TotalAmount:   o.TotalAmount,
+		ItemCount:     int32(o.ItemCount),
+		TotalQuantity: int32(o.TotalQuantity),
+		CreatedAt:     timestamppb.New(o.CreatedAt),
+		UpdatedAt:     timestamppb.New(o.UpdatedAt),
+	}
+}
+
+var statusToProto = map[model.OrderStatus]orderv1.OrderStatus{
+	model.StatusPending:   orderv1.OrderStatus_ORDER_STATUS_PENDING,
+	model.StatusConfirmed: orderv1.OrderStatus_ORDER_STATUS_CONFIRMED,
+	model.StatusPaid:      orderv1.OrderStatus_ORDER_STATUS_PAID,
+	model.StatusShipped:   orderv1.OrderStatus_ORDER_STATUS_SHIPPED,
+	model.StatusDelivered: orderv1.OrderStatus_ORDER_STATUS_DELIVERED,
+	model.StatusCancelled: orderv1.OrderStatus_ORDER_STATUS_CANCELLED,
+	model.StatusFailed:    orderv1.OrderStatus_ORDER_STATUS_FAILED,
+}
+
+func toProtoStatus(s model.OrderStatus) orderv1.OrderStatus {
+	return statusToProto[s]
+}
+
+func fromProtoStatus(s orderv1.OrderStatus) (model.OrderStatus, bool) {
+	for st, proto := range statusToProto {
+		if proto == s {
+			return st, true
+		}
+	}
+	return "", false
+}
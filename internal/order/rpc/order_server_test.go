@@ -0,0 +1,301 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	orderv1 "order-service/api/order/v1"
+	"order-service/internal/order/model"
+	"order-service/internal/order/repository"
+	"order-service/internal/order/service"
+	"order-service/pkg/mq"
+	"order-service/pkg/mq/mqtest"
+	"order-service/pkg/transaction"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type rpcTest struct {
+	client    orderv1.OrderServiceClient
+	health    healthpb.HealthClient
+	healthSrv *health.Server
+	orders    service.OrderService
+	events    *mq.Broadcaster
+}
+
+// newRPCTest sobe o servidor gRPC completo (NewServer, com os interceptors)
+// sobre bufconn, com os services em memória.
+func newRPCTest(t *testing.T, summaries service.SummaryService) *rpcTest {
+	t.Helper()
+
+	repo := repository.NewMemoryOrderRepository()
+	orders := service.NewOrderService(repo, transaction.NewMemoryManager(3, repo), mqtest.NewPublisher())
+	events := mq.NewBroadcaster(10)
+
+	listener := bufconn.Listen(1 << 20)
+	server, healthSrv := NewServer(NewOrderServer(orders, summaries, events))
+	go server.Serve(listener)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		conn.Close()
+		events.Close()
+		server.Stop()
+	})
+
+	return &rpcTest{
+		client:    orderv1.NewOrderServiceClient(conn),
+		health:    healthpb.NewHealthClient(conn),
+		healthSrv: healthSrv,
+		orders:    orders,
+		events:    events,
+	}
+}
+
+func (rt *rpcTest) createOrder(t *testing.T, customerID uint) *model.OrderResponse {
+	t.Helper()
+	order, err := rt.orders.CreateOrder(context.Background(), model.CreateOrderRequest{
+		CustomerID: customerID,
+		Items:      []model.CreateOrderItemRequest{{ProductID: 10, Name: "Teclado", Price: 150, Quantity: 2}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return order
+}
+
+func asCustomer(ctx context.Context, customerID string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, customerIDKey, customerID)
+}
+
+func TestWatchOrderRequiresCustomerScope(t *testing.T) {
+	rt := newRPCTest(t, nil)
+	order := rt.createOrder(t, 1)
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		id   uint
+		want codes.Code
+	}{
+		// O metadata é conferido antes da busca
+		{"sem x-customer-id", context.Background(), 999, codes.Unauthenticated},
+		{"x-customer-id inválido", asCustomer(context.Background(), "abc"), order.ID, codes.Unauthenticated},
+		// Pedido de outro cliente e inexistente são indistinguíveis
+		{"outro cliente", asCustomer(context.Background(), "2"), order.ID, codes.NotFound},
+		{"pedido inexistente", asCustomer(context.Background(), "2"), 999, codes.NotFound},
+	}
+	messages := map[codes.Code]string{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(tt.ctx, 5*time.Second)
+			defer cancel()
+
+			stream, err := rt.client.WatchOrder(ctx, &orderv1.WatchOrderRequest{Id: uint64(tt.id)})
+			if err == nil {
+				_, err = stream.Recv()
+			}
+			if status.Code(err) != tt.want {
+				t.Errorf("erro = %v, esperado %s", err, tt.want)
+			}
+			msg := status.Convert(err).Message()
+			if previous, ok := messages[tt.want]; ok && previous != msg {
+				t.Errorf("mensagem = %q, esperado %q", msg, previous)
+			}
+			messages[tt.want] = msg
+		})
+	}
+}
+
+func TestToStatus(t *testing.T) {
+	tests := []struct {
+		err  error
+		want codes.Code
+	}{
+		{context.DeadlineExceeded, codes.DeadlineExceeded},
+		{fmt.Errorf("consulta: %w", context.Canceled), codes.Canceled},
+		{fmt.Errorf("%w: %w", service.ErrOrderNotFound, errors.New("record not found")), codes.NotFound},
+		{service.ErrEmptyOrder, codes.InvalidArgument},
+		{fmt.Errorf("%w: customer_id é obrigatório", service.ErrInvalidOrder), codes.InvalidArgument},
+		{fmt.Errorf("%w: pending -> delivered", service.ErrInvalidStatusTransition), codes.FailedPrecondition},
+		{fmt.Errorf("%w delivered", service.ErrOrderNotCancellable), codes.FailedPrecondition},
+		{errors.New("connection refused"), codes.Internal},
+	}
+	for _, tt := range tests {
+		if got := status.Code(toStatus(tt.err)); got != tt.want {
+			t.Errorf("toStatus(%v) = %s, esperado %s", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestErrorCodes(t *testing.T) {
+	rt := newRPCTest(t, nil)
+	ctx := context.Background()
+	order := rt.createOrder(t, 1)
+
+	tests := []struct {
+		name string
+		call func() error
+		want codes.Code
+	}{
+		{"pedido inexistente", func() error {
+			_, err := rt.client.GetOrder(ctx, &orderv1.GetOrderRequest{Id: 999})
+			return err
+		}, codes.NotFound},
+		{"sem customer_id", func() error {
+			_, err := rt.client.CreateOrder(ctx, &orderv1.CreateOrderRequest{})
+			return err
+		}, codes.InvalidArgument},
+		{"sem items", func() error {
+			_, err := rt.client.CreateOrder(ctx, &orderv1.CreateOrderRequest{CustomerId: 1})
+			return err
+		}, codes.InvalidArgument},
+		{"status não informado", func() error {
+			_, err := rt.client.UpdateOrderStatus(ctx, &orderv1.UpdateOrderStatusRequest{Id: uint64(order.ID)})
+			return err
+		}, codes.InvalidArgument},
+		{"transição inválida", func() error {
+			_, err := rt.client.UpdateOrderStatus(ctx, &orderv1.UpdateOrderStatusRequest{
+				Id: uint64(order.ID), Status: orderv1.OrderStatus_ORDER_STATUS_DELIVERED,
+			})
+			return err
+		}, codes.FailedPrecondition},
+		{"listagem sem customer_id", func() error {
+			_, err := rt.client.ListOrders(ctx, &orderv1.ListOrdersRequest{})
+			return err
+		}, codes.InvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.call(); status.Code(err) != tt.want {
+				t.Errorf("erro = %v, esperado %s", err, tt.want)
+			}
+		})
+	}
+}
+
+// summaries devolve resumos fixos em ListCustomerOrders.
+type summaries struct {
+	service.SummaryService
+	list []model.OrderSummary
+}
+
+func (s summaries) ListCustomerOrders(_ context.Context, _ uint, limit, offset int) ([]model.OrderSummary, error) {
+	return s.list[min(offset, len(s.list)):min(offset+limit, len(s.list))], nil
+}
+
+func TestListOrdersReturnsSummaries(t *testing.T) {
+	rt := newRPCTest(t, summaries{list: []model.OrderSummary{
+		{ID: 1, CustomerID: 1, Status: model.StatusPaid, TotalAmount: 300, ItemCount: 2, TotalQuantity: 3},
+		{ID: 2, CustomerID: 1, Status: model.StatusPending, TotalAmount: 50, ItemCount: 1, TotalQuantity: 1},
+	}})
+
+	resp, err := rt.client.ListOrders(context.Background(), &orderv1.ListOrdersRequest{CustomerId: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.GetCount() != 2 || resp.GetLimit() != 10 || resp.GetOffset() != 0 {
+		t.Fatalf("count=%d limit=%d offset=%d; esperado 2, 10, 0", resp.GetCount(), resp.GetLimit(), resp.GetOffset())
+	}
+	first := resp.GetOrders()[0]
+	if first.GetStatus() != orderv1.OrderStatus_ORDER_STATUS_PAID || first.GetItemCount() != 2 || first.GetTotalQuantity() != 3 {
+		t.Errorf("resumo = %v", first)
+	}
+}
+
+func TestWatchOrderStreamsUntilFinalStatus(t *testing.T) {
+	rt := newRPCTest(t, nil)
+	order := rt.createOrder(t, 1)
+
+	ctx, cancel := context.WithTimeout(asCustomer(context.Background(), "1"), 5*time.Second)
+	defer cancel()
+	stream, err := rt.client.WatchOrder(ctx, &orderv1.WatchOrderRequest{Id: uint64(order.ID)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	update, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if update.GetEventType() != "snapshot" || update.GetOrder().GetStatus() != orderv1.OrderStatus_ORDER_STATUS_PENDING {
+		t.Fatalf("primeira mensagem = %s/%s, esperado snapshot/pending", update.GetEventType(), update.GetOrder().GetStatus())
+	}
+
+	// Eventos de outros pedidos não chegam ao stream; o do pedido traz o estado atual
+	if err := rt.orders.CancelOrder(context.Background(), order.ID); err != nil {
+		t.Fatal(err)
+	}
+	rt.events.Broadcast(mq.OrderEvent{ID: "e1", Type: "status_changed", OrderID: int(order.ID) + 1})
+	rt.events.Broadcast(mq.OrderEvent{ID: "e2", Type: "cancelled", OrderID: int(order.ID)})
+
+	update, err = stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if update.GetEventType() != "cancelled" || update.GetOrder().GetStatus() != orderv1.OrderStatus_ORDER_STATUS_CANCELLED {
+		t.Fatalf("mensagem = %s/%s, esperado cancelled/cancelled", update.GetEventType(), update.GetOrder().GetStatus())
+	}
+
+	// Status final encerra o stream
+	if _, err := stream.Recv(); err != io.EOF {
+		t.Errorf("erro = %v, esperado fim do stream", err)
+	}
+}
+
+func TestRecoverInterceptors(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: "/order.v1.OrderService/GetOrder"}
+	_, err := recoverUnary(context.Background(), nil, info, func(context.Context, any) (any, error) {
+		panic("nil map")
+	})
+	if status.Code(err) != codes.Internal {
+		t.Errorf("unary: erro = %v, esperado Internal", err)
+	}
+
+	streamInfo := &grpc.StreamServerInfo{FullMethod: "/order.v1.OrderService/WatchOrder"}
+	err = recoverStream(nil, &contextStream{ctx: context.Background()}, streamInfo, func(any, grpc.ServerStream) error {
+		panic("nil map")
+	})
+	if status.Code(err) != codes.Internal {
+		t.Errorf("stream: erro = %v, esperado Internal", err)
+	}
+}
+
+func TestHealthNotServingAfterShutdown(t *testing.T) {
+	rt := newRPCTest(t, nil)
+	ctx := context.Background()
+	named := &healthpb.HealthCheckRequest{Service: orderv1.OrderService_ServiceDesc.ServiceName}
+
+	resp, err := rt.health.Check(ctx, named)
+	if err != nil || resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("antes do desligamento: %v, %v; esperado SERVING", resp.GetStatus(), err)
+	}
+
+	rt.healthSrv.Shutdown()
+	for _, req := range []*healthpb.HealthCheckRequest{{}, named} {
+		resp, err := rt.health.Check(ctx, req)
+		if err != nil || resp.GetStatus() != healthpb.HealthCheckResponse_NOT_SERVING {
+			t.Errorf("%q depois do desligamento: %v, %v; esperado NOT_SERVING", req.GetService(), resp.GetStatus(), err)
+		}
+	}
+}
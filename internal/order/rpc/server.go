@@ -0,0 +1,29 @@
+package rpc
+
+import (
+	orderv1 "order-service/api/order/v1"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+// NewServer cria o servidor gRPC com o OrderService, grpc-health e
+// reflection, com recuperação de pânico, log de acesso e métricas em todas
+// as chamadas. O health server é devolvido para o desligamento marcar o
+// serviço como NOT_SERVING (Shutdown) antes de parar o servidor.
+func NewServer(orderServer *OrderServer) (*grpc.Server, *health.Server) {
+	server := grpc.NewServer(serverOptions()...)
+
+	orderv1.RegisterOrderServiceServer(server, orderServer)
+
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	healthServer.SetServingStatus(orderv1.OrderService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(server, healthServer)
+
+	reflection.Register(server)
+
+	return server, healthServer
+}
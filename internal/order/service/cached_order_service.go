@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"order-service/internal/order/model"
+	"order-service/pkg/cache"
+	"order-service/pkg/mq"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// CachedOrderService guarda as respostas de GetOrderByID em c por até ttl.
+// As demais leituras passam direto para o OrderService decorado. Uma
+// alteração feita por ele remove o pedido do cache logo depois. As feitas
+// por outras réplicas chegam pelos eventos order.* (HandleEvent).
+type CachedOrderService struct {
+	OrderService
+	cache cache.Cache
+	ttl   time.Duration
+	loads singleflight.Group
+	// Invalidações feitas até agora: uma leitura do banco só vai para o
+	// cache se nenhuma aconteceu enquanto ela rodava, para não gravar um
+	// pedido anterior à alteração
+	invalidations atomic.Uint64
+}
+
+func NewCachedOrderService(next OrderService, c cache.Cache, ttl time.Duration) *CachedOrderService {
+	return &CachedOrderService{
+		OrderService: next,
+		cache:        c,
+		ttl:          ttl,
+	}
+}
+
+func orderCacheKey(id uint) string {
+	return "order:" + strconv.FormatUint(uint64(id), 10)
+}
+
+// GetOrderByID busca o pedido no cache e, na falta, no OrderService.
+// Leituras simultâneas do mesmo pedido ausente fazem uma única consulta.
+// Falhas do cache só são logadas: a leitura segue pelo banco.
+func (s *CachedOrderService) GetOrderByID(ctx context.Context, id uint) (*model.OrderResponse, error) {
+	key := orderCacheKey(id)
+	if order, ok := s.cached(ctx, key); ok {
+		orderCacheRequests.WithLabelValues("hit").Inc()
+		return order, nil
+	}
+	orderCacheRequests.WithLabelValues("miss").Inc()
+
+	// A consulta é compartilhada: não pode ser cancelada com a requisição
+	// de quem a iniciou
+	loadCtx := context.WithoutCancel(ctx)
+	result := s.loads.DoChan(key, func() (any, error) {
+		before := s.invalidations.Load()
+		order, err := s.OrderService.GetOrderByID(loadCtx, id)
+		if err != nil {
+			return nil, err
+		}
+		if s.invalidations.Load() == before {
+			s.store(loadCtx, key, order)
+		}
+		return order, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-result:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		// Cópia por chamador: a resposta compartilhada não pode ser alterada
+		order := *res.Val.(*model.OrderResponse)
+		order.Items = append([]model.OrderItemResponse(nil), order.Items...)
+		return &order, nil
+	}
+}
+
+func (s *CachedOrderService) cached(ctx context.Context, key string) (*model.OrderResponse, bool) {
+	raw, ok, err := s.cache.Get(ctx, key)
+	if err != nil {
+		orderCacheRequests.WithLabelValues("error").Inc()
+		slog.WarnContext(ctx, "Erro ao ler pedido do cache", "key", key, "error", err)
+		return nil, false
+	}
+	if !ok {
+		return nil, false
+	}
+
+	var order model.OrderResponse
+	if err := json.Unmarshal(raw, &order); err != nil {
+		slog.WarnContext(ctx, "Pedido inválido no cache", "key", key, "error", err)
+		return nil, false
+	}
+	return &order, true
+}
+
+func (s *CachedOrderService) store(ctx context.Context, key string, order *model.OrderResponse) {
+	raw, err := json.Marshal(order)
+	if err == nil {
+		err = s.cache.Set(ctx, key, raw, s.ttl)
+	}
+	if err != nil {
+		slog.WarnContext(ctx, "Erro ao gravar pedido no cache", "key", key, "error", err)
+	}
+}
+
+// invalidate remove os pedidos do cache e descarta as leituras em
+// andamento, para as próximas consultarem o banco de novo.
+func (s *CachedOrderService) invalidate(ctx context.Context, source string, ids ...uint) {
+	if len(ids) == 0 {
+		return
+	}
+	s.invalidations.Add(1)
+
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = orderCacheKey(id)
+		s.loads.Forget(keys[i])
+	}
+	if err := s.cache.Delete(ctx, keys...); err != nil {
+		// Sem a remoção, o pedido antigo fica no cache até expirar
+		slog.WarnContext(ctx, "Erro ao invalidar pedidos no cache", "keys", keys, "error", err)
+		return
+	}
+	orderCacheInvalidations.WithLabelValues(source).Add(float64(len(keys)))
+}
+
+func (s *CachedOrderService) UpdateOrderStatus(ctx context.Context, id uint, status model.OrderStatus) (*model.OrderResponse, error) {
+	defer s.invalidate(context.WithoutCancel(ctx), "write", id)
+	return s.OrderService.UpdateOrderStatus(ctx, id, status)
+}
+
+func (s *CachedOrderService) AdvanceOrderStatus(ctx context.Context, id uint, status model.OrderStatus) (*model.OrderResponse, error) {
+	defer s.invalidate(context.WithoutCancel(ctx), "write", id)
+	return s.OrderService.AdvanceOrderStatus(ctx, id, status)
+}
+
+func (s *CachedOrderService) CancelOrder(ctx context.Context, id uint) error {
+	defer s.invalidate(context.WithoutCancel(ctx), "write", id)
+	return s.OrderService.CancelOrder(ctx, id)
+}
+
+// UpdateOrderStatuses invalida todos os pedidos do lote, mesmo com falha:
+// no modo partial, parte deles pode ter sido alterada.
+func (s *CachedOrderService) UpdateOrderStatuses(ctx context.Context, req model.BatchUpdateStatusRequest) (*model.BatchResponse, error) {
+	ids := make([]uint, len(req.Updates))
+	for i, update := range req.Updates {
+		ids[i] = update.ID
+	}
+	defer s.invalidate(context.WithoutCancel(ctx), "write", ids...)
+	return s.OrderService.UpdateOrderStatuses(ctx, req)
+}
+
+// HandleEvent invalida o pedido de um evento order.*. Deve ser o
+// mq.EventHandler de uma fila exclusiva de cada réplica, para todas
+// receberem as alterações feitas pelas outras.
+func (s *CachedOrderService) HandleEvent(ctx context.Context, event mq.OrderEvent) error {
+	if event.OrderID > 0 {
+		s.invalidate(ctx, "event", uint(event.OrderID))
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"order-service/internal/order/model"
+	"order-service/pkg/cache"
+	"order-service/pkg/mq"
+)
+
+func TestCachedOrderServiceInvalidation(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, _ := newTestService()
+	cached := NewCachedOrderService(svc, cache.NewLRU(10), time.Minute)
+
+	order := createTestOrder(t, cached)
+	if _, err := cached.GetOrderByID(ctx, order.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// Alteração que não passa pelo serviço (outra réplica): segue em cache
+	if err := repo.UpdateStatus(ctx, order.ID, model.StatusConfirmed); err != nil {
+		t.Fatal(err)
+	}
+	got, err := cached.GetOrderByID(ctx, order.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != model.StatusPending {
+		t.Fatalf("status = %s, esperado pending do cache", got.Status)
+	}
+
+	// O evento da outra réplica invalida
+	if err := cached.HandleEvent(ctx, mq.OrderEvent{Type: "status_changed", OrderID: int(order.ID)}); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := cached.GetOrderByID(ctx, order.ID); got.Status != model.StatusConfirmed {
+		t.Fatalf("status = %s depois do evento, esperado confirmed", got.Status)
+	}
+
+	// Alteração pelo próprio serviço invalida sem esperar o evento
+	if _, err := cached.UpdateOrderStatus(ctx, order.ID, model.StatusPaid); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := cached.GetOrderByID(ctx, order.ID); got.Status != model.StatusPaid {
+		t.Fatalf("status = %s depois da alteração, esperado paid", got.Status)
+	}
+
+	// A resposta devolvida é uma cópia
+	got.Items[0].Quantity = 99
+	if again, _ := cached.GetOrderByID(ctx, order.ID); again.Items[0].Quantity != 2 {
+		t.Errorf("quantidade = %d, alteração da resposta chegou ao cache", again.Items[0].Quantity)
+	}
+}
+
+// blockingOrderService conta as leituras e as segura até release fechar.
+type blockingOrderService struct {
+	OrderService
+	loads   atomic.Int32
+	started chan struct{}
+	release chan struct{}
+}
+
+func (s *blockingOrderService) GetOrderByID(ctx context.Context, id uint) (*model.OrderResponse, error) {
+	if s.loads.Add(1) == 1 {
+		close(s.started)
+	}
+	<-s.release
+	return s.OrderService.GetOrderByID(ctx, id)
+}
+
+func TestCachedOrderServiceCollapsesLoads(t *testing.T) {
+	ctx := context.Background()
+	svc, _, _ := newTestService()
+	order := createTestOrder(t, svc)
+
+	backend := &blockingOrderService{OrderService: svc, started: make(chan struct{}), release: make(chan struct{})}
+	c := cache.NewLRU(10)
+	cached := NewCachedOrderService(backend, c, time.Minute)
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := cached.GetOrderByID(ctx, order.ID); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	<-backend.started
+	time.Sleep(20 * time.Millisecond)
+	close(backend.release)
+	wg.Wait()
+
+	if n := backend.loads.Load(); n != 1 {
+		t.Errorf("%d leituras do banco, esperado 1", n)
+	}
+	if c.Len() != 1 {
+		t.Errorf("%d pedidos no cache, esperado 1", c.Len())
+	}
+}
+
+func TestCachedOrderServiceSkipsStaleLoad(t *testing.T) {
+	ctx := context.Background()
+	svc, _, _ := newTestService()
+	order := createTestOrder(t, svc)
+
+	backend := &blockingOrderService{OrderService: svc, started: make(chan struct{}), release: make(chan struct{})}
+	c := cache.NewLRU(10)
+	cached := NewCachedOrderService(backend, c, time.Minute)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		cached.GetOrderByID(ctx, order.ID)
+	}()
+
+	// O pedido muda enquanto a leitura está em andamento: ela não vai para o cache
+	<-backend.started
+	cached.HandleEvent(ctx, mq.OrderEvent{Type: "status_changed", OrderID: int(order.ID)})
+	close(backend.release)
+	<-done
+
+	if c.Len() != 0 {
+		t.Errorf("%d pedidos no cache, esperado 0", c.Len())
+	}
+}
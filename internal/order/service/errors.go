@@ -0,0 +1,17 @@
+package service
+
+import "errors"
+
+// Erros de domínio retornados (com wrap) pelo OrderService. Os adaptadores
+// HTTP e gRPC usam errors.Is para escolher o status da resposta.
+var (
+	ErrOrderNotFound           = errors.New("pedido não encontrado")
+	ErrEmptyOrder              = errors.New("pedido deve conter pelo menos um item")
+	ErrInvalidStatusTransition = errors.New("transição de status inválida")
+	ErrOrderNotCancellable     = errors.New("não é possível cancelar pedido com status")
+	ErrInvalidOrder            = errors.New("pedido inválido")
+
+	// ReplayService
+	ErrInvalidReplay  = errors.New("replay inválido")
+	ErrReplayNotFound = errors.New("replay não encontrado")
+)
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"order-service/internal/config"
+	"order-service/internal/order/model"
+	"order-service/internal/order/repository"
+	"order-service/pkg/mq"
+	"order-service/pkg/transaction"
+)
+
+const relayBatchSize = 100
+
+// DomainEventRoutingKey é a routing key de um evento do event store:
+// domain.order.<tipo>, fora do order.# dos eventos publicados pelo
+// OrderService, para não chegar aos webhooks nem aos streams.
+func DomainEventRoutingKey(eventType model.DomainEventType) string {
+	return "domain.order." + string(eventType)
+}
+
+// EventRelay publica os eventos gravados no event store, na ordem em que
+// foram gravados. Como a gravação acontece na transação da alteração,
+// nenhum evento se perde; uma queda entre a publicação e a marcação faz o
+// evento sair de novo, com o mesmo ID (order-<pedido>-v<versão>). As
+// réplicas publicam uma de cada vez: o lote fica bloqueado no banco.
+type EventRelay struct {
+	store        repository.EventStore
+	transactions transaction.Manager
+	publisher    mq.Publisher
+	cfg          *config.EventStoreConfig
+}
+
+func NewEventRelay(store repository.EventStore, transactions transaction.Manager, publisher mq.Publisher, cfg *config.EventStoreConfig) *EventRelay {
+	return &EventRelay{
+		store:        store,
+		transactions: transactions,
+		publisher:    publisher,
+		cfg:          cfg,
+	}
+}
+
+// Run publica os eventos pendentes a cada PublishInterval até o contexto
+// ser cancelado, concluindo o lote em andamento.
+func (r *EventRelay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.PublishInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := r.PublishPending(context.WithoutCancel(ctx)); err != nil {
+			slog.ErrorContext(ctx, "Erro ao publicar eventos do event store", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PublishPending publica os eventos pendentes em lotes, parando na primeira
+// falha para não publicar os eventos seguintes fora de ordem.
+func (r *EventRelay) PublishPending(ctx context.Context) error {
+	for {
+		published, claimed, err := r.publishBatch(ctx)
+		if err != nil || claimed < relayBatchSize || published < claimed {
+			return err
+		}
+	}
+}
+
+func (r *EventRelay) publishBatch(ctx context.Context) (published, claimed int, err error) {
+	var publishErr error
+	err = r.transactions.Do(ctx, func(ctx context.Context) error {
+		published, publishErr = 0, nil
+		events, err := r.store.ClaimUnpublished(ctx, relayBatchSize)
+		if err != nil {
+			return fmt.Errorf("erro ao buscar eventos pendentes: %w", err)
+		}
+		claimed = len(events)
+
+		ids := make([]uint64, 0, len(events))
+		for _, event := range events {
+			if err := r.publisher.PublishTo(ctx, DomainEventRoutingKey(event.Type), brokerEvent(event)); err != nil {
+				publishErr = fmt.Errorf("erro ao publicar evento %d do pedido %d: %w", event.Version, event.OrderID, err)
+				break
+			}
+			ids = append(ids, event.ID)
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		if err := r.store.MarkPublished(ctx, ids, time.Now().UTC()); err != nil {
+			return fmt.Errorf("erro ao marcar eventos publicados: %w", err)
+		}
+		published = len(ids)
+		return nil
+	})
+	if err != nil {
+		return 0, claimed, err
+	}
+
+	domainEventsPublished.WithLabelValues("published").Add(float64(published))
+	if publishErr != nil {
+		domainEventsPublished.WithLabelValues("failed").Inc()
+	}
+	if published > 0 {
+		slog.DebugContext(ctx, "Eventos do event store publicados", "events", published)
+	}
+	return published, claimed, publishErr
+}
+
+// brokerEvent é a mensagem de um evento do event store: o payload gravado
+// e a versão, que os consumidores podem usar para descartar repetições.
+func brokerEvent(event model.DomainEvent) mq.OrderEvent {
+	return mq.OrderEvent{
+		ID:      fmt.Sprintf("order-%d-v%d", event.OrderID, event.Version),
+		Type:    string(event.Type),
+		OrderID: int(event.OrderID),
+		Data: map[string]any{
+			"version": event.Version,
+			"payload": json.RawMessage(event.Data),
+		},
+		OccurredAt: event.CreatedAt,
+	}
+}
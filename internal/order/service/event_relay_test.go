@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"order-service/internal/config"
+	"order-service/internal/order/model"
+	"order-service/internal/order/repository"
+	"order-service/pkg/db/dbtest"
+	"order-service/pkg/mq"
+	"order-service/pkg/mq/mqtest"
+	"order-service/pkg/transaction"
+)
+
+// TestEventRelayPublishesStoredEvents roda o OrderService sobre o event
+// store (SQLite em memória) e publica o que ele gravou.
+func TestEventRelayPublishesStoredEvents(t *testing.T) {
+	ctx := context.Background()
+	database := dbtest.NewSQLite(t)
+
+	store := repository.NewEventSourcedOrderRepository(database, 20)
+	transactions := transaction.NewManager(database, 3)
+	svc := NewOrderService(store, transactions, mqtest.NewPublisher())
+	publisher := mqtest.NewPublisher()
+	relay := NewEventRelay(store, transactions, publisher, &config.EventStoreConfig{PublishInterval: time.Second})
+
+	order := createTestOrder(t, svc)
+	if _, err := svc.AdvanceOrderStatus(ctx, order.ID, model.StatusPaid); err != nil {
+		t.Fatal(err)
+	}
+
+	// Broker fora: nada é marcado como publicado
+	publisher.Err = mq.ErrPublisherClosed
+	if err := relay.PublishPending(ctx); err == nil {
+		t.Fatal("PublishPending deveria falhar com o broker fora")
+	}
+
+	publisher.Err = nil
+	if err := relay.PublishPending(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := relay.PublishPending(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	events, targets := publisher.Events(), publisher.Targets()
+	want := []model.DomainEventType{model.EventOrderCreated, model.EventStatusChanged, model.EventStatusChanged}
+	if len(events) != len(want) {
+		t.Fatalf("%d eventos publicados, esperado %d (uma vez cada)", len(events), len(want))
+	}
+	for i, event := range events {
+		data := event.Data.(map[string]any)
+		if event.Type != string(want[i]) || targets[i].RoutingKey != DomainEventRoutingKey(want[i]) || data["version"] != i+1 {
+			t.Errorf("evento %d = %+v para %s", i, event, targets[i].RoutingKey)
+		}
+	}
+	if events[2].ID != "order-1-v3" {
+		t.Errorf("id = %q, esperado order-1-v3", events[2].ID)
+	}
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"order-service/pkg/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	ordersCreated = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "orders",
+		Name:      "created_total",
+		Help:      "Pedidos criados.",
+	})
+
+	statusTransitions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "orders",
+		Name:      "status_transitions_total",
+		Help:      "Transições de status aplicadas.",
+	}, []string{"from", "to"})
+
+	ordersCancelled = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "orders",
+		Name:      "cancelled_total",
+		Help:      "Pedidos cancelados.",
+	})
+
+	replayedEvents = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "orders",
+		Name:      "replayed_events_total",
+		Help:      "Eventos republicados por replays, por resultado (published ou failed).",
+	}, []string{"result"})
+
+	domainEventsPublished = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "orders",
+		Name:      "domain_events_published_total",
+		Help:      "Eventos do event store publicados pelo relay, por resultado (published ou failed).",
+	}, []string{"result"})
+
+	summaryLag = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "orders",
+		Name:      "summary_lag_seconds",
+		Help:      "Tempo entre a publicação de um evento order.* e sua aplicação ao read model das listagens.",
+		Buckets:   []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60},
+	})
+
+	summaryLastEvent = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "orders",
+		Name:      "summary_last_event_timestamp_seconds",
+		Help:      "Publicação (unix) do último evento aplicado ao read model das listagens.",
+	})
+
+	orderCacheRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "orders",
+		Name:      "cache_requests_total",
+		Help:      "Leituras de pedido por ID no cache, por resultado (hit, miss ou error).",
+	}, []string{"result"})
+
+	orderCacheInvalidations = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "orders",
+		Name:      "cache_invalidations_total",
+		Help:      "Pedidos removidos do cache, por origem (write, alteração desta réplica, ou event).",
+	}, []string{"source"})
+)
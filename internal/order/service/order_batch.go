@@ -0,0 +1,247 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"order-service/internal/order/model"
+	"order-service/pkg/mq"
+	"order-service/pkg/transaction"
+)
+
+// insertBatchSize é o tamanho de cada INSERT gerado pelo CreateInBatches.
+const insertBatchSize = 100
+
+const errBatchRejected = "não processado: lote rejeitado por erro em outro item"
+
+func (s *orderService) CreateOrders(ctx context.Context, req model.BatchCreateOrderRequest) (*model.BatchResponse, error) {
+	mode := batchMode(req.Mode)
+	results := make([]model.BatchItemResult, len(req.Orders))
+
+	var orders []model.Order
+	var indexes []int
+	for i, orderReq := range req.Orders {
+		results[i].Index = i
+		if err := validateCreateOrder(orderReq); err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		orders = append(orders, *newOrder(orderReq))
+		indexes = append(indexes, i)
+	}
+
+	if mode == model.BatchAtomic && len(orders) != len(req.Orders) {
+		return rejectBatch(mode, results), nil
+	}
+
+	if len(orders) > 0 {
+		err := s.orderRepo.CreateBatch(ctx, orders, insertBatchSize)
+		if err != nil && mode == model.BatchAtomic {
+			return nil, fmt.Errorf("erro ao criar pedidos: %w", err)
+		}
+		if err != nil {
+			// Lote falhou inteiro: tentar um a um para isolar os pedidos com erro
+			slog.WarnContext(ctx, "Erro ao criar lote de pedidos, tentando individualmente", "orders", len(orders), "error", err)
+			for i := range orders {
+				// O rollback não limpa os IDs já atribuídos pelo GORM
+				orders[i] = *newOrder(req.Orders[indexes[i]])
+				if err := s.orderRepo.Create(ctx, &orders[i]); err != nil {
+					results[indexes[i]].Error = fmt.Sprintf("erro ao criar pedido: %v", err)
+				}
+			}
+		}
+	}
+
+	var events []mq.OrderEvent
+	for i := range orders {
+		result := &results[indexes[i]]
+		if result.Error != "" {
+			continue
+		}
+		response := orders[i].ToResponse()
+		result.Success = true
+		result.Order = &response
+		events = append(events, orderCreatedEvent(&orders[i]))
+	}
+
+	transaction.AfterCommit(ctx, func() {
+		slog.InfoContext(ctx, "Lote de pedidos criado", "mode", mode, "created", len(events), "total", len(req.Orders))
+		ordersCreated.Add(float64(len(events)))
+
+		if len(events) > 0 {
+			if err := s.publisher.PublishOrderEvents(publishContext(ctx), events); err != nil {
+				slog.ErrorContext(ctx, "Erro ao publicar eventos order.created do lote", "error", err)
+			}
+		}
+	})
+
+	return summarize(mode, results), nil
+}
+
+func (s *orderService) UpdateOrderStatuses(ctx context.Context, req model.BatchUpdateStatusRequest) (*model.BatchResponse, error) {
+	mode := batchMode(req.Mode)
+
+	ids := make([]uint, 0, len(req.Updates))
+	for _, update := range req.Updates {
+		ids = append(ids, update.ID)
+	}
+
+	var results []model.BatchItemResult
+	var current map[uint]*model.Order
+	var previousStatus []model.OrderStatus
+	var events []mq.OrderEvent
+	rejected := false
+	err := s.transactions.Do(ctx, func(ctx context.Context) error {
+		// Refeito a cada tentativa da transação
+		results = make([]model.BatchItemResult, len(req.Updates))
+		events = nil
+		rejected = false
+
+		orders, err := s.orderRepo.GetByIDsForUpdate(ctx, ids)
+		if err != nil {
+			return fmt.Errorf("erro ao buscar pedidos: %w", err)
+		}
+
+		current = make(map[uint]*model.Order, len(orders))
+		for i := range orders {
+			current[orders[i].ID] = &orders[i]
+		}
+
+		// Atualizações do mesmo pedido são aplicadas em sequência, então
+		// "confirmed" seguido de "paid" no mesmo lote é válido.
+		statuses := make(map[uint]model.OrderStatus)
+		previousStatus = make([]model.OrderStatus, len(req.Updates))
+		for i, update := range req.Updates {
+			results[i].Index = i
+
+			order, ok := current[update.ID]
+			if !ok {
+				results[i].Error = fmt.Sprintf("%v: id %d", ErrOrderNotFound, update.ID)
+				continue
+			}
+
+			from := order.Status
+			if status, ok := statuses[update.ID]; ok {
+				from = status
+			}
+			if !s.isValidStatusTransition(from, update.Status) {
+				results[i].Error = fmt.Sprintf("%v: %s -> %s", ErrInvalidStatusTransition, from, update.Status)
+				continue
+			}
+
+			previous := *order
+			previous.Status = from
+			previousStatus[i] = from
+			statuses[update.ID] = update.Status
+			events = append(events, orderStatusChangedEvent(&previous, update.Status))
+		}
+
+		if mode == model.BatchAtomic && len(events) != len(req.Updates) {
+			rejected = true
+			return nil
+		}
+
+		if len(statuses) > 0 {
+			if err := s.orderRepo.UpdateStatuses(ctx, statuses); err != nil {
+				return fmt.Errorf("erro ao atualizar status: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if rejected {
+		return rejectBatch(mode, results), nil
+	}
+
+	for i, update := range req.Updates {
+		if results[i].Error != "" {
+			continue
+		}
+		order := *current[update.ID]
+		order.Status = update.Status
+		response := order.ToResponse()
+		results[i].Success = true
+		results[i].Order = &response
+	}
+
+	transaction.AfterCommit(ctx, func() {
+		slog.InfoContext(ctx, "Lote de status aplicado", "mode", mode, "applied", len(events), "total", len(req.Updates))
+		for i, update := range req.Updates {
+			if results[i].Error != "" {
+				continue
+			}
+			statusTransitions.WithLabelValues(string(previousStatus[i]), string(update.Status)).Inc()
+			if update.Status == model.StatusCancelled {
+				ordersCancelled.Inc()
+			}
+		}
+
+		if len(events) > 0 {
+			if err := s.publisher.PublishOrderEvents(publishContext(ctx), events); err != nil {
+				slog.ErrorContext(ctx, "Erro ao publicar eventos order.status_changed do lote", "error", err)
+			}
+		}
+	})
+
+	return summarize(mode, results), nil
+}
+
+// validateCreateOrder aplica, por item, as mesmas regras do binding de
+// CreateOrderRequest.
+func validateCreateOrder(req model.CreateOrderRequest) error {
+	if req.CustomerID == 0 {
+		return fmt.Errorf("%w: customer_id é obrigatório", ErrInvalidOrder)
+	}
+	if len(req.Items) == 0 {
+		return ErrEmptyOrder
+	}
+
+	for i, item := range req.Items {
+		switch {
+		case item.ProductID == 0:
+			return fmt.Errorf("%w: items[%d].product_id é obrigatório", ErrInvalidOrder, i)
+		case item.Name == "":
+			return fmt.Errorf("%w: items[%d].name é obrigatório", ErrInvalidOrder, i)
+		case item.Price < 0:
+			return fmt.Errorf("%w: items[%d].price não pode ser negativo", ErrInvalidOrder, i)
+		case item.Quantity < 1:
+			return fmt.Errorf("%w: items[%d].quantity deve ser maior que zero", ErrInvalidOrder, i)
+		}
+	}
+	return nil
+}
+
+func batchMode(mode model.BatchMode) model.BatchMode {
+	if mode == "" {
+		return model.BatchAtomic
+	}
+	return mode
+}
+
+func rejectBatch(mode model.BatchMode, results []model.BatchItemResult) *model.BatchResponse {
+	for i := range results {
+		if results[i].Error == "" {
+			results[i].Error = errBatchRejected
+		}
+	}
+	return summarize(mode, results)
+}
+
+func summarize(mode model.BatchMode, results []model.BatchItemResult) *model.BatchResponse {
+	response := &model.BatchResponse{
+		Mode:    mode,
+		Total:   len(results),
+		Results: results,
+	}
+	for _, result := range results {
+		if result.Success {
+			response.Succeeded++
+		} else {
+			response.Failed++
+		}
+	}
+	return response
+}
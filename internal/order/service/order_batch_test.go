@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"order-service/internal/order/model"
+	"order-service/internal/order/repository"
+	"order-service/pkg/db/dbtest"
+	"order-service/pkg/mq/mqtest"
+	"order-service/pkg/transaction"
+)
+
+func batchOrder(customerID uint) model.CreateOrderRequest {
+	return model.CreateOrderRequest{
+		CustomerID: customerID,
+		Items:      []model.CreateOrderItemRequest{{ProductID: 10, Name: "Teclado", Price: 150, Quantity: 1}},
+	}
+}
+
+func TestCreateOrdersValidation(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, publisher := newTestService()
+	req := model.BatchCreateOrderRequest{Orders: []model.CreateOrderRequest{
+		batchOrder(1),
+		{CustomerID: 2},
+		batchOrder(3),
+	}}
+
+	// Atômico: um item inválido rejeita o lote sem gravar nada
+	resp, err := svc.CreateOrders(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Succeeded != 0 || resp.Failed != 3 || resp.Results[0].Error != errBatchRejected || resp.Results[1].Error == "" {
+		t.Fatalf("lote atômico = %+v", resp)
+	}
+	if n, _ := repo.Count(ctx); n != 0 || publisher.Calls() != 0 {
+		t.Fatalf("%d pedidos e %d publicações, esperado nenhum", n, publisher.Calls())
+	}
+
+	// Parcial: os válidos são criados e publicados em uma única chamada
+	req.Mode = model.BatchPartial
+	resp, err = svc.CreateOrders(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Succeeded != 2 || resp.Failed != 1 || resp.Results[1].Success || resp.Results[2].Order == nil {
+		t.Fatalf("lote parcial = %+v", resp)
+	}
+	if n, _ := repo.Count(ctx); n != 2 {
+		t.Errorf("%d pedidos gravados, esperado 2", n)
+	}
+	if publisher.Calls() != 1 || len(publisher.EventsOfType("created")) != 2 {
+		t.Errorf("%d publicações com %d eventos, esperado 1 com 2", publisher.Calls(), len(publisher.Events()))
+	}
+}
+
+// TestCreateOrdersRollsBackOnStorageFailure: um pedido que o banco recusa
+// desfaz o INSERT do lote inteiro no modo atômico; no parcial, só ele falha.
+func TestCreateOrdersRollsBackOnStorageFailure(t *testing.T) {
+	ctx := context.Background()
+	database := dbtest.NewSQLite(t)
+	err := database.Exec(`CREATE TRIGGER reject_customer BEFORE INSERT ON orders
+		WHEN NEW.customer_id = 666 BEGIN SELECT RAISE(ABORT, 'cliente bloqueado'); END`).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := repository.NewOrderRepository(database)
+	publisher := mqtest.NewPublisher()
+	svc := NewOrderService(repo, transaction.NewManager(database, 3), publisher)
+	req := model.BatchCreateOrderRequest{Orders: []model.CreateOrderRequest{batchOrder(1), batchOrder(666), batchOrder(2)}}
+
+	if _, err := svc.CreateOrders(ctx, req); err == nil {
+		t.Fatal("lote atômico com pedido recusado deveria falhar")
+	}
+	if n, _ := repo.Count(ctx); n != 0 || publisher.Calls() != 0 {
+		t.Fatalf("%d pedidos e %d publicações após o rollback, esperado nenhum", n, publisher.Calls())
+	}
+
+	req.Mode = model.BatchPartial
+	resp, err := svc.CreateOrders(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Succeeded != 2 || resp.Results[1].Success || resp.Results[1].Error == "" {
+		t.Fatalf("lote parcial = %+v", resp)
+	}
+	if n, _ := repo.Count(ctx); n != 2 {
+		t.Errorf("%d pedidos gravados, esperado 2", n)
+	}
+	created := publisher.EventsOfType("created")
+	if publisher.Calls() != 1 || len(created) != 2 || created[0].OrderID != int(resp.Results[0].Order.ID) {
+		t.Errorf("%d publicações com eventos %v, esperado 1 com os 2 pedidos criados", publisher.Calls(), created)
+	}
+}
@@ -1,40 +1,82 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"order-service/internal/order/model"
 	"order-service/internal/order/repository"
 	"order-service/pkg/mq"
+	"order-service/pkg/transaction"
 	"slices"
 	"time"
+
+	"gorm.io/gorm"
 )
 
+// Os métodos recebem o contexto da requisição: cancelá-lo (cliente
+// desconectado ou prazo da rota) interrompe as queries em andamento.
 type OrderService interface {
-	CreateOrder(req model.CreateOrderRequest) (*model.OrderResponse, error)
-	GetOrderByID(id uint) (*model.OrderResponse, error)
-	GetOrdersByCustomer(customerID uint, limit, offset int) ([]model.OrderResponse, error)
-	UpdateOrderStatus(id uint, status model.OrderStatus) (*model.OrderResponse, error)
-	CancelOrder(id uint) error
+	CreateOrder(ctx context.Context, req model.CreateOrderRequest) (*model.OrderResponse, error)
+	GetOrderByID(ctx context.Context, id uint) (*model.OrderResponse, error)
+	GetOrdersByCustomer(ctx context.Context, customerID uint, limit, offset int) ([]model.OrderResponse, error)
+	UpdateOrderStatus(ctx context.Context, id uint, status model.OrderStatus) (*model.OrderResponse, error)
+	// AdvanceOrderStatus leva o pedido até status pelo fluxo normal (pending,
+	// confirmed, paid, shipped, delivered), aplicando as transições
+	// intermediárias que faltarem. Um status já alcançado ou ultrapassado não
+	// altera nada, o que torna a chamada idempotente.
+	AdvanceOrderStatus(ctx context.Context, id uint, status model.OrderStatus) (*model.OrderResponse, error)
+	CancelOrder(ctx context.Context, id uint) error
+
+	// Operações em lote; ver model.BatchMode para a semântica de cada modo
+	CreateOrders(ctx context.Context, req model.BatchCreateOrderRequest) (*model.BatchResponse, error)
+	UpdateOrderStatuses(ctx context.Context, req model.BatchUpdateStatusRequest) (*model.BatchResponse, error)
 }
 
 type orderService struct {
-	orderRepo repository.OrderRepository
-	publisher mq.Publisher
+	orderRepo    repository.OrderRepository
+	transactions transaction.Manager
+	publisher    mq.Publisher
 }
 
-func NewOrderService(orderRepo repository.OrderRepository, publisher mq.Publisher) OrderService {
+// NewOrderService cria o serviço. Leituras e alterações que dependem do
+// estado lido (transições de status) rodam em transações de transactions;
+// os eventos são publicados só depois do commit, incluindo o de uma
+// transação de quem chama (transaction.AfterCommit).
+func NewOrderService(orderRepo repository.OrderRepository, transactions transaction.Manager, publisher mq.Publisher) OrderService {
 	return &orderService{
-		orderRepo: orderRepo,
-		publisher: publisher,
+		orderRepo:    orderRepo,
+		transactions: transactions,
+		publisher:    publisher,
 	}
 }
 
-func (s *orderService) CreateOrder(req model.CreateOrderRequest) (*model.OrderResponse, error) {
+func (s *orderService) CreateOrder(ctx context.Context, req model.CreateOrderRequest) (*model.OrderResponse, error) {
 	if len(req.Items) == 0 {
-		return nil, fmt.Errorf("pedido deve conter pelo menos um item")
+		return nil, ErrEmptyOrder
 	}
 
+	order := newOrder(req)
+	if err := s.orderRepo.Create(ctx, order); err != nil {
+		return nil, fmt.Errorf("erro ao criar pedido: %w", err)
+	}
+
+	transaction.AfterCommit(ctx, func() {
+		slog.InfoContext(ctx, "Pedido criado",
+			"order_id", order.ID, "customer_id", order.CustomerID, "total_amount", order.TotalAmount)
+		ordersCreated.Inc()
+
+		if err := s.publishOrderCreatedEvent(publishContext(ctx), order); err != nil {
+			slog.ErrorContext(ctx, "Erro ao publicar evento order.created", "order_id", order.ID, "error", err)
+		}
+	})
+
+	response := order.ToResponse()
+	return &response, nil
+}
+
+func newOrder(req model.CreateOrderRequest) *model.Order {
 	order := &model.Order{
 		CustomerID: req.CustomerID,
 		Status:     model.StatusPending,
@@ -49,34 +91,21 @@ func (s *orderService) CreateOrder(req model.CreateOrderRequest) (*model.OrderRe
 			Quantity:  item.Quantity,
 		}
 	}
-
-	if err := s.orderRepo.Create(order); err != nil {
-		return nil, fmt.Errorf("erro ao criar pedido: %w", err)
-	}
-
-	log.Printf("Pedido criado: ID=%d, Customer=%d, Total=%.2f",
-		order.ID, order.CustomerID, order.TotalAmount)
-
-	if err := s.publishOrderCreatedEvent(order); err != nil {
-		log.Printf("Erro ao publicar evento order.created: %v", err)
-	}
-
-	response := order.ToResponse()
-	return &response, nil
+	return order
 }
 
-func (s *orderService) GetOrderByID(id uint) (*model.OrderResponse, error) {
-	order, err := s.orderRepo.GetByID(id)
+func (s *orderService) GetOrderByID(ctx context.Context, id uint) (*model.OrderResponse, error) {
+	order, err := s.orderRepo.GetByID(ctx, id)
 	if err != nil {
-		return nil, fmt.Errorf("pedido não encontrado: %w", err)
+		return nil, notFound(err)
 	}
 
 	response := order.ToResponse()
 	return &response, nil
 }
 
-func (s *orderService) GetOrdersByCustomer(customerID uint, limit, offset int) ([]model.OrderResponse, error) {
-	orders, err := s.orderRepo.GetByCustomerID(customerID, limit, offset)
+func (s *orderService) GetOrdersByCustomer(ctx context.Context, customerID uint, limit, offset int) ([]model.OrderResponse, error) {
+	orders, err := s.orderRepo.GetByCustomerID(ctx, customerID, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("erro ao buscar pedidos do cliente: %w", err)
 	}
@@ -89,61 +118,194 @@ func (s *orderService) GetOrdersByCustomer(customerID uint, limit, offset int) (
 	return responses, nil
 }
 
-func (s *orderService) UpdateOrderStatus(id uint, status model.OrderStatus) (*model.OrderResponse, error) {
-	order, err := s.orderRepo.GetByID(id)
+func (s *orderService) UpdateOrderStatus(ctx context.Context, id uint, status model.OrderStatus) (*model.OrderResponse, error) {
+	var order *model.Order
+	err := s.transactions.Do(ctx, func(ctx context.Context) error {
+		var err error
+		order, err = s.orderRepo.GetByIDForUpdate(ctx, id)
+		if err != nil {
+			return notFound(err)
+		}
+
+		if !s.isValidStatusTransition(order.Status, status) {
+			return fmt.Errorf("%w: %s -> %s", ErrInvalidStatusTransition, order.Status, status)
+		}
+
+		if err := s.orderRepo.UpdateStatus(ctx, id, status); err != nil {
+			return fmt.Errorf("erro ao atualizar status: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("pedido não encontrado: %w", err)
+		return nil, err
 	}
 
-	if !s.isValidStatusTransition(order.Status, status) {
-		return nil, fmt.Errorf("transição de status inválida: %s -> %s", order.Status, status)
-	}
+	transaction.AfterCommit(ctx, func() {
+		slog.InfoContext(ctx, "Status do pedido alterado",
+			"order_id", id, "customer_id", order.CustomerID, "from", order.Status, "to", status)
+		statusTransitions.WithLabelValues(string(order.Status), string(status)).Inc()
 
-	if err := s.orderRepo.UpdateStatus(id, status); err != nil {
-		return nil, fmt.Errorf("erro ao atualizar status: %w", err)
+		if err := s.publishOrderStatusChangedEvent(publishContext(ctx), order, status); err != nil {
+			slog.ErrorContext(ctx, "Erro ao publicar evento order.status_changed", "order_id", id, "error", err)
+		}
+	})
+
+	return s.GetOrderByID(ctx, id)
+}
+
+func (s *orderService) AdvanceOrderStatus(ctx context.Context, id uint, status model.OrderStatus) (*model.OrderResponse, error) {
+	var order *model.Order
+	var path []model.OrderStatus
+	err := s.transactions.Do(ctx, func(ctx context.Context) error {
+		var err error
+		order, err = s.orderRepo.GetByIDForUpdate(ctx, id)
+		if err != nil {
+			return notFound(err)
+		}
+
+		path, err = s.statusPath(order.Status, status)
+		if err != nil {
+			return err
+		}
+		for _, step := range path {
+			if err := s.orderRepo.UpdateStatus(ctx, id, step); err != nil {
+				return fmt.Errorf("erro ao atualizar status: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	log.Printf("Status do pedido %d alterado: %s -> %s", id, order.Status, status)
+	if len(path) == 0 {
+		slog.DebugContext(ctx, "Status do pedido já alcançado", "order_id", id, "status", order.Status, "requested", status)
+	}
 
-	if err := s.publishOrderStatusChangedEvent(id, order.Status, status); err != nil {
-		log.Printf("Erro ao publicar evento order.status_changed: %v", err)
+	// Um evento status_changed por transição, como se tivessem sido feitas
+	// uma a uma
+	events := make([]mq.OrderEvent, len(path))
+	from := order.Status
+	for i, step := range path {
+		events[i] = orderStatusChangedEvent(order, step)
+		order.Status = step
+	}
+	if len(events) > 0 {
+		transaction.AfterCommit(ctx, func() {
+			for _, step := range path {
+				slog.InfoContext(ctx, "Status do pedido alterado",
+					"order_id", id, "customer_id", order.CustomerID, "from", from, "to", step)
+				statusTransitions.WithLabelValues(string(from), string(step)).Inc()
+				from = step
+			}
+
+			if err := s.publisher.PublishOrderEvents(publishContext(ctx), events); err != nil {
+				slog.ErrorContext(ctx, "Erro ao publicar eventos order.status_changed", "order_id", id, "error", err)
+			}
+		})
 	}
 
-	return s.GetOrderByID(id)
+	return s.GetOrderByID(ctx, id)
 }
 
-func (s *orderService) CancelOrder(id uint) error {
-	order, err := s.orderRepo.GetByID(id)
-	if err != nil {
-		return fmt.Errorf("pedido não encontrado: %w", err)
+// statusPath devolve as transições que levam de from a to. to já alcançado
+// (ou ultrapassado, no fluxo normal) resulta em caminho vazio; pedidos
+// cancelados ou com falha não saem do status atual.
+func (s *orderService) statusPath(from, to model.OrderStatus) ([]model.OrderStatus, error) {
+	if from == to {
+		return nil, nil
 	}
 
-	if order.Status != model.StatusPending && order.Status != model.StatusConfirmed {
-		return fmt.Errorf("não é possível cancelar pedido com status: %s", order.Status)
+	flow := []model.OrderStatus{
+		model.StatusPending,
+		model.StatusConfirmed,
+		model.StatusPaid,
+		model.StatusShipped,
+		model.StatusDelivered,
 	}
+	current := slices.Index(flow, from)
 
-	if err := s.orderRepo.UpdateStatus(id, model.StatusCancelled); err != nil {
-		return fmt.Errorf("erro ao cancelar pedido: %w", err)
+	// Falha só se aplica antes do pagamento; depois dele, é um evento antigo
+	if to == model.StatusFailed && current > slices.Index(flow, model.StatusConfirmed) {
+		return nil, nil
+	}
+	if s.isValidStatusTransition(from, to) {
+		return []model.OrderStatus{to}, nil
 	}
 
-	log.Printf("Pedido %d cancelado", id)
+	target := slices.Index(flow, to)
+	switch {
+	case current < 0 || target < 0:
+		return nil, fmt.Errorf("%w: %s -> %s", ErrInvalidStatusTransition, from, to)
+	case target < current:
+		return nil, nil
+	}
+	return flow[current+1 : target+1], nil
+}
+
+func (s *orderService) CancelOrder(ctx context.Context, id uint) error {
+	var order *model.Order
+	err := s.transactions.Do(ctx, func(ctx context.Context) error {
+		var err error
+		order, err = s.orderRepo.GetByIDForUpdate(ctx, id)
+		if err != nil {
+			return notFound(err)
+		}
 
-	if err := s.publishOrderCancelledEvent(id); err != nil {
-		log.Printf("Erro ao publicar evento order.cancelled: %v", err)
+		if order.Status != model.StatusPending && order.Status != model.StatusConfirmed {
+			return fmt.Errorf("%w: %s", ErrOrderNotCancellable, order.Status)
+		}
+
+		if err := s.orderRepo.UpdateStatus(ctx, id, model.StatusCancelled); err != nil {
+			return fmt.Errorf("erro ao cancelar pedido: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
+	transaction.AfterCommit(ctx, func() {
+		slog.InfoContext(ctx, "Pedido cancelado", "order_id", id, "customer_id", order.CustomerID)
+		statusTransitions.WithLabelValues(string(order.Status), string(model.StatusCancelled)).Inc()
+		ordersCancelled.Inc()
+
+		if err := s.publishOrderCancelledEvent(publishContext(ctx), order); err != nil {
+			slog.ErrorContext(ctx, "Erro ao publicar evento order.cancelled", "order_id", id, "error", err)
+		}
+	})
+
 	return nil
 }
 
+// notFound marca como ErrOrderNotFound só a ausência do pedido. Falhas do
+// banco (conexão, lock, conflito de serialização) seguem como estão, para
+// quem chama poder repetir a operação.
+func notFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("%w: %w", ErrOrderNotFound, err)
+	}
+	return err
+}
+
+// publishContext mantém trace e request ID, mas não o cancelamento: depois do
+// commit, um cliente desconectado não pode impedir a publicação do evento. O
+// publisher aplica o próprio prazo.
+func publishContext(ctx context.Context) context.Context {
+	return context.WithoutCancel(ctx)
+}
+
 func (s *orderService) isValidStatusTransition(from, to model.OrderStatus) bool {
 	validTransitions := map[model.OrderStatus][]model.OrderStatus{
 		model.StatusPending: {
 			model.StatusConfirmed,
 			model.StatusCancelled,
+			model.StatusFailed,
 		},
 		model.StatusConfirmed: {
 			model.StatusPaid,
 			model.StatusCancelled,
+			model.StatusFailed,
 		},
 		model.StatusPaid: {
 			model.StatusShipped,
@@ -164,35 +326,51 @@ func (s *orderService) isValidStatusTransition(from, to model.OrderStatus) bool
 	return slices.Contains(allowedTransitions, to)
 }
 
-func (s *orderService) publishOrderCreatedEvent(order *model.Order) error {
+func (s *orderService) publishOrderCreatedEvent(ctx context.Context, order *model.Order) error {
+	event := orderCreatedEvent(order)
+	return s.publisher.PublishOrderEvent(ctx, event.Type, order.ID, event.Data)
+}
+
+func (s *orderService) publishOrderStatusChangedEvent(ctx context.Context, order *model.Order, newStatus model.OrderStatus) error {
+	event := orderStatusChangedEvent(order, newStatus)
+	return s.publisher.PublishOrderEvent(ctx, event.Type, order.ID, event.Data)
+}
+
+func (s *orderService) publishOrderCancelledEvent(ctx context.Context, order *model.Order) error {
 	eventData := map[string]any{
 		"order_id":     order.ID,
 		"customer_id":  order.CustomerID,
-		"status":       order.Status,
-		"total_amount": order.TotalAmount,
-		"items":        order.Items,
-		"created_at":   order.CreatedAt,
+		"cancelled_at": time.Now(),
 	}
 
-	return s.publisher.PublishOrderEvent("created", order.ID, eventData)
+	return s.publisher.PublishOrderEvent(ctx, "cancelled", order.ID, eventData)
 }
 
-func (s *orderService) publishOrderStatusChangedEvent(orderID uint, oldStatus, newStatus model.OrderStatus) error {
-	eventData := map[string]any{
-		"order_id":   orderID,
-		"old_status": oldStatus,
-		"new_status": newStatus,
-		"changed_at": time.Now(),
+func orderCreatedEvent(order *model.Order) mq.OrderEvent {
+	return mq.OrderEvent{
+		Type:    "created",
+		OrderID: int(order.ID),
+		Data: map[string]any{
+			"order_id":     order.ID,
+			"customer_id":  order.CustomerID,
+			"status":       order.Status,
+			"total_amount": order.TotalAmount,
+			"items":        order.Items,
+			"created_at":   order.CreatedAt,
+		},
 	}
-
-	return s.publisher.PublishOrderEvent("status_changed", orderID, eventData)
 }
 
-func (s *orderService) publishOrderCancelledEvent(orderID uint) error {
-	eventData := map[string]any{
-		"order_id":     orderID,
-		"cancelled_at": time.Now(),
+func orderStatusChangedEvent(order *model.Order, newStatus model.OrderStatus) mq.OrderEvent {
+	return mq.OrderEvent{
+		Type:    "status_changed",
+		OrderID: int(order.ID),
+		Data: map[string]any{
+			"order_id":    order.ID,
+			"customer_id": order.CustomerID,
+			"old_status":  order.Status,
+			"new_status":  newStatus,
+			"changed_at":  time.Now(),
+		},
 	}
-
-	return s.publisher.PublishOrderEvent("cancelled", orderID, eventData)
 }
@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"order-service/internal/order/model"
+	"order-service/internal/order/repository"
+	"order-service/pkg/mq/mqtest"
+	"order-service/pkg/transaction"
+)
+
+func newTestService() (OrderService, *repository.MemoryOrderRepository, *mqtest.Publisher) {
+	repo := repository.NewMemoryOrderRepository()
+	publisher := mqtest.NewPublisher()
+	return NewOrderService(repo, transaction.NewMemoryManager(3, repo), publisher), repo, publisher
+}
+
+func createTestOrder(t *testing.T, svc OrderService) *model.OrderResponse {
+	t.Helper()
+	order, err := svc.CreateOrder(context.Background(), model.CreateOrderRequest{
+		CustomerID: 1,
+		Items:      []model.CreateOrderItemRequest{{ProductID: 10, Name: "Teclado", Price: 150, Quantity: 2}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return order
+}
+
+func TestOrderLifecyclePublishesEvents(t *testing.T) {
+	ctx := context.Background()
+	svc, _, publisher := newTestService()
+
+	order := createTestOrder(t, svc)
+	if order.TotalAmount != 300 || order.Status != model.StatusPending {
+		t.Fatalf("pedido = %s, total %.2f; esperado pending, 300.00", order.Status, order.TotalAmount)
+	}
+
+	updated, err := svc.UpdateOrderStatus(ctx, order.ID, model.StatusConfirmed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Status != model.StatusConfirmed {
+		t.Fatalf("status = %s, esperado confirmed", updated.Status)
+	}
+	if err := svc.CancelOrder(ctx, order.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	var types []string
+	for _, event := range publisher.Events() {
+		types = append(types, event.Type)
+		if event.OrderID != int(order.ID) {
+			t.Errorf("evento %s do pedido %d, esperado %d", event.Type, event.OrderID, order.ID)
+		}
+	}
+	if len(types) != 3 || types[0] != "created" || types[1] != "status_changed" || types[2] != "cancelled" {
+		t.Fatalf("eventos = %v, esperado [created status_changed cancelled]", types)
+	}
+}
+
+func TestInvalidTransitionChangesNothing(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, publisher := newTestService()
+	order := createTestOrder(t, svc)
+	publisher.Reset()
+
+	if _, err := svc.UpdateOrderStatus(ctx, order.ID, model.StatusShipped); !errors.Is(err, ErrInvalidStatusTransition) {
+		t.Fatalf("err = %v, esperado ErrInvalidStatusTransition", err)
+	}
+	if _, err := svc.UpdateOrderStatus(ctx, 999, model.StatusConfirmed); !errors.Is(err, ErrOrderNotFound) {
+		t.Fatalf("err = %v, esperado ErrOrderNotFound", err)
+	}
+
+	stored, err := repo.GetByID(ctx, order.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Status != model.StatusPending || len(publisher.Events()) != 0 {
+		t.Fatalf("status = %s com %d eventos, esperado pending sem eventos", stored.Status, len(publisher.Events()))
+	}
+}
+
+func TestAtomicBatchStatusRollsBack(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, publisher := newTestService()
+	first, second := createTestOrder(t, svc), createTestOrder(t, svc)
+	publisher.Reset()
+
+	resp, err := svc.UpdateOrderStatuses(ctx, model.BatchUpdateStatusRequest{
+		Updates: []model.StatusUpdateEntry{
+			{ID: first.ID, Status: model.StatusConfirmed},
+			{ID: second.ID, Status: model.StatusDelivered},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Succeeded != 0 || resp.Failed != 2 {
+		t.Fatalf("lote atômico = %d ok / %d falhas, esperado 0 / 2", resp.Succeeded, resp.Failed)
+	}
+
+	stored, err := repo.GetByID(ctx, first.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Status != model.StatusPending || len(publisher.Events()) != 0 {
+		t.Fatalf("status = %s com %d eventos, esperado pending sem eventos", stored.Status, len(publisher.Events()))
+	}
+
+	resp, err = svc.UpdateOrderStatuses(ctx, model.BatchUpdateStatusRequest{
+		Mode: model.BatchPartial,
+		Updates: []model.StatusUpdateEntry{
+			{ID: first.ID, Status: model.StatusConfirmed},
+			{ID: first.ID, Status: model.StatusPaid},
+			{ID: second.ID, Status: model.StatusDelivered},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Succeeded != 2 || len(publisher.EventsOfType("status_changed")) != 2 {
+		t.Fatalf("lote parcial = %d ok com %d eventos, esperado 2 e 2", resp.Succeeded, len(publisher.Events()))
+	}
+}
+
+// TestBatchPublishesAfterCommit: dentro de uma transação de quem chama, os
+// eventos do lote saem só no commit, e não saem se ela for desfeita.
+func TestBatchPublishesAfterCommit(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryOrderRepository()
+	publisher := mqtest.NewPublisher()
+	transactions := transaction.NewMemoryManager(3, repo)
+	svc := NewOrderService(repo, transactions, publisher)
+	order := createTestOrder(t, svc)
+	publisher.Reset()
+
+	create := model.BatchCreateOrderRequest{Orders: []model.CreateOrderRequest{{
+		CustomerID: 2,
+		Items:      []model.CreateOrderItemRequest{{ProductID: 10, Name: "Teclado", Price: 150, Quantity: 1}},
+	}}}
+	update := model.BatchUpdateStatusRequest{Updates: []model.StatusUpdateEntry{{ID: order.ID, Status: model.StatusConfirmed}}}
+	errAbort := errors.New("operação desfeita")
+
+	err := transactions.Do(ctx, func(ctx context.Context) error {
+		if _, err := svc.CreateOrders(ctx, create); err != nil {
+			return err
+		}
+		if _, err := svc.UpdateOrderStatuses(ctx, update); err != nil {
+			return err
+		}
+		if n := len(publisher.Events()); n != 0 {
+			t.Errorf("%d eventos publicados antes do commit", n)
+		}
+		return errAbort
+	})
+	if !errors.Is(err, errAbort) {
+		t.Fatalf("err = %v, esperado %v", err, errAbort)
+	}
+	if n := len(publisher.Events()); n != 0 {
+		t.Fatalf("%d eventos publicados por uma transação desfeita", n)
+	}
+
+	err = transactions.Do(ctx, func(ctx context.Context) error {
+		if _, err := svc.CreateOrders(ctx, create); err != nil {
+			return err
+		}
+		_, err := svc.UpdateOrderStatuses(ctx, update)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(publisher.EventsOfType("created")) != 1 || len(publisher.EventsOfType("status_changed")) != 1 {
+		t.Errorf("eventos após o commit = %v, esperado created e status_changed", publisher.Events())
+	}
+}
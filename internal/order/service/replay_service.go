@@ -0,0 +1,275 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"order-service/internal/order/model"
+	"order-service/internal/order/repository"
+	"order-service/pkg/mq"
+
+	"golang.org/x/time/rate"
+)
+
+// replayPageSize é quantos pedidos cada consulta do replay carrega.
+const replayPageSize = 100
+
+// maxReplayHistory é quantos replays encerrados continuam em GetReplay.
+const maxReplayHistory = 100
+
+// ReplayService republica eventos de pedidos para consumers que perderam
+// dados ou começaram a consumir depois. Não há outbox nem histórico de
+// eventos no banco: cada pedido selecionado vira um evento created com o
+// estado atual (snapshot), marcado com o header replay.
+type ReplayService interface {
+	// Replay republica e só retorna ao terminar ou quando ctx é cancelado.
+	Replay(ctx context.Context, req model.ReplayRequest) (*model.ReplayResponse, error)
+	// StartReplay valida o pedido e republica em segundo plano; o andamento
+	// fica disponível em GetReplay.
+	StartReplay(ctx context.Context, req model.ReplayRequest) (*model.ReplayResponse, error)
+	GetReplay(id string) (*model.ReplayResponse, error)
+	// CancelReplay interrompe um replay em andamento.
+	CancelReplay(id string) (*model.ReplayResponse, error)
+	// Shutdown interrompe os replays em segundo plano e espera terminarem.
+	Shutdown(ctx context.Context) error
+}
+
+type replayService struct {
+	orderRepo repository.OrderRepository
+	publisher mq.Publisher
+	maxRate   int
+
+	mu sync.Mutex
+	// Replays por ID; finished guarda a ordem de término para descartar os
+	// mais antigos
+	jobs     map[string]*replayJob
+	finished []string
+	wg       sync.WaitGroup
+}
+
+type replayJob struct {
+	model.ReplayResponse
+	cancel context.CancelFunc
+}
+
+// NewReplayService cria o serviço; maxRate (eventos por segundo) é o padrão
+// e o limite de cada replay.
+func NewReplayService(orderRepo repository.OrderRepository, publisher mq.Publisher, maxRate int) ReplayService {
+	return &replayService{
+		orderRepo: orderRepo,
+		publisher: publisher,
+		maxRate:   maxRate,
+		jobs:      make(map[string]*replayJob),
+	}
+}
+
+func (s *replayService) Replay(ctx context.Context, req model.ReplayRequest) (*model.ReplayResponse, error) {
+	ctx, job, err := s.newJob(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer job.cancel()
+
+	s.run(ctx, job)
+	return s.GetReplay(job.ID)
+}
+
+func (s *replayService) StartReplay(ctx context.Context, req model.ReplayRequest) (*model.ReplayResponse, error) {
+	// Mantém trace e request ID, mas não o prazo da requisição
+	ctx, job, err := s.newJob(context.WithoutCancel(ctx), req)
+	if err != nil {
+		return nil, err
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer job.cancel()
+		s.run(ctx, job)
+	}()
+	return s.GetReplay(job.ID)
+}
+
+// newJob valida o pedido e registra o replay, cancelável pelo contexto
+// devolvido.
+func (s *replayService) newJob(ctx context.Context, req model.ReplayRequest) (context.Context, *replayJob, error) {
+	if err := s.validate(&req); err != nil {
+		return nil, nil, err
+	}
+
+	job := &replayJob{ReplayResponse: model.ReplayResponse{
+		ID:        newReplayID(),
+		Status:    model.ReplayRunning,
+		Request:   req,
+		StartedAt: time.Now().UTC(),
+	}}
+	ctx, job.cancel = context.WithCancel(ctx)
+
+	s.mu.Lock()
+	s.jobs[job.ID] = job
+	s.mu.Unlock()
+	return ctx, job, nil
+}
+
+// validate confere os filtros e preenche Rate com o padrão.
+func (s *replayService) validate(req *model.ReplayRequest) error {
+	switch {
+	case req.From == nil && req.To == nil && len(req.OrderIDs) == 0 && req.Status == "":
+		return fmt.Errorf("%w: informe ao menos um filtro (from/to, order_ids ou status)", ErrInvalidReplay)
+	case req.From != nil && req.To != nil && !req.From.Before(*req.To):
+		return fmt.Errorf("%w: from deve ser anterior a to", ErrInvalidReplay)
+	case req.Status != "" && !req.Status.Valid():
+		return fmt.Errorf("%w: status desconhecido %q", ErrInvalidReplay, req.Status)
+	case req.Rate < 0 || req.Rate > s.maxRate:
+		return fmt.Errorf("%w: rate deve estar entre 1 e %d eventos/s", ErrInvalidReplay, s.maxRate)
+	}
+	if req.Rate == 0 {
+		req.Rate = s.maxRate
+	}
+	return nil
+}
+
+// run percorre os pedidos do filtro em páginas, publicando no ritmo de
+// Rate. Falhas de publicação são contadas e não interrompem o replay.
+func (s *replayService) run(ctx context.Context, job *replayJob) {
+	req := job.Request
+	target := mq.ReplayTarget{ID: job.ID, RoutingKey: req.RoutingKey, Queue: req.Queue}
+	limiter := rate.NewLimiter(rate.Limit(req.Rate), 1)
+
+	slog.InfoContext(ctx, "Replay iniciado",
+		"replay_id", job.ID, "routing_key", req.RoutingKey, "queue", req.Queue, "rate", req.Rate)
+
+	var afterID uint
+	err := func() error {
+		for {
+			orders, err := s.orderRepo.ListForReplay(ctx, req.Filter(), afterID, replayPageSize)
+			if err != nil {
+				return fmt.Errorf("erro ao buscar pedidos: %w", err)
+			}
+			if len(orders) == 0 {
+				return nil
+			}
+
+			for i := range orders {
+				if err := limiter.Wait(ctx); err != nil {
+					return err
+				}
+				err := s.publisher.ReplayOrderEvents(ctx, []mq.OrderEvent{orderSnapshotEvent(&orders[i])}, target)
+				if err != nil {
+					slog.WarnContext(ctx, "Erro ao republicar evento", "replay_id", job.ID, "order_id", orders[i].ID, "error", err)
+					replayedEvents.WithLabelValues("failed").Inc()
+				} else {
+					replayedEvents.WithLabelValues("published").Inc()
+				}
+				s.update(job, func(r *model.ReplayResponse) {
+					if err != nil {
+						r.Failed++
+					} else {
+						r.Published++
+					}
+				})
+			}
+			afterID = orders[len(orders)-1].ID
+		}
+	}()
+
+	s.update(job, func(r *model.ReplayResponse) {
+		finishedAt := time.Now().UTC()
+		r.FinishedAt = &finishedAt
+		switch {
+		case errors.Is(err, context.Canceled):
+			r.Status = model.ReplayCancelled
+		case err != nil:
+			r.Status = model.ReplayFailed
+			r.Error = err.Error()
+		default:
+			r.Status = model.ReplayCompleted
+		}
+	})
+	s.retire(job.ID)
+
+	final, _ := s.GetReplay(job.ID)
+	slog.InfoContext(ctx, "Replay encerrado",
+		"replay_id", job.ID, "status", final.Status, "published", final.Published, "failed", final.Failed, "error", err)
+}
+
+func (s *replayService) update(job *replayJob, fn func(*model.ReplayResponse)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn(&job.ReplayResponse)
+}
+
+// retire registra o término do replay e descarta os encerrados mais antigos.
+func (s *replayService) retire(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.finished = append(s.finished, id)
+	for len(s.finished) > maxReplayHistory {
+		delete(s.jobs, s.finished[0])
+		s.finished = s.finished[1:]
+	}
+}
+
+func (s *replayService) GetReplay(id string) (*model.ReplayResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrReplayNotFound, id)
+	}
+	response := job.ReplayResponse
+	return &response, nil
+}
+
+func (s *replayService) CancelReplay(id string) (*model.ReplayResponse, error) {
+	s.mu.Lock()
+	job, ok := s.jobs[id]
+	s.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrReplayNotFound, id)
+	}
+
+	job.cancel()
+	return s.GetReplay(id)
+}
+
+func (s *replayService) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	for _, job := range s.jobs {
+		job.cancel()
+	}
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// orderSnapshotEvent é o evento created com o estado atual do pedido.
+func orderSnapshotEvent(order *model.Order) mq.OrderEvent {
+	event := orderCreatedEvent(order)
+	event.Data.(map[string]any)["updated_at"] = order.UpdatedAt
+	return event
+}
+
+func newReplayID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return "rpl_" + hex.EncodeToString(b)
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"order-service/internal/order/model"
+	"order-service/pkg/mq"
+)
+
+func TestReplayPublishesSnapshots(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, publisher := newTestService()
+	replays := NewReplayService(repo, publisher, 1000)
+
+	var ids []uint
+	for range 3 {
+		ids = append(ids, createTestOrder(t, svc).ID)
+	}
+	if _, err := svc.UpdateOrderStatus(ctx, ids[1], model.StatusConfirmed); err != nil {
+		t.Fatal(err)
+	}
+	publisher.Reset()
+
+	result, err := replays.Replay(ctx, model.ReplayRequest{
+		OrderIDs:   ids,
+		Status:     model.StatusPending,
+		RoutingKey: "replay.order.created",
+		Queue:      "billing",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Status != model.ReplayCompleted || result.Published != 2 || result.Failed != 0 || result.Request.Rate != 1000 {
+		t.Fatalf("replay = %+v, esperado completed com 2 eventos a 1000/s", result)
+	}
+
+	events, targets := publisher.Events(), publisher.Targets()
+	want := mq.ReplayTarget{ID: result.ID, RoutingKey: "replay.order.created", Queue: "billing"}
+	for i, event := range events {
+		data := event.Data.(map[string]any)
+		if event.Type != "created" || event.Replay != result.ID || data["status"] != model.StatusPending || targets[i] != want {
+			t.Errorf("evento %d = %+v para %+v", i, event, targets[i])
+		}
+	}
+	if len(events) != 2 || events[0].OrderID != int(ids[0]) || events[1].OrderID != int(ids[2]) {
+		t.Fatalf("eventos = %v, esperado os pedidos %d e %d", events, ids[0], ids[2])
+	}
+}
+
+func TestReplayValidation(t *testing.T) {
+	svc, repo, publisher := newTestService()
+	replays := NewReplayService(repo, publisher, 10)
+	createTestOrder(t, svc)
+
+	for name, req := range map[string]model.ReplayRequest{
+		"sem filtro":      {Queue: "billing"},
+		"status inválido": {Status: "lost"},
+		"rate acima":      {Status: model.StatusPending, Rate: 11},
+	} {
+		if _, err := replays.StartReplay(context.Background(), req); !errors.Is(err, ErrInvalidReplay) {
+			t.Errorf("%s: err = %v, esperado ErrInvalidReplay", name, err)
+		}
+	}
+	if _, err := replays.GetReplay("rpl_desconhecido"); !errors.Is(err, ErrReplayNotFound) {
+		t.Errorf("GetReplay: err = %v, esperado ErrReplayNotFound", err)
+	}
+	if len(publisher.Events()) != 1 {
+		t.Errorf("replays inválidos publicaram %d eventos", len(publisher.Events())-1)
+	}
+}
+
+func TestCancelReplay(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, publisher := newTestService()
+	// Um evento por segundo: o replay ainda está no primeiro ao ser cancelado
+	replays := NewReplayService(repo, publisher, 1)
+	for range 3 {
+		createTestOrder(t, svc)
+	}
+	publisher.Reset()
+
+	started, err := replays.StartReplay(ctx, model.ReplayRequest{Status: model.StatusPending})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if started.Status != model.ReplayRunning {
+		t.Fatalf("status = %s, esperado running", started.Status)
+	}
+	if _, err := replays.CancelReplay(started.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := replays.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := replays.GetReplay(started.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Status != model.ReplayCancelled || result.FinishedAt == nil || result.Published >= 3 {
+		t.Fatalf("replay = %+v, esperado cancelled antes do fim", result)
+	}
+	if len(publisher.Events()) != result.Published {
+		t.Errorf("%d eventos publicados, replay contou %d", len(publisher.Events()), result.Published)
+	}
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"maps"
+	"slices"
+
+	"order-service/internal/order/model"
+	"order-service/pkg/mq"
+)
+
+// statusEvents mapeia os eventos de pagamento e entrega publicados por
+// outros serviços (routing key) para o status do pedido.
+var statusEvents = map[string]model.OrderStatus{
+	"payment.authorized":  model.StatusConfirmed,
+	"payment.captured":    model.StatusPaid,
+	"payment.failed":      model.StatusFailed,
+	"shipment.dispatched": model.StatusShipped,
+	"shipment.delivered":  model.StatusDelivered,
+}
+
+// StatusEventRoutingKeys são as routing keys tratadas por NewStatusEventHandler.
+func StatusEventRoutingKeys() []string {
+	return slices.Sorted(maps.Keys(statusEvents))
+}
+
+// NewStatusEventHandler aplica os eventos de pagamento e entrega aos pedidos
+// com AdvanceOrderStatus: entregas repetidas não mudam nada e um evento que
+// chega antes dos anteriores completa as transições que faltam. Eventos que
+// não podem ser aplicados (pedido inexistente, cancelado ou com falha) são
+// descartados; só erros de infraestrutura devolvem a mensagem à fila.
+func NewStatusEventHandler(orders OrderService) mq.EventHandler {
+	return func(ctx context.Context, event mq.OrderEvent) error {
+		status, ok := statusEvents[event.RoutingKey]
+		if !ok {
+			slog.DebugContext(ctx, "Evento sem status associado ignorado", "routing_key", event.RoutingKey, "event_id", event.ID)
+			return nil
+		}
+		if event.OrderID <= 0 {
+			slog.WarnContext(ctx, "Evento sem order_id ignorado", "routing_key", event.RoutingKey, "event_id", event.ID)
+			return nil
+		}
+
+		_, err := orders.AdvanceOrderStatus(ctx, uint(event.OrderID), status)
+		switch {
+		case errors.Is(err, ErrOrderNotFound), errors.Is(err, ErrInvalidStatusTransition):
+			slog.WarnContext(ctx, "Evento descartado",
+				"routing_key", event.RoutingKey, "order_id", event.OrderID, "event_id", event.ID, "error", err)
+			return nil
+		case err != nil:
+			return err
+		}
+		return nil
+	}
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"order-service/internal/order/model"
+	"order-service/internal/order/repository"
+	"order-service/pkg/mq"
+	"order-service/pkg/mq/mqtest"
+	"order-service/pkg/transaction"
+)
+
+func TestStatusEventsOutOfOrder(t *testing.T) {
+	ctx := context.Background()
+	svc, _, publisher := newTestService()
+	handle := NewStatusEventHandler(svc)
+	order := createTestOrder(t, svc)
+	publisher.Reset()
+
+	// captured antes de authorized: o pedido passa por confirmed até paid, e
+	// a entrega repetida e o authorized atrasado não mudam mais nada
+	for _, key := range []string{"payment.captured", "payment.captured", "payment.authorized", "payment.failed"} {
+		if err := handle(ctx, mq.OrderEvent{RoutingKey: key, OrderID: int(order.ID)}); err != nil {
+			t.Fatalf("%s: %v", key, err)
+		}
+	}
+
+	got, err := svc.GetOrderByID(ctx, order.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != model.StatusPaid {
+		t.Fatalf("status = %s, esperado paid", got.Status)
+	}
+
+	events := publisher.Events()
+	if len(events) != 2 {
+		t.Fatalf("%d eventos publicados, esperado 2: %v", len(events), events)
+	}
+	for i, want := range []model.OrderStatus{model.StatusConfirmed, model.StatusPaid} {
+		data := events[i].Data.(map[string]any)
+		if events[i].Type != "status_changed" || data["new_status"] != want {
+			t.Errorf("evento %d = %v, esperado status_changed para %s", i, events[i], want)
+		}
+	}
+
+	// shipment.delivered completa shipped e delivered
+	if err := handle(ctx, mq.OrderEvent{RoutingKey: "shipment.delivered", OrderID: int(order.ID)}); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := svc.GetOrderByID(ctx, order.ID); got.Status != model.StatusDelivered {
+		t.Fatalf("status = %s, esperado delivered", got.Status)
+	}
+}
+
+func TestStatusEventsDiscarded(t *testing.T) {
+	ctx := context.Background()
+	svc, _, publisher := newTestService()
+	handle := NewStatusEventHandler(svc)
+
+	failed := createTestOrder(t, svc)
+	cancelled := createTestOrder(t, svc)
+	if err := svc.CancelOrder(ctx, cancelled.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	events := []mq.OrderEvent{
+		{RoutingKey: "payment.failed", OrderID: int(failed.ID)},
+		{RoutingKey: "payment.failed", OrderID: int(failed.ID)},
+		{RoutingKey: "payment.captured", OrderID: int(failed.ID)},
+		{RoutingKey: "shipment.dispatched", OrderID: int(cancelled.ID)},
+		{RoutingKey: "payment.authorized", OrderID: 999},
+		{RoutingKey: "payment.refunded", OrderID: int(failed.ID)},
+		{RoutingKey: "payment.captured"},
+	}
+	publisher.Reset()
+	for _, event := range events {
+		// Descartados são confirmados: devolvê-los à fila não adiantaria
+		if err := handle(ctx, event); err != nil {
+			t.Fatalf("%s (pedido %d): %v", event.RoutingKey, event.OrderID, err)
+		}
+	}
+
+	for id, want := range map[uint]model.OrderStatus{failed.ID: model.StatusFailed, cancelled.ID: model.StatusCancelled} {
+		if got, _ := svc.GetOrderByID(ctx, id); got.Status != want {
+			t.Errorf("pedido %d: status = %s, esperado %s", id, got.Status, want)
+		}
+	}
+	if len(publisher.Events()) != 1 {
+		t.Errorf("%d eventos publicados, esperado só o de failed", len(publisher.Events()))
+	}
+}
+
+// unavailableRepository simula o banco fora ao bloquear o pedido.
+type unavailableRepository struct {
+	*repository.MemoryOrderRepository
+}
+
+var errDatabaseUnavailable = errors.New("connection refused")
+
+func (r unavailableRepository) GetByIDForUpdate(context.Context, uint) (*model.Order, error) {
+	return nil, errDatabaseUnavailable
+}
+
+func TestStatusEventsRequeueOnDatabaseError(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryOrderRepository()
+	svc := NewOrderService(unavailableRepository{repo}, transaction.NewMemoryManager(3, repo), mqtest.NewPublisher())
+	handle := NewStatusEventHandler(svc)
+
+	err := handle(ctx, mq.OrderEvent{RoutingKey: "payment.captured", OrderID: 1})
+	if !errors.Is(err, errDatabaseUnavailable) || errors.Is(err, ErrOrderNotFound) {
+		t.Fatalf("err = %v, esperado o erro do banco para a mensagem voltar à fila", err)
+	}
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"order-service/internal/order/model"
+	"order-service/internal/order/repository"
+	"order-service/pkg/mq"
+	"order-service/pkg/transaction"
+
+	"gorm.io/gorm"
+)
+
+// SummaryService é o lado de leitura das listagens por cliente: mantém
+// customer_order_summary com os eventos order.* e responde as listagens sem
+// carregar os items.
+type SummaryService interface {
+	ListCustomerOrders(ctx context.Context, customerID uint, limit, offset int) ([]model.OrderSummary, error)
+	// HandleEvent aplica um evento order.* ao read model; é o mq.EventHandler
+	// da fila MQ_SUMMARY_QUEUE.
+	HandleEvent(ctx context.Context, event mq.OrderEvent) error
+	// Rebuild recria o read model a partir dos pedidos gravados.
+	Rebuild(ctx context.Context) (int64, error)
+}
+
+type summaryService struct {
+	repo         repository.SummaryRepository
+	transactions transaction.Manager
+}
+
+func NewSummaryService(repo repository.SummaryRepository, transactions transaction.Manager) SummaryService {
+	return &summaryService{
+		repo:         repo,
+		transactions: transactions,
+	}
+}
+
+// summaryEventData reúne os campos de data usados dos eventos created
+// (também os republicados por replay), status_changed e cancelled.
+type summaryEventData struct {
+	CustomerID  uint              `json:"customer_id"`
+	Status      model.OrderStatus `json:"status"`
+	NewStatus   model.OrderStatus `json:"new_status"`
+	TotalAmount float64           `json:"total_amount"`
+	Items       []struct {
+		Quantity int `json:"quantity"`
+	} `json:"items"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	ChangedAt   time.Time `json:"changed_at"`
+	CancelledAt time.Time `json:"cancelled_at"`
+}
+
+func (s *summaryService) ListCustomerOrders(ctx context.Context, customerID uint, limit, offset int) ([]model.OrderSummary, error) {
+	summaries, err := s.repo.ListByCustomer(ctx, customerID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar pedidos do cliente: %w", err)
+	}
+	return summaries, nil
+}
+
+// HandleEvent mescla o evento ao resumo do pedido. Os eventos podem chegar
+// fora de ordem (várias réplicas consomem a fila): o status só muda com um
+// evento publicado depois do último aplicado, e as contagens, que só o
+// created traz, são gravadas mesmo que ele chegue depois de uma mudança de
+// status.
+func (s *summaryService) HandleEvent(ctx context.Context, event mq.OrderEvent) error {
+	switch event.Type {
+	case "created", "status_changed", "cancelled":
+	default:
+		return nil
+	}
+	if event.OrderID <= 0 {
+		slog.WarnContext(ctx, "Evento sem order_id ignorado", "event_type", event.Type, "event_id", event.ID)
+		return nil
+	}
+
+	var data summaryEventData
+	raw, err := json.Marshal(event.Data)
+	if err == nil {
+		err = json.Unmarshal(raw, &data)
+	}
+	if err != nil {
+		slog.WarnContext(ctx, "Evento com data inválido ignorado",
+			"event_type", event.Type, "order_id", event.OrderID, "event_id", event.ID, "error", err)
+		return nil
+	}
+
+	occurredAt := event.OccurredAt.UTC()
+	if occurredAt.IsZero() {
+		occurredAt = time.Now().UTC()
+	}
+
+	id := uint(event.OrderID)
+	err = s.transactions.Do(ctx, func(ctx context.Context) error {
+		summary, err := s.repo.GetForUpdate(ctx, id)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			summary = &model.OrderSummary{ID: id}
+		} else if err != nil {
+			return err
+		}
+
+		applySummaryEvent(summary, event.Type, data, occurredAt)
+		return s.repo.Save(ctx, summary)
+	})
+	if err != nil {
+		return fmt.Errorf("erro ao atualizar resumo do pedido %d: %w", id, err)
+	}
+
+	summaryLag.Observe(time.Since(occurredAt).Seconds())
+	summaryLastEvent.Set(float64(occurredAt.UnixMilli()) / 1000)
+	return nil
+}
+
+func applySummaryEvent(summary *model.OrderSummary, eventType string, data summaryEventData, occurredAt time.Time) {
+	if data.CustomerID != 0 {
+		summary.CustomerID = data.CustomerID
+	}
+	newer := !occurredAt.Before(summary.LastEventAt)
+
+	switch eventType {
+	case "created":
+		summary.ItemCount = len(data.Items)
+		summary.TotalQuantity = 0
+		for _, item := range data.Items {
+			summary.TotalQuantity += item.Quantity
+		}
+		summary.TotalAmount = data.TotalAmount
+		summary.CreatedAt = data.CreatedAt.UTC()
+		if newer {
+			summary.Status = data.Status
+			// Os republicados por replay trazem a última alteração
+			summary.UpdatedAt = data.CreatedAt.UTC()
+			if data.UpdatedAt.After(data.CreatedAt) {
+				summary.UpdatedAt = data.UpdatedAt.UTC()
+			}
+		}
+	case "status_changed":
+		if newer {
+			summary.Status = data.NewStatus
+			summary.UpdatedAt = data.ChangedAt.UTC()
+		}
+	case "cancelled":
+		if newer {
+			summary.Status = model.StatusCancelled
+			summary.UpdatedAt = data.CancelledAt.UTC()
+		}
+	}
+
+	if newer {
+		summary.LastEventAt = occurredAt
+	}
+}
+
+func (s *summaryService) Rebuild(ctx context.Context) (int64, error) {
+	startedAt := time.Now().UTC()
+	rebuilt, err := s.repo.Rebuild(ctx, startedAt)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao reconstruir resumos dos pedidos: %w", err)
+	}
+
+	slog.InfoContext(ctx, "Resumos dos pedidos reconstruídos", "orders", rebuilt, "duration", time.Since(startedAt))
+	return rebuilt, nil
+}
@@ -0,0 +1,223 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"order-service/internal/order/model"
+	"order-service/internal/order/repository"
+	"order-service/pkg/db"
+	"order-service/pkg/db/dbtest"
+	"order-service/pkg/db/migrations"
+	"order-service/pkg/migrate"
+	"order-service/pkg/mq"
+	"order-service/pkg/mq/mqtest"
+	"order-service/pkg/transaction"
+)
+
+// TestSummaryFollowsOrderEvents alimenta o read model com os eventos que o
+// OrderService publicou, como faria o consumidor da fila.
+func TestSummaryFollowsOrderEvents(t *testing.T) {
+	ctx := context.Background()
+	database := dbtest.NewSQLite(t)
+	transactions := transaction.NewManager(database, 3)
+	publisher := mqtest.NewPublisher()
+	orders := NewOrderService(repository.NewOrderRepository(database), transactions, publisher)
+	summaries := NewSummaryService(repository.NewSummaryRepository(database), transactions)
+
+	first := createTestOrder(t, orders)
+	second, err := orders.CreateOrder(ctx, model.CreateOrderRequest{
+		CustomerID: 1,
+		Items: []model.CreateOrderItemRequest{
+			{ProductID: 10, Name: "Teclado", Price: 150, Quantity: 1},
+			{ProductID: 11, Name: "Mouse", Price: 40.5, Quantity: 3},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := orders.UpdateOrderStatus(ctx, first.ID, model.StatusConfirmed); err != nil {
+		t.Fatal(err)
+	}
+	if err := orders.CancelOrder(ctx, second.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, event := range publisher.Events() {
+		if err := summaries.HandleEvent(ctx, event); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	list, err := summaries.ListCustomerOrders(ctx, 1, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 {
+		t.Fatalf("%d resumos, esperado 2", len(list))
+	}
+	// Mais recentes primeiro
+	if list[0].ID != second.ID || list[0].Status != model.StatusCancelled ||
+		list[0].ItemCount != 2 || list[0].TotalQuantity != 4 || list[0].TotalAmount != 271.5 {
+		t.Errorf("resumo do segundo pedido = %+v", list[0])
+	}
+	if list[1].ID != first.ID || list[1].Status != model.StatusConfirmed ||
+		list[1].ItemCount != 1 || list[1].TotalQuantity != 2 || list[1].TotalAmount != 300 {
+		t.Errorf("resumo do primeiro pedido = %+v", list[1])
+	}
+
+	page, err := summaries.ListCustomerOrders(ctx, 1, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page) != 1 || page[0].ID != first.ID {
+		t.Errorf("segunda página = %+v", page)
+	}
+}
+
+func TestSummaryToleratesOutOfOrderEvents(t *testing.T) {
+	ctx := context.Background()
+	database := dbtest.NewSQLite(t)
+	summaries := NewSummaryService(repository.NewSummaryRepository(database), transaction.NewManager(database, 3))
+
+	createdAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	created := mq.OrderEvent{
+		ID: "1", Type: "created", OrderID: 7, OccurredAt: createdAt,
+		Data: map[string]any{
+			"customer_id":  3,
+			"status":       model.StatusPending,
+			"total_amount": 90,
+			"items":        []map[string]any{{"quantity": 2}, {"quantity": 1}},
+			"created_at":   createdAt,
+		},
+	}
+	paid := mq.OrderEvent{
+		ID: "2", Type: "status_changed", OrderID: 7, OccurredAt: createdAt.Add(time.Minute),
+		Data: map[string]any{"customer_id": 3, "new_status": model.StatusPaid, "changed_at": createdAt.Add(time.Minute)},
+	}
+	confirmed := mq.OrderEvent{
+		ID: "3", Type: "status_changed", OrderID: 7, OccurredAt: createdAt.Add(30 * time.Second),
+		Data: map[string]any{"customer_id": 3, "new_status": model.StatusConfirmed, "changed_at": createdAt.Add(30 * time.Second)},
+	}
+
+	// paid chega antes do created e do confirmed, que é anterior a ele
+	for _, event := range []mq.OrderEvent{paid, created, confirmed} {
+		if err := summaries.HandleEvent(ctx, event); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	list, err := summaries.ListCustomerOrders(ctx, 3, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 {
+		t.Fatalf("%d resumos, esperado 1", len(list))
+	}
+	got := list[0]
+	if got.Status != model.StatusPaid || !got.UpdatedAt.Equal(createdAt.Add(time.Minute)) {
+		t.Errorf("status = %s em %s, esperado paid em %s", got.Status, got.UpdatedAt, createdAt.Add(time.Minute))
+	}
+	if got.ItemCount != 2 || got.TotalQuantity != 3 || got.TotalAmount != 90 || !got.CreatedAt.Equal(createdAt) {
+		t.Errorf("contagens = %+v, esperado as do created", got)
+	}
+}
+
+func TestSummaryRebuild(t *testing.T) {
+	ctx := context.Background()
+	database := dbtest.NewSQLite(t)
+	transactions := transaction.NewManager(database, 3)
+	orderRepo := repository.NewOrderRepository(database)
+	orders := NewOrderService(orderRepo, transactions, mqtest.NewPublisher())
+	summaries := NewSummaryService(repository.NewSummaryRepository(database), transactions)
+
+	order := createTestOrder(t, orders)
+	if _, err := orders.UpdateOrderStatus(ctx, order.ID, model.StatusConfirmed); err != nil {
+		t.Fatal(err)
+	}
+	deleted := createTestOrder(t, orders)
+	if err := orderRepo.Delete(ctx, deleted.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// Resumo de um pedido que não existe mais é descartado
+	if err := summaries.HandleEvent(ctx, mq.OrderEvent{
+		Type: "created", OrderID: 99, OccurredAt: time.Now(),
+		Data: map[string]any{"customer_id": 1, "status": model.StatusPending},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	rebuilt, err := summaries.Rebuild(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rebuilt != 1 {
+		t.Errorf("%d resumos reconstruídos, esperado 1", rebuilt)
+	}
+
+	list, err := summaries.ListCustomerOrders(ctx, 1, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].ID != order.ID || list[0].Status != model.StatusConfirmed ||
+		list[0].ItemCount != 1 || list[0].TotalQuantity != 2 || list[0].TotalAmount != 300 {
+		t.Errorf("resumos = %+v", list)
+	}
+}
+
+// TestSummaryMigrationBackfillsOrders reaplica a migration do read model
+// sobre pedidos já gravados: as listagens não ficam vazias depois do deploy.
+func TestSummaryMigrationBackfillsOrders(t *testing.T) {
+	ctx := context.Background()
+	database := dbtest.NewSQLite(t)
+	transactions := transaction.NewManager(database, 3)
+	orders := NewOrderService(repository.NewOrderRepository(database), transactions, mqtest.NewPublisher())
+	summaries := NewSummaryService(repository.NewSummaryRepository(database), transactions)
+
+	order := createTestOrder(t, orders)
+	if err := orders.CancelOrder(ctx, order.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	sqlDB, err := database.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fsys, err := migrations.For(db.DriverSQLite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrator, err := migrate.New(sqlDB, db.DriverSQLite, fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrator.Down(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrator.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := summaries.ListCustomerOrders(ctx, 1, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].ID != order.ID || list[0].Status != model.StatusCancelled ||
+		list[0].ItemCount != 1 || list[0].TotalQuantity != 2 || list[0].TotalAmount != 300 {
+		t.Fatalf("resumos = %+v", list)
+	}
+
+	// Eventos anteriores à migration não voltam o status
+	stale := mq.OrderEvent{
+		Type: "status_changed", OrderID: int(order.ID), OccurredAt: time.Now().Add(-time.Minute),
+		Data: map[string]any{"customer_id": 1, "new_status": model.StatusConfirmed, "changed_at": time.Now().Add(-time.Minute)},
+	}
+	if err := summaries.HandleEvent(ctx, stale); err != nil {
+		t.Fatal(err)
+	}
+	if list, _ := summaries.ListCustomerOrders(ctx, 1, 10, 0); list[0].Status != model.StatusCancelled {
+		t.Errorf("status = %s depois do evento antigo, esperado cancelled", list[0].Status)
+	}
+}
@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"net/http"
+
+	"order-service/internal/webhook/model"
+	"order-service/pkg/openapi"
+)
+
+// AddOpenAPI registra as rotas de webhooks no documento do serviço.
+func AddOpenAPI(doc *openapi.Document) {
+	createSub := doc.Register(model.CreateSubscriptionRequest{})
+	updateSub := doc.Register(model.UpdateSubscriptionRequest{})
+	sub := doc.Register(model.SubscriptionResponse{})
+	subList := doc.Register(SubscriptionListResponse{})
+	delivery := doc.Register(model.Delivery{})
+	deliveryList := doc.Register(DeliveryListResponse{})
+	errorContent := openapi.JSONContent(doc.Register(ErrorResponse{}))
+
+	idParam := openapi.PathParam("id", openapi.Integer())
+
+	doc.AddOperation(http.MethodPost, "/api/v1/webhooks", &openapi.Operation{
+		OperationID: "createWebhook",
+		Summary:     "Cria uma assinatura de webhook (o segredo só é retornado aqui)",
+		Tags:        []string{"webhooks"},
+		RequestBody: &openapi.RequestBody{Required: true, Content: openapi.JSONContent(createSub)},
+		Responses: map[string]*openapi.Response{
+			"201": {Description: "Assinatura criada", Content: openapi.JSONContent(sub)},
+			"400": {Description: "Dados inválidos", Content: errorContent},
+			"500": {Description: "Erro ao criar webhook", Content: errorContent},
+		},
+	})
+
+	doc.AddOperation(http.MethodGet, "/api/v1/webhooks", &openapi.Operation{
+		OperationID: "listWebhooks",
+		Tags:        []string{"webhooks"},
+		Responses: map[string]*openapi.Response{
+			"200": {Description: "Assinaturas", Content: openapi.JSONContent(subList)},
+			"500": {Description: "Erro ao buscar webhooks", Content: errorContent},
+		},
+	})
+
+	doc.AddOperation(http.MethodGet, "/api/v1/webhooks/:id", &openapi.Operation{
+		OperationID: "getWebhook",
+		Tags:        []string{"webhooks"},
+		Parameters:  []openapi.Parameter{idParam},
+		Responses: map[string]*openapi.Response{
+			"200": {Description: "Assinatura", Content: openapi.JSONContent(sub)},
+			"400": {Description: "ID inválido", Content: errorContent},
+			"404": {Description: "Webhook não encontrado", Content: errorContent},
+		},
+	})
+
+	doc.AddOperation(http.MethodPut, "/api/v1/webhooks/:id", &openapi.Operation{
+		OperationID: "updateWebhook",
+		Summary:     "Atualiza URL, filtros, segredo ou reativa a assinatura",
+		Tags:        []string{"webhooks"},
+		Parameters:  []openapi.Parameter{idParam},
+		RequestBody: &openapi.RequestBody{Required: true, Content: openapi.JSONContent(updateSub)},
+		Responses: map[string]*openapi.Response{
+			"200": {Description: "Assinatura atualizada", Content: openapi.JSONContent(sub)},
+			"400": {Description: "Dados inválidos", Content: errorContent},
+			"404": {Description: "Webhook não encontrado", Content: errorContent},
+		},
+	})
+
+	doc.AddOperation(http.MethodDelete, "/api/v1/webhooks/:id", &openapi.Operation{
+		OperationID: "deleteWebhook",
+		Tags:        []string{"webhooks"},
+		Parameters:  []openapi.Parameter{idParam},
+		Responses: map[string]*openapi.Response{
+			"204": {Description: "Assinatura removida"},
+			"400": {Description: "ID inválido", Content: errorContent},
+			"404": {Description: "Webhook não encontrado", Content: errorContent},
+		},
+	})
+
+	doc.AddOperation(http.MethodGet, "/api/v1/webhooks/:id/deliveries", &openapi.Operation{
+		OperationID: "listWebhookDeliveries",
+		Summary:     "Log de entregas da assinatura",
+		Tags:        []string{"webhooks"},
+		Parameters: []openapi.Parameter{
+			idParam,
+			openapi.QueryParam("limit", false, openapi.Integer()),
+			openapi.QueryParam("offset", false, openapi.Integer()),
+		},
+		Responses: map[string]*openapi.Response{
+			"200": {Description: "Entregas", Content: openapi.JSONContent(deliveryList)},
+			"400": {Description: "ID inválido", Content: errorContent},
+			"404": {Description: "Webhook não encontrado", Content: errorContent},
+		},
+	})
+
+	doc.AddOperation(http.MethodPost, "/api/v1/webhooks/:id/deliveries/:delivery_id/redeliver", &openapi.Operation{
+		OperationID: "redeliverWebhook",
+		Summary:     "Reagenda uma entrega para envio imediato",
+		Tags:        []string{"webhooks"},
+		Parameters: []openapi.Parameter{
+			idParam,
+			openapi.PathParam("delivery_id", openapi.Integer()),
+		},
+		Responses: map[string]*openapi.Response{
+			"202": {Description: "Entrega reagendada", Content: openapi.JSONContent(delivery)},
+			"400": {Description: "ID inválido", Content: errorContent},
+			"404": {Description: "Entrega não encontrada", Content: errorContent},
+		},
+	})
+}
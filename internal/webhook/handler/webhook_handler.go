@@ -0,0 +1,229 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"order-service/internal/webhook/model"
+	"order-service/internal/webhook/service"
+	"order-service/pkg/deadline"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WebhookHandler struct {
+	webhookService service.WebhookService
+}
+
+func NewWebhookHandler(webhookService service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+	}
+}
+
+func (h *WebhookHandler) CreateSubscription(c *gin.Context) {
+	var req model.CreateSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Dados inválidos",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	sub, err := h.webhookService.CreateSubscription(c.Request.Context(), req)
+	if err != nil {
+		if deadline.Respond(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Erro ao criar webhook",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, sub)
+}
+
+func (h *WebhookHandler) ListSubscriptions(c *gin.Context) {
+	subs, err := h.webhookService.ListSubscriptions(c.Request.Context())
+	if err != nil {
+		if deadline.Respond(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Erro ao buscar webhooks",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, SubscriptionListResponse{
+		Webhooks: subs,
+		Count:    len(subs),
+	})
+}
+
+func (h *WebhookHandler) GetSubscription(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	sub, err := h.webhookService.GetSubscription(c.Request.Context(), id)
+	if err != nil {
+		if deadline.Respond(c, err) {
+			return
+		}
+		respondError(c, "Erro ao buscar webhook", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, sub)
+}
+
+func (h *WebhookHandler) UpdateSubscription(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req model.UpdateSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Dados inválidos",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	sub, err := h.webhookService.UpdateSubscription(c.Request.Context(), id, req)
+	if err != nil {
+		if deadline.Respond(c, err) {
+			return
+		}
+		respondError(c, "Erro ao atualizar webhook", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, sub)
+}
+
+func (h *WebhookHandler) DeleteSubscription(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.webhookService.DeleteSubscription(c.Request.Context(), id); err != nil {
+		if deadline.Respond(c, err) {
+			return
+		}
+		respondError(c, "Erro ao remover webhook", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+
+	// Parâmetros de paginação
+	limit := 20
+	offset := 0
+
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+			limit = l
+		}
+	}
+
+	if offsetStr := c.Query("offset"); offsetStr != "" {
+		if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
+			offset = o
+		}
+	}
+
+	deliveries, err := h.webhookService.ListDeliveries(c.Request.Context(), id, limit, offset)
+	if err != nil {
+		if deadline.Respond(c, err) {
+			return
+		}
+		respondError(c, "Erro ao buscar entregas", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, DeliveryListResponse{
+		Deliveries: deliveries,
+		Count:      len(deliveries),
+		Limit:      limit,
+		Offset:     offset,
+	})
+}
+
+func (h *WebhookHandler) Redeliver(c *gin.Context) {
+	id, ok := parseID(c, "id")
+	if !ok {
+		return
+	}
+	deliveryID, ok := parseID(c, "delivery_id")
+	if !ok {
+		return
+	}
+
+	delivery, err := h.webhookService.Redeliver(c.Request.Context(), id, deliveryID)
+	if err != nil {
+		if deadline.Respond(c, err) {
+			return
+		}
+		respondError(c, "Erro ao reenviar entrega", err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, delivery)
+}
+
+func parseID(c *gin.Context, name string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param(name), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "ID inválido",
+			Message: name + " deve ser um número",
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func respondError(c *gin.Context, title string, err error) {
+	status := http.StatusInternalServerError
+	if errors.Is(err, service.ErrSubscriptionNotFound) || errors.Is(err, service.ErrDeliveryNotFound) {
+		status = http.StatusNotFound
+	}
+
+	c.JSON(status, ErrorResponse{
+		Error:   title,
+		Message: err.Error(),
+	})
+}
+
+// ErrorResponse é o corpo de erro comum às rotas da API.
+type ErrorResponse = deadline.ErrorResponse
+
+type SubscriptionListResponse struct {
+	Webhooks []model.SubscriptionResponse `json:"webhooks"`
+	Count    int                          `json:"count"`
+}
+
+type DeliveryListResponse struct {
+	Deliveries []model.Delivery `json:"deliveries"`
+	Count      int              `json:"count"`
+	Limit      int              `json:"limit"`
+	Offset     int              `json:"offset"`
+}
@@ -0,0 +1,113 @@
+package model
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliverySucceeded DeliveryStatus = "succeeded"
+	DeliveryFailed    DeliveryStatus = "failed"
+)
+
+type Subscription struct {
+	ID  uint   `json:"id" gorm:"primarykey"`
+	URL string `json:"url" gorm:"type:varchar(2048);not null"`
+	// Tipos de evento separados por vírgula (ex.: "order.created,order.cancelled").
+	// Vazio recebe todos os eventos.
+	EventTypes          string         `json:"event_types" gorm:"type:varchar(500)"`
+	Secret              string         `json:"-" gorm:"type:varchar(255);not null"`
+	Active              bool           `json:"active" gorm:"not null"`
+	ConsecutiveFailures int            `json:"consecutive_failures" gorm:"not null;default:0"`
+	DisabledAt          *time.Time     `json:"disabled_at"`
+	CreatedAt           time.Time      `json:"created_at"`
+	UpdatedAt           time.Time      `json:"updated_at"`
+	DeletedAt           gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+func (Subscription) TableName() string {
+	return "webhook_subscriptions"
+}
+
+type Delivery struct {
+	ID             uint           `json:"id" gorm:"primarykey"`
+	SubscriptionID uint           `json:"subscription_id" gorm:"not null;uniqueIndex:idx_webhook_delivery_event"`
+	Subscription   Subscription   `json:"-" gorm:"foreignKey:SubscriptionID;constraint:OnDelete:CASCADE"`
+	EventID        string         `json:"event_id" gorm:"type:varchar(64);not null;uniqueIndex:idx_webhook_delivery_event"`
+	EventType      string         `json:"event_type" gorm:"type:varchar(50);not null"`
+	Payload        string         `json:"payload" gorm:"type:text;not null"`
+	Status         DeliveryStatus `json:"status" gorm:"type:varchar(20);not null;index"`
+	Attempts       int            `json:"attempts" gorm:"not null;default:0"`
+	NextAttemptAt  time.Time      `json:"next_attempt_at" gorm:"index"`
+	ResponseStatus int            `json:"response_status"`
+	LastError      string         `json:"last_error" gorm:"type:text"`
+	DeliveredAt    *time.Time     `json:"delivered_at"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+}
+
+func (Delivery) TableName() string {
+	return "webhook_deliveries"
+}
+
+type CreateSubscriptionRequest struct {
+	URL        string   `json:"url" binding:"required,url"`
+	EventTypes []string `json:"event_types"`
+	// Opcional; gerado automaticamente quando vazio
+	Secret string `json:"secret,omitempty"`
+}
+
+type UpdateSubscriptionRequest struct {
+	URL        *string   `json:"url,omitempty" binding:"omitempty,url"`
+	EventTypes *[]string `json:"event_types,omitempty"`
+	Secret     *string   `json:"secret,omitempty"`
+	// Reativar uma assinatura desabilitada zera o contador de falhas
+	Active *bool `json:"active,omitempty"`
+}
+
+type SubscriptionResponse struct {
+	ID                  uint       `json:"id"`
+	URL                 string     `json:"url"`
+	EventTypes          []string   `json:"event_types"`
+	Active              bool       `json:"active"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	DisabledAt          *time.Time `json:"disabled_at"`
+	// Retornado apenas na criação
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (s *Subscription) EventTypeList() []string {
+	if s.EventTypes == "" {
+		return []string{}
+	}
+	return strings.Split(s.EventTypes, ",")
+}
+
+func (s *Subscription) SetEventTypes(types []string) {
+	s.EventTypes = strings.Join(types, ",")
+}
+
+func (s *Subscription) Matches(eventType string) bool {
+	return s.EventTypes == "" || slices.Contains(s.EventTypeList(), eventType)
+}
+
+func (s *Subscription) ToResponse() SubscriptionResponse {
+	return SubscriptionResponse{
+		ID:                  s.ID,
+		URL:                 s.URL,
+		EventTypes:          s.EventTypeList(),
+		Active:              s.Active,
+		ConsecutiveFailures: s.ConsecutiveFailures,
+		DisabledAt:          s.DisabledAt,
+		CreatedAt:           s.CreatedAt,
+		UpdatedAt:           s.UpdatedAt,
+	}
+}
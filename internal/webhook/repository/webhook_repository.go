@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"order-service/internal/webhook/model"
+	"order-service/pkg/transaction"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/plugin/dbresolver"
+)
+
+type WebhookRepository interface {
+	CreateSubscription(ctx context.Context, sub *model.Subscription) error
+	GetSubscription(ctx context.Context, id uint) (*model.Subscription, error)
+	ListSubscriptions(ctx context.Context) ([]model.Subscription, error)
+	ListActiveSubscriptions(ctx context.Context) ([]model.Subscription, error)
+	UpdateSubscription(ctx context.Context, sub *model.Subscription) error
+	// UpdateSubscriptionHealth grava apenas active, consecutive_failures e
+	// disabled_at, sem sobrescrever edições feitas pela API.
+	UpdateSubscriptionHealth(ctx context.Context, sub *model.Subscription) error
+	DeleteSubscription(ctx context.Context, id uint) error
+
+	// CreateDeliveries ignora entregas já existentes para o mesmo evento e assinatura
+	CreateDeliveries(ctx context.Context, deliveries []model.Delivery) error
+	// ClaimDueDeliveries reserva entregas pendentes vencidas adiando o
+	// next_attempt_at em lease, para que outras réplicas não as processem.
+	// now deve estar em UTC: no SQLite os horários são comparados como texto.
+	ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]model.Delivery, error)
+	GetDelivery(ctx context.Context, subscriptionID, id uint) (*model.Delivery, error)
+	ListDeliveries(ctx context.Context, subscriptionID uint, limit, offset int) ([]model.Delivery, error)
+	UpdateDelivery(ctx context.Context, delivery *model.Delivery) error
+}
+
+type webhookRepository struct {
+	db *gorm.DB
+}
+
+func NewWebhookRepository(db *gorm.DB) WebhookRepository {
+	return &webhookRepository{db: db}
+}
+
+func (r *webhookRepository) CreateSubscription(ctx context.Context, sub *model.Subscription) error {
+	return transaction.DB(ctx, r.db).Create(sub).Error
+}
+
+func (r *webhookRepository) GetSubscription(ctx context.Context, id uint) (*model.Subscription, error) {
+	var sub model.Subscription
+	// Leituras que antecedem escritas vão ao primário; listagens, às réplicas
+	err := transaction.DB(ctx, r.db).Clauses(dbresolver.Write).First(&sub, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+func (r *webhookRepository) ListSubscriptions(ctx context.Context) ([]model.Subscription, error) {
+	var subs []model.Subscription
+	err := transaction.DB(ctx, r.db).Order("id").Find(&subs).Error
+	return subs, err
+}
+
+func (r *webhookRepository) ListActiveSubscriptions(ctx context.Context) ([]model.Subscription, error) {
+	var subs []model.Subscription
+	err := transaction.DB(ctx, r.db).Clauses(dbresolver.Write).Where("active = ?", true).Find(&subs).Error
+	return subs, err
+}
+
+func (r *webhookRepository) UpdateSubscription(ctx context.Context, sub *model.Subscription) error {
+	return transaction.DB(ctx, r.db).Save(sub).Error
+}
+
+func (r *webhookRepository) UpdateSubscriptionHealth(ctx context.Context, sub *model.Subscription) error {
+	return transaction.DB(ctx, r.db).Model(sub).
+		Select("active", "consecutive_failures", "disabled_at").
+		Updates(sub).Error
+}
+
+func (r *webhookRepository) DeleteSubscription(ctx context.Context, id uint) error {
+	return transaction.DB(ctx, r.db).Delete(&model.Subscription{}, id).Error
+}
+
+func (r *webhookRepository) CreateDeliveries(ctx context.Context, deliveries []model.Delivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return transaction.DB(ctx, r.db).Clauses(clause.OnConflict{DoNothing: true}).Create(&deliveries).Error
+}
+
+func (r *webhookRepository) ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]model.Delivery, error) {
+	var deliveries []model.Delivery
+
+	err := transaction.DB(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var ids []uint
+		err := tx.Model(&model.Delivery{}).
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", model.DeliveryPending, now).
+			Order("next_attempt_at").
+			Limit(limit).
+			Pluck("id", &ids).Error
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+
+		err = tx.Model(&model.Delivery{}).
+			Where("id IN ?", ids).
+			Update("next_attempt_at", now.Add(lease)).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Preload("Subscription").Where("id IN ?", ids).Find(&deliveries).Error
+	})
+
+	return deliveries, err
+}
+
+func (r *webhookRepository) GetDelivery(ctx context.Context, subscriptionID, id uint) (*model.Delivery, error) {
+	var delivery model.Delivery
+	err := transaction.DB(ctx, r.db).Clauses(dbresolver.Write).Where("subscription_id = ?", subscriptionID).First(&delivery, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+func (r *webhookRepository) ListDeliveries(ctx context.Context, subscriptionID uint, limit, offset int) ([]model.Delivery, error) {
+	var deliveries []model.Delivery
+	err := transaction.DB(ctx, r.db).Where("subscription_id = ?", subscriptionID).
+		Limit(limit).
+		Offset(offset).
+		Order("created_at DESC").
+		Find(&deliveries).Error
+	return deliveries, err
+}
+
+func (r *webhookRepository) UpdateDelivery(ctx context.Context, delivery *model.Delivery) error {
+	return transaction.DB(ctx, r.db).Omit("Subscription").Save(delivery).Error
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"order-service/internal/config"
+	"order-service/internal/webhook/model"
+	"order-service/internal/webhook/repository"
+)
+
+const claimBatchSize = 50
+
+// Dispatcher envia as entregas pendentes e reagenda as que falharam com
+// backoff exponencial. Várias réplicas podem rodar ao mesmo tempo: cada
+// entrega é reservada no banco antes do envio.
+type Dispatcher struct {
+	repo   repository.WebhookRepository
+	client *http.Client
+	cfg    *config.WebhookConfig
+}
+
+func NewDispatcher(repo repository.WebhookRepository, client *http.Client, cfg *config.WebhookConfig) *Dispatcher {
+	if client == nil {
+		client = &http.Client{Timeout: cfg.RequestTimeout}
+	}
+
+	return &Dispatcher{
+		repo:   repo,
+		client: client,
+		cfg:    cfg,
+	}
+}
+
+// Run processa entregas a cada PollInterval até o contexto ser cancelado.
+// A rodada em andamento é concluída antes de retornar, para que entregas já
+// reservadas não fiquem presas até o lease expirar.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := d.DispatchDue(context.WithoutCancel(ctx)); err != nil {
+			slog.ErrorContext(ctx, "Erro ao processar webhooks", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DispatchDue envia uma rodada de entregas vencidas.
+func (d *Dispatcher) DispatchDue(ctx context.Context) error {
+	// O lease cobre o tempo máximo de envio de um lote
+	lease := d.cfg.RequestTimeout * 2
+	deliveries, err := d.repo.ClaimDueDeliveries(ctx, time.Now().UTC(), lease, claimBatchSize)
+	if err != nil {
+		return fmt.Errorf("erro ao reservar entregas: %w", err)
+	}
+
+	for i := range deliveries {
+		if ctx.Err() != nil {
+			return nil
+		}
+		d.attempt(ctx, &deliveries[i])
+	}
+	return nil
+}
+
+func (d *Dispatcher) attempt(ctx context.Context, delivery *model.Delivery) {
+	sub := delivery.Subscription
+
+	// Assinatura removida (soft delete não é carregado) ou desabilitada
+	if sub.ID == 0 || !sub.Active {
+		delivery.Status = model.DeliveryFailed
+		delivery.LastError = "assinatura inativa"
+		d.saveDelivery(ctx, delivery)
+		return
+	}
+
+	delivery.Attempts++
+
+	status, err := d.send(ctx, &sub, delivery)
+	delivery.ResponseStatus = status
+
+	if err == nil {
+		now := time.Now().UTC()
+		delivery.Status = model.DeliverySucceeded
+		delivery.DeliveredAt = &now
+		delivery.LastError = ""
+
+		if sub.ConsecutiveFailures > 0 {
+			sub.ConsecutiveFailures = 0
+			d.saveSubscription(ctx, &sub)
+		}
+		d.saveDelivery(ctx, delivery)
+		return
+	}
+
+	delivery.LastError = err.Error()
+	if delivery.Attempts < d.cfg.MaxAttempts {
+		delivery.NextAttemptAt = time.Now().UTC().Add(d.backoff(delivery.Attempts))
+		d.saveDelivery(ctx, delivery)
+		slog.WarnContext(ctx, "Entrega de webhook falhou, nova tentativa agendada",
+			"webhook_id", sub.ID, "delivery_id", delivery.ID, "attempt", delivery.Attempts,
+			"next_attempt_at", delivery.NextAttemptAt, "error", err)
+		return
+	}
+
+	delivery.Status = model.DeliveryFailed
+	d.saveDelivery(ctx, delivery)
+
+	sub.ConsecutiveFailures++
+	if sub.ConsecutiveFailures >= d.cfg.DisableAfter {
+		now := time.Now().UTC()
+		sub.Active = false
+		sub.DisabledAt = &now
+		slog.WarnContext(ctx, "Webhook desabilitado após entregas sem sucesso",
+			"webhook_id", sub.ID, "consecutive_failures", sub.ConsecutiveFailures)
+	}
+	d.saveSubscription(ctx, &sub)
+}
+
+func (d *Dispatcher) send(ctx context.Context, sub *model.Subscription, delivery *model.Delivery) (int, error) {
+	body := []byte(delivery.Payload)
+	now := time.Now().UTC()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("erro ao montar requisição: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "order-service-webhooks/1.0")
+	req.Header.Set(SignatureHeader, Sign(sub.Secret, now, body))
+	req.Header.Set(TimestampHeader, strconv.FormatInt(now.Unix(), 10))
+	req.Header.Set(EventHeader, delivery.EventType)
+	req.Header.Set(DeliveryHeader, strconv.FormatUint(uint64(delivery.ID), 10))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao enviar webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("status inesperado %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// backoff dobra a espera a cada tentativa, limitado a MaxBackoff.
+func (d *Dispatcher) backoff(attempt int) time.Duration {
+	wait := d.cfg.InitialBackoff
+	for i := 1; i < attempt && wait < d.cfg.MaxBackoff; i++ {
+		wait *= 2
+	}
+	return min(wait, d.cfg.MaxBackoff)
+}
+
+func (d *Dispatcher) saveDelivery(ctx context.Context, delivery *model.Delivery) {
+	if err := d.repo.UpdateDelivery(ctx, delivery); err != nil {
+		slog.ErrorContext(ctx, "Erro ao salvar entrega", "delivery_id", delivery.ID, "error", err)
+	}
+}
+
+func (d *Dispatcher) saveSubscription(ctx context.Context, sub *model.Subscription) {
+	if err := d.repo.UpdateSubscriptionHealth(ctx, sub); err != nil {
+		slog.ErrorContext(ctx, "Erro ao salvar webhook", "webhook_id", sub.ID, "error", err)
+	}
+}
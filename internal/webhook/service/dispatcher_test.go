@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"order-service/internal/config"
+	"order-service/internal/webhook/model"
+	"order-service/internal/webhook/repository"
+)
+
+// fakeRepository guarda apenas o necessário para o dispatcher e para
+// HandleOrderEvent.
+type fakeRepository struct {
+	repository.WebhookRepository
+	due           []model.Delivery
+	deliveries    map[uint]model.Delivery
+	subscription  *model.Subscription
+	subscriptions []model.Subscription
+	created       []model.Delivery
+	lookupErr     error
+}
+
+func (r *fakeRepository) GetSubscription(context.Context, uint) (*model.Subscription, error) {
+	if r.lookupErr != nil {
+		return nil, r.lookupErr
+	}
+	return r.subscription, nil
+}
+
+func (r *fakeRepository) ListActiveSubscriptions(context.Context) ([]model.Subscription, error) {
+	return r.subscriptions, nil
+}
+
+func (r *fakeRepository) CreateDeliveries(_ context.Context, deliveries []model.Delivery) error {
+	r.created = append(r.created, deliveries...)
+	return nil
+}
+
+func (r *fakeRepository) ClaimDueDeliveries(_ context.Context, now time.Time, lease time.Duration, limit int) ([]model.Delivery, error) {
+	due := r.due
+	r.due = nil
+	return due, nil
+}
+
+func (r *fakeRepository) UpdateDelivery(_ context.Context, d *model.Delivery) error {
+	r.deliveries[d.ID] = *d
+	return nil
+}
+
+func (r *fakeRepository) UpdateSubscriptionHealth(_ context.Context, sub *model.Subscription) error {
+	r.subscription = sub
+	return nil
+}
+
+func TestDispatcherSignsAndRetries(t *testing.T) {
+	const secret = "s3cr3t"
+	status := http.StatusOK
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := VerifySignature(secret, r.Header.Get(SignatureHeader), body, time.Minute); err != nil {
+			t.Errorf("assinatura inválida: %v", err)
+		}
+		if got := r.Header.Get(EventHeader); got != "order.created" {
+			t.Errorf("evento = %q, esperado order.created", got)
+		}
+		w.WriteHeader(status)
+	}))
+	defer receiver.Close()
+
+	cfg := &config.WebhookConfig{
+		RequestTimeout: time.Second,
+		MaxAttempts:    2,
+		InitialBackoff: time.Minute,
+		MaxBackoff:     time.Hour,
+		DisableAfter:   1,
+	}
+	sub := model.Subscription{ID: 1, URL: receiver.URL, Secret: secret, Active: true}
+	newDelivery := func(id uint) model.Delivery {
+		return model.Delivery{
+			ID:             id,
+			SubscriptionID: sub.ID,
+			Subscription:   sub,
+			EventType:      "order.created",
+			Payload:        `{"type":"created","order_id":1}`,
+			Status:         model.DeliveryPending,
+		}
+	}
+
+	repo := &fakeRepository{deliveries: make(map[uint]model.Delivery)}
+	dispatcher := NewDispatcher(repo, receiver.Client(), cfg)
+
+	repo.due = []model.Delivery{newDelivery(1)}
+	if err := dispatcher.DispatchDue(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := repo.deliveries[1]; got.Status != model.DeliverySucceeded || got.ResponseStatus != http.StatusOK {
+		t.Fatalf("entrega 1 = %s/%d, esperado succeeded/200", got.Status, got.ResponseStatus)
+	}
+
+	status = http.StatusInternalServerError
+	repo.due = []model.Delivery{newDelivery(2)}
+	dispatcher.DispatchDue(context.Background())
+
+	retry := repo.deliveries[2]
+	if retry.Status != model.DeliveryPending || retry.Attempts != 1 {
+		t.Fatalf("entrega 2 = %s após %d tentativas, esperado pending após 1", retry.Status, retry.Attempts)
+	}
+	if wait := time.Until(retry.NextAttemptAt); wait < 59*time.Second || wait > time.Minute {
+		t.Errorf("próxima tentativa em %s, esperado ~1m", wait)
+	}
+
+	repo.due = []model.Delivery{retry}
+	dispatcher.DispatchDue(context.Background())
+
+	if got := repo.deliveries[2]; got.Status != model.DeliveryFailed {
+		t.Fatalf("entrega 2 = %s, esperado failed", got.Status)
+	}
+	if repo.subscription == nil || repo.subscription.Active {
+		t.Fatal("assinatura deveria ser desabilitada após esgotar as tentativas")
+	}
+}
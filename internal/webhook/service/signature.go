@@ -0,0 +1,65 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	SignatureHeader = "X-Webhook-Signature"
+	TimestampHeader = "X-Webhook-Timestamp"
+	EventHeader     = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-Delivery"
+)
+
+var ErrInvalidSignature = errors.New("assinatura do webhook inválida")
+
+// Sign calcula o header de assinatura no formato "t=<unix>,v1=<hex>", onde v1
+// é o HMAC-SHA256 de "<unix>.<body>" com o segredo da assinatura.
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	ts := strconv.FormatInt(timestamp.Unix(), 10)
+	return fmt.Sprintf("t=%s,v1=%s", ts, computeMAC(secret, ts, body))
+}
+
+// VerifySignature valida o header gerado por Sign e rejeita timestamps fora da
+// tolerância, para evitar replay. Pode ser usada pelos receptores em Go.
+func VerifySignature(secret, header string, body []byte, tolerance time.Duration) error {
+	var ts, mac string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(part, "=")
+		switch key {
+		case "t":
+			ts = value
+		case "v1":
+			mac = value
+		}
+	}
+
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil || mac == "" {
+		return ErrInvalidSignature
+	}
+
+	if age := time.Since(time.Unix(unix, 0)); age > tolerance || age < -tolerance {
+		return fmt.Errorf("%w: timestamp fora da tolerância", ErrInvalidSignature)
+	}
+
+	if !hmac.Equal([]byte(mac), []byte(computeMAC(secret, ts, body))) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func computeMAC(secret, ts string, body []byte) string {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(ts))
+	h.Write([]byte("."))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,231 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"order-service/internal/webhook/model"
+	"order-service/internal/webhook/repository"
+	"order-service/pkg/mq"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrSubscriptionNotFound = errors.New("assinatura não encontrada")
+	ErrDeliveryNotFound     = errors.New("entrega não encontrada")
+)
+
+type WebhookService interface {
+	CreateSubscription(ctx context.Context, req model.CreateSubscriptionRequest) (*model.SubscriptionResponse, error)
+	GetSubscription(ctx context.Context, id uint) (*model.SubscriptionResponse, error)
+	ListSubscriptions(ctx context.Context) ([]model.SubscriptionResponse, error)
+	UpdateSubscription(ctx context.Context, id uint, req model.UpdateSubscriptionRequest) (*model.SubscriptionResponse, error)
+	DeleteSubscription(ctx context.Context, id uint) error
+
+	ListDeliveries(ctx context.Context, subscriptionID uint, limit, offset int) ([]model.Delivery, error)
+	Redeliver(ctx context.Context, subscriptionID, deliveryID uint) (*model.Delivery, error)
+
+	// HandleOrderEvent enfileira uma entrega por assinatura ativa interessada no evento.
+	HandleOrderEvent(ctx context.Context, event mq.OrderEvent) error
+}
+
+type webhookService struct {
+	repo repository.WebhookRepository
+}
+
+func NewWebhookService(repo repository.WebhookRepository) WebhookService {
+	return &webhookService{repo: repo}
+}
+
+func (s *webhookService) CreateSubscription(ctx context.Context, req model.CreateSubscriptionRequest) (*model.SubscriptionResponse, error) {
+	secret := req.Secret
+	if secret == "" {
+		secret = generateSecret()
+	}
+
+	sub := &model.Subscription{
+		URL:    req.URL,
+		Secret: secret,
+		Active: true,
+	}
+	sub.SetEventTypes(req.EventTypes)
+
+	if err := s.repo.CreateSubscription(ctx, sub); err != nil {
+		return nil, fmt.Errorf("erro ao criar assinatura: %w", err)
+	}
+
+	slog.InfoContext(ctx, "Webhook criado", "webhook_id", sub.ID, "url", sub.URL)
+
+	response := sub.ToResponse()
+	response.Secret = secret
+	return &response, nil
+}
+
+func (s *webhookService) GetSubscription(ctx context.Context, id uint) (*model.SubscriptionResponse, error) {
+	sub, err := s.repo.GetSubscription(ctx, id)
+	if err != nil {
+		return nil, subscriptionNotFound(err)
+	}
+
+	response := sub.ToResponse()
+	return &response, nil
+}
+
+func (s *webhookService) ListSubscriptions(ctx context.Context) ([]model.SubscriptionResponse, error) {
+	subs, err := s.repo.ListSubscriptions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar assinaturas: %w", err)
+	}
+
+	responses := make([]model.SubscriptionResponse, len(subs))
+	for i, sub := range subs {
+		responses[i] = sub.ToResponse()
+	}
+	return responses, nil
+}
+
+func (s *webhookService) UpdateSubscription(ctx context.Context, id uint, req model.UpdateSubscriptionRequest) (*model.SubscriptionResponse, error) {
+	sub, err := s.repo.GetSubscription(ctx, id)
+	if err != nil {
+		return nil, subscriptionNotFound(err)
+	}
+
+	if req.URL != nil {
+		sub.URL = *req.URL
+	}
+	if req.EventTypes != nil {
+		sub.SetEventTypes(*req.EventTypes)
+	}
+	if req.Secret != nil && *req.Secret != "" {
+		sub.Secret = *req.Secret
+	}
+	if req.Active != nil {
+		sub.Active = *req.Active
+		if sub.Active {
+			sub.ConsecutiveFailures = 0
+			sub.DisabledAt = nil
+		}
+	}
+
+	if err := s.repo.UpdateSubscription(ctx, sub); err != nil {
+		return nil, fmt.Errorf("erro ao atualizar assinatura: %w", err)
+	}
+
+	response := sub.ToResponse()
+	return &response, nil
+}
+
+func (s *webhookService) DeleteSubscription(ctx context.Context, id uint) error {
+	if _, err := s.repo.GetSubscription(ctx, id); err != nil {
+		return subscriptionNotFound(err)
+	}
+
+	if err := s.repo.DeleteSubscription(ctx, id); err != nil {
+		return fmt.Errorf("erro ao remover assinatura: %w", err)
+	}
+	return nil
+}
+
+func (s *webhookService) ListDeliveries(ctx context.Context, subscriptionID uint, limit, offset int) ([]model.Delivery, error) {
+	if _, err := s.repo.GetSubscription(ctx, subscriptionID); err != nil {
+		return nil, subscriptionNotFound(err)
+	}
+
+	deliveries, err := s.repo.ListDeliveries(ctx, subscriptionID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar entregas: %w", err)
+	}
+	return deliveries, nil
+}
+
+func (s *webhookService) Redeliver(ctx context.Context, subscriptionID, deliveryID uint) (*model.Delivery, error) {
+	delivery, err := s.repo.GetDelivery(ctx, subscriptionID, deliveryID)
+	if err != nil {
+		return nil, deliveryNotFound(err)
+	}
+
+	delivery.Status = model.DeliveryPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = time.Now().UTC()
+	delivery.LastError = ""
+
+	if err := s.repo.UpdateDelivery(ctx, delivery); err != nil {
+		return nil, fmt.Errorf("erro ao reagendar entrega: %w", err)
+	}
+
+	slog.InfoContext(ctx, "Entrega reagendada manualmente", "webhook_id", subscriptionID, "delivery_id", deliveryID)
+	return delivery, nil
+}
+
+func (s *webhookService) HandleOrderEvent(ctx context.Context, event mq.OrderEvent) error {
+	// O ID do evento garante que a mesma entrega não seja criada duas vezes
+	if event.ID == "" {
+		slog.WarnContext(ctx, "Evento sem ID ignorado pelos webhooks", "event_type", event.Type, "order_id", event.OrderID)
+		return nil
+	}
+	// Replays sem fila de destino são para os consumers internos; os
+	// parceiros só os recebem quando o replay é direcionado à fila dos webhooks
+	// (o consumer já descarta os direcionados a outras filas)
+	if event.Replay != "" && event.ReplayQueue == "" {
+		slog.DebugContext(ctx, "Replay ignorado pelos webhooks", "replay_id", event.Replay, "event_id", event.ID)
+		return nil
+	}
+
+	subs, err := s.repo.ListActiveSubscriptions(ctx)
+	if err != nil {
+		return fmt.Errorf("erro ao buscar assinaturas: %w", err)
+	}
+
+	eventType := "order." + event.Type
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("erro ao serializar evento: %w", err)
+	}
+
+	var deliveries []model.Delivery
+	for _, sub := range subs {
+		if !sub.Matches(eventType) {
+			continue
+		}
+		deliveries = append(deliveries, model.Delivery{
+			SubscriptionID: sub.ID,
+			EventID:        event.ID,
+			EventType:      eventType,
+			Payload:        string(payload),
+			Status:         model.DeliveryPending,
+			NextAttemptAt:  time.Now().UTC(),
+		})
+	}
+
+	return s.repo.CreateDeliveries(ctx, deliveries)
+}
+
+// subscriptionNotFound traduz o registro inexistente para
+// ErrSubscriptionNotFound; os demais erros do banco seguem como estão.
+func subscriptionNotFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("%w: %w", ErrSubscriptionNotFound, err)
+	}
+	return fmt.Errorf("erro ao buscar assinatura: %w", err)
+}
+
+// deliveryNotFound traduz o registro inexistente para ErrDeliveryNotFound.
+func deliveryNotFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("%w: %w", ErrDeliveryNotFound, err)
+	}
+	return fmt.Errorf("erro ao buscar entrega: %w", err)
+}
+
+func generateSecret() string {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return "whsec_" + hex.EncodeToString(b)
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"order-service/internal/webhook/model"
+	"order-service/pkg/mq"
+
+	"gorm.io/gorm"
+)
+
+func TestGetSubscriptionErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		notFound bool
+	}{
+		{"inexistente", gorm.ErrRecordNotFound, true},
+		// Falha do banco não vira 404
+		{"erro do banco", errors.New("connection refused"), false},
+		{"prazo estourado", context.DeadlineExceeded, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewWebhookService(&fakeRepository{lookupErr: tt.err}).GetSubscription(context.Background(), 1)
+			if errors.Is(err, ErrSubscriptionNotFound) != tt.notFound {
+				t.Errorf("erro = %v, assinatura não encontrada esperado %v", err, tt.notFound)
+			}
+			if !errors.Is(err, tt.err) {
+				t.Errorf("erro = %v, esperado embrulhando %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestHandleOrderEvent(t *testing.T) {
+	tests := []struct {
+		name  string
+		event mq.OrderEvent
+		want  int
+	}{
+		{"evento novo", mq.OrderEvent{ID: "e1", Type: "created", OrderID: 1}, 2},
+		{"tipo não assinado", mq.OrderEvent{ID: "e2", Type: "paid", OrderID: 1}, 1},
+		{"sem ID", mq.OrderEvent{Type: "created", OrderID: 1}, 0},
+		// Replay para todas as filas é dos consumers internos
+		{"replay sem fila", mq.OrderEvent{ID: "e3", Type: "created", OrderID: 1, Replay: "r1"}, 0},
+		// O consumer só entrega à fila dos webhooks os replays direcionados a ela
+		{"replay direcionado", mq.OrderEvent{ID: "e4", Type: "created", OrderID: 1, Replay: "r2", ReplayQueue: "order_webhooks"}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			all := model.Subscription{ID: 1, Active: true}
+			created := model.Subscription{ID: 2, Active: true}
+			created.SetEventTypes([]string{"order.created"})
+			repo := &fakeRepository{subscriptions: []model.Subscription{all, created}}
+
+			if err := NewWebhookService(repo).HandleOrderEvent(context.Background(), tt.event); err != nil {
+				t.Fatal(err)
+			}
+			if len(repo.created) != tt.want {
+				t.Errorf("%d entregas criadas, esperado %d", len(repo.created), tt.want)
+			}
+			for _, d := range repo.created {
+				if d.EventID != tt.event.ID || d.EventType != "order."+tt.event.Type {
+					t.Errorf("entrega = %+v", d)
+				}
+			}
+		})
+	}
+}
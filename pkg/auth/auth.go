@@ -0,0 +1,37 @@
+// Package auth protege as rotas administrativas (nível de log, replays)
+// com um token compartilhado, enviado como "Authorization: Bearer <token>".
+package auth
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminToken só deixa passar requisições com o token. Sem token
+// configurado, as rotas ficam fechadas (403) em vez de abertas.
+func AdminToken(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token == "" {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error":   "Rota administrativa desabilitada",
+				"message": "ADMIN_TOKEN não configurado",
+			})
+			return
+		}
+
+		got, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			c.Header("WWW-Authenticate", "Bearer")
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error":   "Não autorizado",
+				"message": "token de administração ausente ou inválido",
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,42 @@
+// Package cache guarda valores serializados por chave, com prazo de
+// validade, em memória (LRU de cada réplica) ou no Redis (compartilhado
+// entre as réplicas).
+package cache
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"order-service/internal/config"
+)
+
+const (
+	BackendNone   = "none"
+	BackendMemory = "memory"
+	BackendRedis  = "redis"
+)
+
+type Cache interface {
+	// Get devolve o valor guardado em key; ok é false se a chave não existe
+	// ou expirou.
+	Get(ctx context.Context, key string) (value []byte, ok bool, err error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+	Close() error
+}
+
+// New cria o cache do backend escolhido em cfg.Backend, que não pode ser
+// BackendNone.
+func New(cfg *config.CacheConfig) (Cache, error) {
+	switch cfg.Backend {
+	case BackendMemory:
+		slog.Info("Cache em memória", "size", cfg.Size, "ttl", cfg.TTL)
+		return NewLRU(cfg.Size), nil
+	case BackendRedis:
+		return NewRedis(cfg.RedisURL, cfg.KeyPrefix)
+	default:
+		return nil, fmt.Errorf("unsupported cache backend %q", cfg.Backend)
+	}
+}
@@ -0,0 +1,86 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+func TestLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	c := NewLRU(2)
+
+	c.Set(ctx, "a", []byte("1"), time.Minute)
+	c.Set(ctx, "b", []byte("2"), time.Minute)
+	// a passa a ser a mais recente; b sai ao entrar c
+	if _, ok, _ := c.Get(ctx, "a"); !ok {
+		t.Fatal("a deveria estar no cache")
+	}
+	c.Set(ctx, "c", []byte("3"), time.Minute)
+
+	if _, ok, _ := c.Get(ctx, "b"); ok {
+		t.Error("b deveria ter saído do cache")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok, _ := c.Get(ctx, key); !ok {
+			t.Errorf("%s deveria estar no cache", key)
+		}
+	}
+
+	c.Delete(ctx, "a", "inexistente")
+	if _, ok, _ := c.Get(ctx, "a"); ok || c.Len() != 1 {
+		t.Errorf("a deveria ter sido removida (%d chaves)", c.Len())
+	}
+}
+
+func TestLRUExpires(t *testing.T) {
+	ctx := context.Background()
+	c := NewLRU(10)
+
+	c.Set(ctx, "a", []byte("1"), 10*time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	if _, ok, _ := c.Get(ctx, "a"); ok {
+		t.Error("a deveria ter expirado")
+	}
+	if c.Len() != 0 {
+		t.Errorf("%d chaves, esperado 0 depois da leitura da expirada", c.Len())
+	}
+}
+
+func TestRedis(t *testing.T) {
+	ctx := context.Background()
+	server := miniredis.RunT(t)
+	c, err := NewRedis("redis://"+server.Addr(), "test:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	if _, ok, err := c.Get(ctx, "a"); ok || err != nil {
+		t.Fatalf("Get de chave inexistente = %v, %v", ok, err)
+	}
+	if err := c.Set(ctx, "a", []byte("1"), time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if value, ok, err := c.Get(ctx, "a"); !ok || err != nil || string(value) != "1" {
+		t.Fatalf("Get = %q, %v, %v", value, ok, err)
+	}
+	if !server.Exists("test:a") {
+		t.Error("chave sem o prefixo no Redis")
+	}
+
+	server.FastForward(time.Minute)
+	if _, ok, _ := c.Get(ctx, "a"); ok {
+		t.Error("a deveria ter expirado")
+	}
+
+	c.Set(ctx, "b", []byte("2"), time.Minute)
+	if err := c.Delete(ctx, "b"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, _ := c.Get(ctx, "b"); ok {
+		t.Error("b deveria ter sido removida")
+	}
+}
@@ -1,71 +1,227 @@
 package db
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
-	"log"
+	"log/slog"
+	"net"
+	"strings"
 	"time"
 
 	"order-service/internal/config"
-	"order-service/internal/order/model"
+	"order-service/pkg/db/migrations"
+	"order-service/pkg/logger"
+	"order-service/pkg/metrics"
+	"order-service/pkg/migrate"
+	"order-service/pkg/tracing"
 
+	_ "github.com/jackc/pgx/v5/stdlib"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
+	"gorm.io/plugin/dbresolver"
 )
 
+// Espera entre tentativas de conexão na subida
+const (
+	initialRetryBackoff = 500 * time.Millisecond
+	maxRetryBackoff     = 5 * time.Second
+)
+
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+const (
+	MigrationsAuto  = "auto"
+	MigrationsCheck = "check"
+	MigrationsOff   = "off"
+)
+
+// Connect abre o banco de DB_DRIVER. No Postgres, abre o pool do primário
+// e, se houver DB_REPLICA_HOSTS, das réplicas: com elas, leituras fora de
+// transação vão para uma réplica aleatória e escritas para o primário
+// (leituras que precisam do dado recém-gravado usam dbresolver.Write). Cada
+// pool é testado com backoff por até ConnectRetryTimeout, para a subida
+// tolerar um banco ainda iniciando.
 func Connect(cfg *config.DatabaseConfig) (*gorm.DB, error) {
-	dsn := fmt.Sprintf(
-		"host=%s user=%s password=%s dbname=%s port=%s sslmode=%s TimeZone=UTC",
-		cfg.Host, cfg.User, cfg.Password, cfg.DBName, cfg.Port, cfg.SSLMode,
-	)
+	if cfg.Driver == DriverSQLite {
+		return connectSQLite(cfg)
+	}
+
+	primary, err := open(cfg, cfg.Host, cfg.Port)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := newGorm(postgres.New(postgres.Config{Conn: primary}), primary, cfg, cfg.DBName)
+	if err != nil {
+		return nil, err
+	}
+
+	replicas := cfg.Replicas()
+	if len(replicas) > 0 {
+		dialectors := make([]gorm.Dialector, 0, len(replicas))
+		for _, addr := range replicas {
+			host, port, _ := net.SplitHostPort(addr)
+			replica, err := open(cfg, host, port)
+			if err != nil {
+				return nil, err
+			}
+			if err := metrics.RegisterDBStats(replica, cfg.DBName+"@"+addr); err != nil {
+				return nil, fmt.Errorf("failed to register db stats collector: %w", err)
+			}
+			dialectors = append(dialectors, postgres.New(postgres.Config{Conn: replica}))
+		}
 
-	gormLogger := logger.Default
-	if cfg.DBName != "production" {
-		gormLogger = logger.Default.LogMode(logger.Info)
+		if err := useReplicas(db, dialectors); err != nil {
+			return nil, err
+		}
 	}
 
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
-		Logger: gormLogger,
+	slog.Info("Conectado ao PostgreSQL", "host", cfg.Host, "database", cfg.DBName, "replicas", len(replicas))
+	return db, nil
+}
+
+// useReplicas manda as leituras fora de transação para uma das réplicas,
+// escolhida ao acaso; escritas, transações e dbresolver.Write ficam no primário.
+func useReplicas(db *gorm.DB, replicas []gorm.Dialector) error {
+	err := db.Use(dbresolver.Register(dbresolver.Config{
+		Replicas: replicas,
+		Policy:   dbresolver.RandomPolicy{},
+	}))
+	if err != nil {
+		return fmt.Errorf("failed to register read replicas: %w", err)
+	}
+	return nil
+}
+
+// newGorm abre o GORM sobre sqlDB com o logger, as métricas (duração/erros
+// das queries e estatísticas do pool) e os spans por query.
+func newGorm(dialector gorm.Dialector, sqlDB *sql.DB, cfg *config.DatabaseConfig, statsName string) (*gorm.DB, error) {
+	db, err := gorm.Open(dialector, &gorm.Config{
+		Logger: logger.NewGormLogger(cfg.SlowQueryThreshold),
 		NowFunc: func() time.Time {
 			return time.Now().UTC()
 		},
 	})
-
 	if err != nil {
+		sqlDB.Close()
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
-	//connection pool
-	sqlDB, err := db.DB()
+	if err := db.Use(metrics.GormPlugin{}); err != nil {
+		return nil, fmt.Errorf("failed to register metrics plugin: %w", err)
+	}
+	if err := metrics.RegisterDBStats(sqlDB, statsName); err != nil {
+		return nil, fmt.Errorf("failed to register db stats collector: %w", err)
+	}
+	if err := db.Use(tracing.GormPlugin{}); err != nil {
+		return nil, fmt.Errorf("failed to register tracing plugin: %w", err)
+	}
+	return db, nil
+}
+
+// open cria o pool de um servidor Postgres e espera ele responder.
+func open(cfg *config.DatabaseConfig, host, port string) (*sql.DB, error) {
+	sqlDB, err := sql.Open("pgx", dsn(cfg, host, port))
 	if err != nil {
-		return nil, fmt.Errorf("failed to get sql.DB: %w", err)
+		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
-	sqlDB.SetMaxIdleConns(10)
-	sqlDB.SetMaxOpenConns(100)
-	sqlDB.SetConnMaxLifetime(time.Hour)
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+
+	addr := net.JoinHostPort(host, port)
+	deadline := time.Now().Add(cfg.ConnectRetryTimeout)
+	backoff := initialRetryBackoff
+	for attempt := 1; ; attempt++ {
+		err := sqlDB.Ping()
+		if err == nil {
+			return sqlDB, nil
+		}
+		if time.Now().Add(backoff).After(deadline) {
+			sqlDB.Close()
+			return nil, fmt.Errorf("failed to connect to database at %s after %d attempts: %w", addr, attempt, err)
+		}
 
-	// Testar conexão
-	if err := sqlDB.Ping(); err != nil {
-		return nil, fmt.Errorf("failed to ping database: %w", err)
+		slog.Warn("Banco indisponível, tentando novamente", "addr", addr, "attempt", attempt, "retry_in", backoff, "error", err)
+		time.Sleep(backoff)
+		backoff = min(backoff*2, maxRetryBackoff)
 	}
+}
 
-	log.Println("Conectado ao PostgreSQL")
-	return db, nil
+// dsn monta a string de conexão no formato chave=valor, com os valores entre
+// aspas para senhas com espaços ou aspas.
+func dsn(cfg *config.DatabaseConfig, host, port string) string {
+	params := []string{
+		"host=" + quote(host),
+		"port=" + quote(port),
+		"user=" + quote(cfg.User),
+		"password=" + quote(cfg.Password),
+		"dbname=" + quote(cfg.DBName),
+		"sslmode=" + quote(cfg.SSLMode),
+		"TimeZone=" + quote(cfg.TimeZone),
+	}
+	if cfg.SSLRootCert != "" {
+		params = append(params, "sslrootcert="+quote(cfg.SSLRootCert))
+	}
+	if cfg.SSLCert != "" {
+		params = append(params, "sslcert="+quote(cfg.SSLCert), "sslkey="+quote(cfg.SSLKey))
+	}
+	if cfg.ConnectTimeout > 0 {
+		params = append(params, fmt.Sprintf("connect_timeout=%d", int(cfg.ConnectTimeout.Seconds())))
+	}
+	// Parâmetro de sessão enviado na conexão, em milissegundos
+	if cfg.StatementTimeout > 0 {
+		params = append(params, fmt.Sprintf("statement_timeout=%d", cfg.StatementTimeout.Milliseconds()))
+	}
+	return strings.Join(params, " ")
 }
 
-func Migrate(db *gorm.DB) error {
-	log.Println("Executando migrations...")
+func quote(value string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
+}
 
-	err := db.AutoMigrate(
-		&model.Order{},
-		&model.OrderItem{},
-	)
+// Migrate aplica ou verifica as migrations embutidas do driver conforme o
+// modo: "auto" aplica as pendentes, "check" falha se houver pendentes (o
+// schema é aplicado antes do deploy com cmd/migrate) e "off" não faz nada.
+func Migrate(ctx context.Context, sqlDB *sql.DB, driver, mode string) error {
+	if mode == MigrationsOff {
+		return nil
+	}
 
+	fsys, err := migrations.For(driver)
+	if err != nil {
+		return err
+	}
+	migrator, err := migrate.New(sqlDB, driver, fsys)
 	if err != nil {
-		return fmt.Errorf("failed to migrate database: %w", err)
+		return err
 	}
 
-	log.Println("Migrations executadas com sucesso")
-	return nil
+	switch mode {
+	case MigrationsAuto:
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to migrate database: %w", err)
+		}
+		slog.Info("Migrations executadas com sucesso", "applied", len(applied))
+		return nil
+	case MigrationsCheck:
+		pending, err := migrator.Pending(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to check migrations: %w", err)
+		}
+		if len(pending) > 0 {
+			return fmt.Errorf("database schema is behind: %d pending migrations (first: %d_%s)",
+				len(pending), pending[0].Version, pending[0].Name)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown migrations mode %q", mode)
+	}
 }
@@ -0,0 +1,178 @@
+package db
+
+import (
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"order-service/internal/config"
+
+	"github.com/glebarez/sqlite"
+	"github.com/jackc/pgx/v5"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gorm.io/plugin/dbresolver"
+)
+
+func TestDSN(t *testing.T) {
+	cfg := &config.DatabaseConfig{
+		User:             "orders_user",
+		Password:         `p4ss 'com' \\ aspas`,
+		DBName:           "orders",
+		SSLMode:          "disable",
+		TimeZone:         "America/Sao_Paulo",
+		ConnectTimeout:   2 * time.Second,
+		StatementTimeout: 1500 * time.Millisecond,
+	}
+
+	// O driver lê de volta exatamente o que foi configurado
+	parsed, err := pgx.ParseConfig(dsn(cfg, "db.internal", "6432"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Host != "db.internal" || parsed.Port != 6432 || parsed.User != "orders_user" || parsed.Database != "orders" {
+		t.Errorf("conexão = %s@%s:%d/%s", parsed.User, parsed.Host, parsed.Port, parsed.Database)
+	}
+	if parsed.Password != cfg.Password {
+		t.Errorf("senha = %q, esperado %q", parsed.Password, cfg.Password)
+	}
+	if parsed.ConnectTimeout != 2*time.Second {
+		t.Errorf("connect_timeout = %s, esperado 2s", parsed.ConnectTimeout)
+	}
+	if got := parsed.RuntimeParams["statement_timeout"]; got != "1500" {
+		t.Errorf("statement_timeout = %q, esperado 1500 (ms)", got)
+	}
+	if got := parsed.RuntimeParams["TimeZone"]; got != "America/Sao_Paulo" {
+		t.Errorf("TimeZone = %q", got)
+	}
+
+	// Sem prazos nem TLS, os parâmetros são omitidos
+	plain := dsn(&config.DatabaseConfig{SSLMode: "disable"}, "localhost", "5432")
+	for _, param := range []string{"connect_timeout", "statement_timeout", "sslrootcert", "sslcert", "sslkey"} {
+		if strings.Contains(plain, param+"=") {
+			t.Errorf("dsn com %s sem configuração: %s", param, plain)
+		}
+	}
+
+	tls := dsn(&config.DatabaseConfig{
+		SSLMode:     "verify-full",
+		SSLRootCert: "/certs/ca.pem",
+		SSLCert:     "/certs/client.pem",
+		SSLKey:      "/certs/client.key",
+	}, "localhost", "5432")
+	for _, param := range []string{"sslmode='verify-full'", "sslrootcert='/certs/ca.pem'", "sslcert='/certs/client.pem'", "sslkey='/certs/client.key'"} {
+		if !strings.Contains(tls, param) {
+			t.Errorf("dsn sem %s: %s", param, tls)
+		}
+	}
+}
+
+func TestOpenGivesUpAfterRetryTimeout(t *testing.T) {
+	// Porta livre, sem ninguém escutando
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+	listener.Close()
+
+	cfg := &config.DatabaseConfig{
+		User:                "orders_user",
+		DBName:              "orders",
+		SSLMode:             "disable",
+		TimeZone:            "UTC",
+		MaxOpenConns:        5,
+		ConnectTimeout:      time.Second,
+		ConnectRetryTimeout: 1200 * time.Millisecond,
+	}
+
+	// Tentativas em 0 e 500ms; a próxima (após 1s de espera) passaria do prazo
+	start := time.Now()
+	_, err = open(cfg, "127.0.0.1", port)
+	elapsed := time.Since(start)
+	if err == nil || !strings.Contains(err.Error(), "after 2 attempts") {
+		t.Fatalf("erro = %v, esperado desistência após 2 tentativas", err)
+	}
+	if elapsed < initialRetryBackoff || elapsed > cfg.ConnectRetryTimeout+time.Second {
+		t.Errorf("open levou %s, esperado entre %s e o prazo", elapsed, initialRetryBackoff)
+	}
+
+	// Sem prazo de retry, desiste na primeira falha
+	cfg.ConnectRetryTimeout = 0
+	if _, err := open(cfg, "127.0.0.1", port); err == nil || !strings.Contains(err.Error(), "after 1 attempts") {
+		t.Errorf("erro = %v, esperado desistência na primeira tentativa", err)
+	}
+}
+
+type widget struct {
+	ID   uint
+	Name string
+}
+
+// openSQLiteFile cria um banco SQLite em arquivo com a tabela widgets e
+// um registro name.
+func openSQLiteFile(t *testing.T, path, name string) *gorm.DB {
+	t.Helper()
+	database, err := gorm.Open(sqlite.Open(path), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, _ := database.DB()
+	t.Cleanup(func() { sqlDB.Close() })
+	if err := database.AutoMigrate(&widget{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := database.Create(&widget{Name: name}).Error; err != nil {
+		t.Fatal(err)
+	}
+	return database
+}
+
+// TestReplicaRouting usa um SQLite como primário e outro como réplica, com
+// dados diferentes, para ver de qual banco cada leitura veio.
+func TestReplicaRouting(t *testing.T) {
+	dir := t.TempDir()
+	replicaPath := filepath.Join(dir, "replica.db")
+	openSQLiteFile(t, replicaPath, "replica")
+	primary := openSQLiteFile(t, filepath.Join(dir, "primary.db"), "primary")
+
+	if err := useReplicas(primary, []gorm.Dialector{sqlite.Open(replicaPath)}); err != nil {
+		t.Fatal(err)
+	}
+
+	names := func(tx *gorm.DB) string {
+		t.Helper()
+		var widgets []widget
+		if err := tx.Order("id").Find(&widgets).Error; err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, w := range widgets {
+			names = append(names, w.Name)
+		}
+		return strings.Join(names, ",")
+	}
+
+	// Escritas vão para o primário
+	if err := primary.Create(&widget{Name: "novo"}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if got := names(primary); got != "replica" {
+		t.Errorf("leitura fora de transação = %q, esperado a réplica", got)
+	}
+	if got := names(primary.Clauses(dbresolver.Write)); got != "primary,novo" {
+		t.Errorf("leitura com dbresolver.Write = %q, esperado o primário", got)
+	}
+	err := primary.Transaction(func(tx *gorm.DB) error {
+		if got := names(tx); got != "primary,novo" {
+			t.Errorf("leitura na transação = %q, esperado o primário", got)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
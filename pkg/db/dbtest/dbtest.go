@@ -0,0 +1,37 @@
+// Package dbtest traz o banco dos testes de repositórios e services: um
+// SQLite em memória com as migrations aplicadas.
+package dbtest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"order-service/internal/config"
+	"order-service/pkg/db"
+
+	"gorm.io/gorm"
+)
+
+// NewSQLite devolve um SQLite em memória novo, já migrado, fechado ao fim
+// do teste.
+func NewSQLite(t testing.TB) *gorm.DB {
+	t.Helper()
+	database, err := db.Connect(&config.DatabaseConfig{
+		Driver:             db.DriverSQLite,
+		SQLitePath:         db.SQLitePathMemory,
+		SlowQueryThreshold: time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := database.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	if err := db.Migrate(context.Background(), sqlDB, db.DriverSQLite, db.MigrationsAuto); err != nil {
+		t.Fatal(err)
+	}
+	return database
+}
@@ -0,0 +1,24 @@
+// Package migrations contém o schema do banco em arquivos SQL versionados,
+// embutidos no binário: um diretório por driver, com as mesmas versões nos
+// dois. Novos arquivos: go run ./cmd/migrate create <nome>.
+package migrations
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+)
+
+// Drivers com migrations, que também são os nomes dos diretórios
+var Drivers = []string{"postgres", "sqlite"}
+
+//go:embed postgres/*.sql sqlite/*.sql
+var files embed.FS
+
+// For devolve as migrations do driver (postgres ou sqlite).
+func For(driver string) (fs.FS, error) {
+	if _, err := fs.Stat(files, driver); err != nil {
+		return nil, fmt.Errorf("no migrations for driver %q", driver)
+	}
+	return fs.Sub(files, driver)
+}
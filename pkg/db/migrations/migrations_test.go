@@ -0,0 +1,33 @@
+package migrations
+
+import (
+	"io/fs"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// Cada versão precisa existir para todos os drivers, com o mesmo nome.
+func TestDriversHaveSameVersions(t *testing.T) {
+	var want []string
+	for _, driver := range Drivers {
+		fsys, err := For(driver)
+		if err != nil {
+			t.Fatal(err)
+		}
+		names, err := fs.Glob(fsys, "*.sql")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(names) == 0 {
+			t.Fatalf("%s: nenhuma migration", driver)
+		}
+		if want == nil {
+			want = names
+			continue
+		}
+		if !slices.Equal(names, want) {
+			t.Errorf("%s tem %s, %s tem %s", Drivers[0], strings.Join(want, ", "), driver, strings.Join(names, ", "))
+		}
+	}
+}
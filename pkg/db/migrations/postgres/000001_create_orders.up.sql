@@ -0,0 +1,28 @@
+-- IF NOT EXISTS: bancos criados pelo antigo AutoMigrate já têm estas tabelas
+CREATE TABLE IF NOT EXISTS orders (
+    id           BIGSERIAL PRIMARY KEY,
+    customer_id  BIGINT NOT NULL,
+    status       VARCHAR(20) DEFAULT 'pending',
+    total_amount DECIMAL(10,2),
+    created_at   TIMESTAMPTZ,
+    updated_at   TIMESTAMPTZ,
+    deleted_at   TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_orders_deleted_at ON orders (deleted_at);
+
+CREATE TABLE IF NOT EXISTS order_items (
+    id         BIGSERIAL PRIMARY KEY,
+    order_id   BIGINT NOT NULL,
+    product_id BIGINT NOT NULL,
+    name       VARCHAR(255) NOT NULL,
+    price      DECIMAL(10,2) NOT NULL,
+    quantity   BIGINT NOT NULL,
+    subtotal   DECIMAL(10,2),
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ,
+    CONSTRAINT fk_orders_items FOREIGN KEY (order_id) REFERENCES orders (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_items_deleted_at ON order_items (deleted_at);
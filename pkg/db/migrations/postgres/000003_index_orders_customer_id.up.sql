@@ -0,0 +1,2 @@
+-- Listagem por cliente (GET /api/v1/orders?customer_id=)
+CREATE INDEX IF NOT EXISTS idx_orders_customer_id ON orders (customer_id);
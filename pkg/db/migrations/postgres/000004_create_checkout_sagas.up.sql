@@ -0,0 +1,33 @@
+-- Sagas de checkout (internal/checkout): uma por pedido, com os passos em
+-- checkout_saga_steps
+CREATE TABLE IF NOT EXISTS checkout_sagas (
+    id           BIGSERIAL PRIMARY KEY,
+    order_id     BIGINT NOT NULL,
+    status       VARCHAR(20) NOT NULL,
+    current_step VARCHAR(30) NOT NULL,
+    next_run_at  TIMESTAMPTZ,
+    error        TEXT,
+    created_at   TIMESTAMPTZ,
+    updated_at   TIMESTAMPTZ,
+    CONSTRAINT fk_checkout_sagas_order FOREIGN KEY (order_id) REFERENCES orders (id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_checkout_sagas_order_id ON checkout_sagas (order_id);
+CREATE INDEX IF NOT EXISTS idx_checkout_sagas_status_next_run_at ON checkout_sagas (status, next_run_at);
+
+CREATE TABLE IF NOT EXISTS checkout_saga_steps (
+    id             BIGSERIAL PRIMARY KEY,
+    saga_id        BIGINT NOT NULL,
+    position       BIGINT NOT NULL,
+    name           VARCHAR(30) NOT NULL,
+    status         VARCHAR(20) NOT NULL,
+    reference      VARCHAR(100),
+    error          TEXT,
+    started_at     TIMESTAMPTZ,
+    deadline       TIMESTAMPTZ,
+    finished_at    TIMESTAMPTZ,
+    compensated_at TIMESTAMPTZ,
+    CONSTRAINT fk_checkout_saga_steps_saga FOREIGN KEY (saga_id) REFERENCES checkout_sagas (id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_checkout_saga_step ON checkout_saga_steps (saga_id, position);
@@ -0,0 +1,37 @@
+-- Event store dos pedidos (EVENT_STORE_ENABLED): eventos de domínio com
+-- versão sequencial por pedido e snapshots do estado. Os eventos só são
+-- acrescentados; a única alteração aceita é marcar a publicação.
+CREATE TABLE IF NOT EXISTS order_events (
+    id           BIGSERIAL PRIMARY KEY,
+    order_id     BIGINT NOT NULL,
+    version      INTEGER NOT NULL,
+    type         VARCHAR(30) NOT NULL,
+    data         TEXT NOT NULL,
+    created_at   TIMESTAMPTZ NOT NULL,
+    published_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_order_events_version ON order_events (order_id, version);
+CREATE INDEX IF NOT EXISTS idx_order_events_unpublished ON order_events (id) WHERE published_at IS NULL;
+
+CREATE OR REPLACE FUNCTION order_events_append_only() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'DELETE' OR NEW.order_id <> OLD.order_id OR NEW.version <> OLD.version
+        OR NEW.type <> OLD.type OR NEW.data <> OLD.data OR NEW.created_at <> OLD.created_at THEN
+        RAISE EXCEPTION 'order_events is append-only';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS order_events_append_only ON order_events;
+CREATE TRIGGER order_events_append_only
+    BEFORE UPDATE OR DELETE ON order_events
+    FOR EACH ROW EXECUTE FUNCTION order_events_append_only();
+
+CREATE TABLE IF NOT EXISTS order_snapshots (
+    order_id   BIGINT PRIMARY KEY,
+    version    INTEGER NOT NULL,
+    state      TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL
+);
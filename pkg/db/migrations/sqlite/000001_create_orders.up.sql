@@ -0,0 +1,30 @@
+-- Mesmo schema de postgres/000001_create_orders.up.sql. O SQLite aceita os
+-- tipos decimal(10,2) e varchar(n) dos models, mas não impõe precisão nem
+-- tamanho: o repositório arredonda os valores antes de gravar.
+CREATE TABLE IF NOT EXISTS orders (
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    customer_id  INTEGER NOT NULL,
+    status       VARCHAR(20) DEFAULT 'pending',
+    total_amount DECIMAL(10,2),
+    created_at   DATETIME,
+    updated_at   DATETIME,
+    deleted_at   DATETIME
+);
+
+CREATE INDEX IF NOT EXISTS idx_orders_deleted_at ON orders (deleted_at);
+
+CREATE TABLE IF NOT EXISTS order_items (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    order_id   INTEGER NOT NULL,
+    product_id INTEGER NOT NULL,
+    name       VARCHAR(255) NOT NULL,
+    price      DECIMAL(10,2) NOT NULL,
+    quantity   INTEGER NOT NULL,
+    subtotal   DECIMAL(10,2),
+    created_at DATETIME,
+    updated_at DATETIME,
+    deleted_at DATETIME,
+    CONSTRAINT fk_orders_items FOREIGN KEY (order_id) REFERENCES orders (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_items_deleted_at ON order_items (deleted_at);
@@ -0,0 +1,36 @@
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+    id                   INTEGER PRIMARY KEY AUTOINCREMENT,
+    url                  VARCHAR(2048) NOT NULL,
+    event_types          VARCHAR(500),
+    secret               VARCHAR(255) NOT NULL,
+    active               BOOLEAN NOT NULL,
+    consecutive_failures INTEGER NOT NULL DEFAULT 0,
+    disabled_at          DATETIME,
+    created_at           DATETIME,
+    updated_at           DATETIME,
+    deleted_at           DATETIME
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_subscriptions_deleted_at ON webhook_subscriptions (deleted_at);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id              INTEGER PRIMARY KEY AUTOINCREMENT,
+    subscription_id INTEGER NOT NULL,
+    event_id        VARCHAR(64) NOT NULL,
+    event_type      VARCHAR(50) NOT NULL,
+    payload         TEXT NOT NULL,
+    status          VARCHAR(20) NOT NULL,
+    attempts        INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at DATETIME,
+    response_status INTEGER,
+    last_error      TEXT,
+    delivered_at    DATETIME,
+    created_at      DATETIME,
+    updated_at      DATETIME,
+    CONSTRAINT fk_webhook_deliveries_subscription FOREIGN KEY (subscription_id)
+        REFERENCES webhook_subscriptions (id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_webhook_delivery_event ON webhook_deliveries (subscription_id, event_id);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_status ON webhook_deliveries (status);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_next_attempt_at ON webhook_deliveries (next_attempt_at);
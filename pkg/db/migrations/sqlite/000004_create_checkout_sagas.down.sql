@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS checkout_saga_steps;
+DROP TABLE IF EXISTS checkout_sagas;
@@ -0,0 +1,34 @@
+-- Event store dos pedidos (EVENT_STORE_ENABLED): eventos de domínio com
+-- versão sequencial por pedido e snapshots do estado. Os eventos só são
+-- acrescentados; a única alteração aceita é marcar a publicação.
+CREATE TABLE IF NOT EXISTS order_events (
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    order_id     INTEGER NOT NULL,
+    version      INTEGER NOT NULL,
+    type         VARCHAR(30) NOT NULL,
+    data         TEXT NOT NULL,
+    created_at   DATETIME NOT NULL,
+    published_at DATETIME
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_order_events_version ON order_events (order_id, version);
+CREATE INDEX IF NOT EXISTS idx_order_events_unpublished ON order_events (id) WHERE published_at IS NULL;
+
+CREATE TRIGGER IF NOT EXISTS order_events_no_delete
+BEFORE DELETE ON order_events
+BEGIN
+    SELECT RAISE(ABORT, 'order_events is append-only');
+END;
+
+CREATE TRIGGER IF NOT EXISTS order_events_no_update
+BEFORE UPDATE OF id, order_id, version, type, data, created_at ON order_events
+BEGIN
+    SELECT RAISE(ABORT, 'order_events is append-only');
+END;
+
+CREATE TABLE IF NOT EXISTS order_snapshots (
+    order_id   INTEGER PRIMARY KEY,
+    version    INTEGER NOT NULL,
+    state      TEXT NOT NULL,
+    created_at DATETIME NOT NULL
+);
@@ -0,0 +1,28 @@
+-- Read model das listagens por cliente (GET /api/v1/orders?customer_id=),
+-- mantido pelos eventos order.*. Sem chave estrangeira: é descartável e
+-- reconstruído a partir de orders por cmd/projection rebuild. Começa com os
+-- pedidos existentes, para as listagens não ficarem vazias depois do deploy
+CREATE TABLE IF NOT EXISTS customer_order_summary (
+    order_id       INTEGER PRIMARY KEY,
+    customer_id    INTEGER NOT NULL,
+    status         VARCHAR(20) NOT NULL,
+    item_count     INTEGER NOT NULL,
+    total_quantity INTEGER NOT NULL,
+    total_amount   DECIMAL(10,2),
+    created_at     DATETIME,
+    updated_at     DATETIME,
+    last_event_at  DATETIME NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_customer_order_summary_customer ON customer_order_summary (customer_id, created_at);
+
+-- Mesma consulta de SummaryRepository.Rebuild; eventos publicados antes da
+-- migration são ignorados pelo status
+INSERT INTO customer_order_summary
+    (order_id, customer_id, status, item_count, total_quantity, total_amount, created_at, updated_at, last_event_at)
+SELECT o.id, o.customer_id, COALESCE(o.status, 'pending'), COUNT(i.id), COALESCE(SUM(i.quantity), 0), o.total_amount,
+    o.created_at, o.updated_at, strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')
+FROM orders o
+LEFT JOIN order_items i ON i.order_id = o.id AND i.deleted_at IS NULL
+WHERE o.deleted_at IS NULL
+GROUP BY o.id, o.customer_id, o.status, o.total_amount, o.created_at, o.updated_at;
@@ -0,0 +1,58 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"path/filepath"
+
+	"order-service/internal/config"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+// SQLitePathMemory mantém o banco só em memória, perdido ao encerrar.
+const SQLitePathMemory = ":memory:"
+
+// connectSQLite abre o banco SQLite de DB_SQLITE_PATH (driver em Go puro, sem
+// cgo), para desenvolvimento local e implantações embarcadas.
+func connectSQLite(cfg *config.DatabaseConfig) (*gorm.DB, error) {
+	sqlDB, err := sql.Open("sqlite", sqliteDSN(cfg.SQLitePath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	// Uma conexão só: o SQLite aceita um escritor por vez e um banco em
+	// memória existe apenas na conexão que o criou, que por isso nunca é
+	// reciclada.
+	sqlDB.SetMaxOpenConns(1)
+	sqlDB.SetMaxIdleConns(1)
+	sqlDB.SetConnMaxLifetime(0)
+	sqlDB.SetConnMaxIdleTime(0)
+
+	if err := sqlDB.Ping(); err != nil {
+		sqlDB.Close()
+		return nil, fmt.Errorf("failed to open sqlite database %s: %w", cfg.SQLitePath, err)
+	}
+
+	db, err := newGorm(sqlite.Dialector{Conn: sqlDB}, sqlDB, cfg, "sqlite:"+filepath.Base(cfg.SQLitePath))
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("Conectado ao SQLite", "path", cfg.SQLitePath)
+	return db, nil
+}
+
+// sqliteDSN liga as foreign keys (ON DELETE CASCADE) e, em arquivo, o modo
+// WAL, que deixa leituras de outros processos correrem durante uma escrita.
+func sqliteDSN(path string) string {
+	query := url.Values{"_pragma": {"foreign_keys(1)"}}
+	if path == SQLitePathMemory {
+		return "file::memory:?" + query.Encode()
+	}
+	query.Add("_pragma", "journal_mode(WAL)")
+	return "file:" + path + "?" + query.Encode()
+}
@@ -0,0 +1,66 @@
+// Package deadline limita o tempo das requisições HTTP. O prazo vai no
+// contexto da requisição, que handlers, services e repositórios repassam
+// até o banco: quando ele estoura, as queries em andamento são canceladas.
+package deadline
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StatusClientClosedRequest é o status registrado (métricas e log de acesso)
+// quando o cliente desiste antes da resposta; convenção do nginx.
+const StatusClientClosedRequest = 499
+
+// Middleware aplica o prazo d ao contexto da requisição. Com d <= 0, a rota
+// fica sem prazo (ex.: streams SSE e WebSocket).
+func Middleware(d time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if d <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), d)
+		defer cancel()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// Status indica se err veio do contexto da requisição e o status HTTP
+// correspondente: 504 para prazo estourado e 499 para cliente desconectado.
+func Status(err error) (int, bool) {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout, true
+	case errors.Is(err, context.Canceled):
+		return StatusClientClosedRequest, true
+	default:
+		return 0, false
+	}
+}
+
+// ErrorResponse é o corpo de erro das rotas da API.
+type ErrorResponse struct {
+	Error   string `json:"error"`
+	Message string `json:"message"`
+}
+
+// Respond responde 504 quando o prazo da rota estourou durante a operação e
+// 499 quando o cliente desconectou. Retorna false se err não veio do contexto.
+func Respond(c *gin.Context, err error) bool {
+	status, ok := Status(err)
+	if ok {
+		c.JSON(status, ErrorResponse{
+			Error:   "Requisição interrompida",
+			Message: err.Error(),
+		})
+	}
+	return ok
+}
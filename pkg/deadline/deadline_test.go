@@ -0,0 +1,136 @@
+package deadline
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name         string
+		d            time.Duration
+		wantDeadline bool
+	}{
+		{"com prazo", time.Minute, true},
+		{"sem prazo", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ctx context.Context
+			r := gin.New()
+			r.GET("/", Middleware(tt.d), func(c *gin.Context) {
+				ctx = c.Request.Context()
+				c.Status(http.StatusOK)
+			})
+
+			start := time.Now()
+			r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+			end := time.Now()
+
+			dl, ok := ctx.Deadline()
+			if ok != tt.wantDeadline {
+				t.Fatalf("prazo definido = %v, esperado %v", ok, tt.wantDeadline)
+			}
+			if ok && (dl.Before(start.Add(tt.d)) || dl.After(end.Add(tt.d))) {
+				t.Errorf("prazo = %v, esperado entre %v e %v", dl, start.Add(tt.d), end.Add(tt.d))
+			}
+			// O contexto com prazo é liberado ao fim da requisição
+			if tt.wantDeadline && ctx.Err() == nil {
+				t.Error("contexto ainda ativo após a resposta")
+			}
+		})
+	}
+}
+
+func TestMiddlewarePropagatesCancellation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	parent, cancel := context.WithCancel(context.Background())
+	var err error
+	r := gin.New()
+	r.GET("/", Middleware(time.Minute), func(c *gin.Context) {
+		// O cliente desconecta no meio da requisição
+		cancel()
+		<-c.Request.Context().Done()
+		err = c.Request.Context().Err()
+	})
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil).WithContext(parent))
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("erro = %v, esperado context.Canceled", err)
+	}
+}
+
+func TestStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		want   int
+		wantOK bool
+	}{
+		{"prazo estourado", context.DeadlineExceeded, http.StatusGatewayTimeout, true},
+		{"cliente desconectado", context.Canceled, StatusClientClosedRequest, true},
+		{"embrulhado", fmt.Errorf("failed to get order: %w", context.DeadlineExceeded), http.StatusGatewayTimeout, true},
+		{"outro erro", errors.New("connection refused"), 0, false},
+		{"nil", nil, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Status(tt.err)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("Status = (%d, %v), esperado (%d, %v)", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestRespond(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name   string
+		err    error
+		want   int
+		wantOK bool
+	}{
+		{"prazo estourado", context.DeadlineExceeded, http.StatusGatewayTimeout, true},
+		{"cliente desconectado", context.Canceled, StatusClientClosedRequest, true},
+		{"outro erro", errors.New("connection refused"), http.StatusOK, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+
+			if ok := Respond(c, tt.err); ok != tt.wantOK {
+				t.Fatalf("Respond = %v, esperado %v", ok, tt.wantOK)
+			}
+			if w.Code != tt.want {
+				t.Errorf("status = %d, esperado %d", w.Code, tt.want)
+			}
+			if !tt.wantOK {
+				if w.Body.Len() != 0 {
+					t.Errorf("corpo = %s, esperado vazio", w.Body)
+				}
+				return
+			}
+			var resp ErrorResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			if resp.Message != tt.err.Error() {
+				t.Errorf("message = %q, esperado %q", resp.Message, tt.err.Error())
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package health
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+// CheckFunc verifica uma dependência; deve respeitar o prazo do contexto.
+type CheckFunc func(ctx context.Context) error
+
+type CheckResult struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+type Report struct {
+	Status    string        `json:"status"`
+	Version   string        `json:"version"`
+	CheckedAt time.Time     `json:"checked_at"`
+	Checks    []CheckResult `json:"checks"`
+}
+
+type Liveness struct {
+	Status  string `json:"status"`
+	Version string `json:"version"`
+}
+
+type check struct {
+	name string
+	fn   CheckFunc
+}
+
+// Checker executa as verificações registradas em paralelo, cada uma com
+// timeout, e guarda o resultado por cacheTTL para que probes frequentes não
+// sobrecarreguem o banco e o broker.
+type Checker struct {
+	version  string
+	timeout  time.Duration
+	cacheTTL time.Duration
+
+	mu           sync.Mutex
+	checks       []check
+	last         *Report
+	shuttingDown bool
+}
+
+func NewChecker(version string, timeout, cacheTTL time.Duration) *Checker {
+	return &Checker{
+		version:  version,
+		timeout:  timeout,
+		cacheTTL: cacheTTL,
+	}
+}
+
+// Register adiciona uma verificação de prontidão.
+func (c *Checker) Register(name string, fn CheckFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checks = append(c.checks, check{name: name, fn: fn})
+	c.last = nil
+}
+
+// SetShuttingDown faz o /readyz falhar a partir de agora, para que o load
+// balancer pare de enviar tráfego antes de o servidor ser drenado.
+func (c *Checker) SetShuttingDown() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.shuttingDown = true
+}
+
+// Check retorna o último resultado se ainda estiver no cache; caso contrário
+// executa todas as verificações.
+func (c *Checker) Check(ctx context.Context) Report {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.shuttingDown {
+		return Report{
+			Status:    StatusDown,
+			Version:   c.version,
+			CheckedAt: time.Now(),
+			Checks:    []CheckResult{{Name: "shutdown", Status: StatusDown, Error: "service is shutting down"}},
+		}
+	}
+
+	if c.last != nil && time.Since(c.last.CheckedAt) < c.cacheTTL {
+		return *c.last
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	results := make([]CheckResult, len(c.checks))
+	var wg sync.WaitGroup
+	for i, chk := range c.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = run(ctx, chk)
+		}()
+	}
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Name < results[j].Name })
+
+	report := Report{
+		Status:    StatusUp,
+		Version:   c.version,
+		CheckedAt: time.Now(),
+		Checks:    results,
+	}
+	for _, result := range results {
+		if result.Status != StatusUp {
+			report.Status = StatusDown
+		}
+	}
+
+	c.last = &report
+	return report
+}
+
+func run(ctx context.Context, chk check) CheckResult {
+	start := time.Now()
+	result := CheckResult{Name: chk.name, Status: StatusUp}
+
+	done := make(chan error, 1)
+	go func() { done <- chk.fn(ctx) }()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+	result.DurationMs = time.Since(start).Milliseconds()
+	return result
+}
+
+// LiveHandler responde 200 enquanto o processo estiver de pé; não consulta
+// dependências, para que uma falha no banco não reinicie o pod.
+func (c *Checker) LiveHandler(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, Liveness{Status: StatusUp, Version: c.version})
+}
+
+// ReadyHandler responde 200 quando todas as dependências estão disponíveis e
+// 503 caso contrário, sempre com o detalhe de cada verificação.
+func (c *Checker) ReadyHandler(ctx *gin.Context) {
+	report := c.Check(ctx.Request.Context())
+
+	status := http.StatusOK
+	if report.Status != StatusUp {
+		status = http.StatusServiceUnavailable
+	}
+	ctx.JSON(status, report)
+}
@@ -0,0 +1,118 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCheckCachesResult(t *testing.T) {
+	ctx := context.Background()
+	var calls atomic.Int32
+	checker := NewChecker("test", time.Second, time.Hour)
+	checker.Register("db", func(context.Context) error {
+		calls.Add(1)
+		return nil
+	})
+
+	for range 3 {
+		if report := checker.Check(ctx); report.Status != StatusUp {
+			t.Fatalf("status = %s, esperado up", report.Status)
+		}
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("%d verificações, esperado 1 dentro do cache", n)
+	}
+
+	// Registrar uma verificação descarta o resultado guardado
+	checker.Register("broker", func(context.Context) error { return errors.New("connection refused") })
+	report := checker.Check(ctx)
+	if report.Status != StatusDown || calls.Load() != 2 {
+		t.Fatalf("status = %s com %d verificações, esperado down e 2", report.Status, calls.Load())
+	}
+	if report.Checks[0].Name != "broker" || report.Checks[0].Error != "connection refused" || report.Checks[1].Status != StatusUp {
+		t.Errorf("checks = %+v", report.Checks)
+	}
+}
+
+func TestCheckWithoutCacheRunsEveryTime(t *testing.T) {
+	var calls atomic.Int32
+	checker := NewChecker("test", time.Second, 0)
+	checker.Register("db", func(context.Context) error {
+		calls.Add(1)
+		return nil
+	})
+
+	checker.Check(context.Background())
+	checker.Check(context.Background())
+	if n := calls.Load(); n != 2 {
+		t.Errorf("%d verificações, esperado 2 sem cache", n)
+	}
+}
+
+func TestCheckTimeout(t *testing.T) {
+	checker := NewChecker("test", 20*time.Millisecond, 0)
+	release := make(chan struct{})
+	defer close(release)
+	// Ignora o contexto: o Checker não pode esperar por ela
+	checker.Register("stuck", func(context.Context) error {
+		<-release
+		return nil
+	})
+	checker.Register("db", func(context.Context) error { return nil })
+
+	start := time.Now()
+	report := checker.Check(context.Background())
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("Check levou %s, esperado o prazo de 20ms", elapsed)
+	}
+	if report.Status != StatusDown {
+		t.Fatalf("status = %s, esperado down", report.Status)
+	}
+	if stuck := report.Checks[1]; stuck.Name != "stuck" || stuck.Error != context.DeadlineExceeded.Error() {
+		t.Errorf("stuck = %+v, esperado prazo esgotado", stuck)
+	}
+	if db := report.Checks[0]; db.Status != StatusUp {
+		t.Errorf("db = %+v, esperado up", db)
+	}
+}
+
+func TestShuttingDown(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var calls atomic.Int32
+	checker := NewChecker("test", time.Second, time.Hour)
+	checker.Register("db", func(context.Context) error {
+		calls.Add(1)
+		return nil
+	})
+
+	ready := func() int {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/readyz", nil)
+		checker.ReadyHandler(c)
+		return w.Code
+	}
+	if code := ready(); code != http.StatusOK {
+		t.Fatalf("/readyz = %d, esperado 200", code)
+	}
+
+	// O resultado em cache não mascara o desligamento
+	checker.SetShuttingDown()
+	if code := ready(); code != http.StatusServiceUnavailable {
+		t.Errorf("/readyz = %d no desligamento, esperado 503", code)
+	}
+	report := checker.Check(context.Background())
+	if len(report.Checks) != 1 || report.Checks[0].Name != "shutdown" {
+		t.Errorf("checks = %+v, esperado só shutdown", report.Checks)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("%d verificações, esperado nenhuma depois do desligamento", n-1)
+	}
+}
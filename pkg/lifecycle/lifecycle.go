@@ -0,0 +1,122 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Hook é um componente da aplicação. OnStart deve retornar rápido (trabalho
+// contínuo vai para goroutines, com falhas reportadas via App.Fail); OnStop
+// deve drenar o componente respeitando o prazo do contexto. Ambos são
+// opcionais.
+type Hook struct {
+	Name    string
+	OnStart func(ctx context.Context) error
+	OnStop  func(ctx context.Context) error
+}
+
+// App inicia os hooks na ordem em que foram adicionados e os para na ordem
+// inversa, de modo que um componente só é encerrado depois de tudo que
+// depende dele (ex.: o banco fecha depois do servidor HTTP).
+type App struct {
+	hooks   []Hook
+	timeout time.Duration
+
+	failOnce sync.Once
+	failed   chan error
+}
+
+func New(shutdownTimeout time.Duration) *App {
+	return &App{
+		timeout: shutdownTimeout,
+		failed:  make(chan error, 1),
+	}
+}
+
+// Append registra um hook. Registre primeiro as dependências (banco, broker)
+// e por último quem recebe tráfego (HTTP, gRPC).
+func (a *App) Append(hook Hook) {
+	a.hooks = append(a.hooks, hook)
+}
+
+// Fail encerra a aplicação a partir de uma goroutine de fundo, por exemplo
+// quando o servidor HTTP para com erro.
+func (a *App) Fail(err error) {
+	a.failOnce.Do(func() {
+		a.failed <- err
+	})
+}
+
+// Run inicia os hooks e bloqueia até SIGINT/SIGTERM, cancelamento de ctx ou
+// uma chamada a Fail. Em seguida para os hooks iniciados dentro do prazo de
+// desligamento. Um segundo sinal durante o desligamento encerra o processo.
+func (a *App) Run(ctx context.Context) error {
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	started, err := a.start(ctx)
+	if err == nil {
+		select {
+		case sig := <-signals:
+			slog.Info("Sinal recebido, iniciando desligamento", "signal", sig.String())
+		case err = <-a.failed:
+			slog.Error("Componente falhou, iniciando desligamento", "error", err)
+		case <-ctx.Done():
+			slog.Info("Contexto encerrado, iniciando desligamento")
+		}
+	}
+
+	go func() {
+		if sig, ok := <-signals; ok {
+			slog.Error("Segundo sinal recebido, encerrando imediatamente", "signal", sig.String())
+			os.Exit(1)
+		}
+	}()
+
+	stopCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), a.timeout)
+	defer cancel()
+
+	return errors.Join(err, a.stop(stopCtx, started))
+}
+
+func (a *App) start(ctx context.Context) (int, error) {
+	for i, hook := range a.hooks {
+		if hook.OnStart == nil {
+			continue
+		}
+		if err := hook.OnStart(ctx); err != nil {
+			return i, fmt.Errorf("failed to start %s: %w", hook.Name, err)
+		}
+		slog.Debug("Componente iniciado", "component", hook.Name)
+	}
+	return len(a.hooks), nil
+}
+
+// stop para, em ordem inversa, os hooks [0, started). Continua mesmo se um
+// deles falhar ou o prazo estourar, para liberar o máximo de recursos.
+func (a *App) stop(ctx context.Context, started int) error {
+	var errs []error
+	for i := started - 1; i >= 0; i-- {
+		hook := a.hooks[i]
+		if hook.OnStop == nil {
+			continue
+		}
+
+		start := time.Now()
+		if err := hook.OnStop(ctx); err != nil {
+			slog.Error("Erro ao parar componente", "component", hook.Name, "error", err)
+			errs = append(errs, fmt.Errorf("failed to stop %s: %w", hook.Name, err))
+			continue
+		}
+		slog.Info("Componente parado", "component", hook.Name, "duration", time.Since(start))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,133 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recorder registra a ordem em que os hooks iniciam e param.
+type recorder struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (r *recorder) record(call string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, call)
+}
+
+func (r *recorder) hook(name string) Hook {
+	return Hook{
+		Name: name,
+		OnStart: func(context.Context) error {
+			r.record("start " + name)
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			r.record("stop " + name)
+			return nil
+		},
+	}
+}
+
+func (r *recorder) assert(t *testing.T, want ...string) {
+	t.Helper()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !slices.Equal(r.calls, want) {
+		t.Errorf("chamadas = %v, esperado %v", r.calls, want)
+	}
+}
+
+func TestRunStopsInReverseOrder(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	rec := &recorder{}
+
+	app := New(time.Second)
+	app.Append(rec.hook("db"))
+	app.Append(Hook{Name: "sem hooks"})
+	app.Append(rec.hook("http"))
+	// Encerra assim que tudo iniciou
+	app.Append(Hook{Name: "cancel", OnStart: func(context.Context) error {
+		cancel()
+		return nil
+	}})
+
+	if err := app.Run(ctx); err != nil {
+		t.Fatal(err)
+	}
+	rec.assert(t, "start db", "start http", "stop http", "stop db")
+}
+
+func TestRunStopsStartedHooksWhenStartFails(t *testing.T) {
+	errStart := errors.New("porta em uso")
+	rec := &recorder{}
+
+	app := New(time.Second)
+	app.Append(rec.hook("db"))
+	app.Append(Hook{
+		Name:    "http",
+		OnStart: func(context.Context) error { return errStart },
+		OnStop: func(context.Context) error {
+			rec.record("stop http")
+			return nil
+		},
+	})
+	app.Append(rec.hook("grpc"))
+
+	err := app.Run(context.Background())
+	if !errors.Is(err, errStart) {
+		t.Fatalf("erro = %v, esperado %v", err, errStart)
+	}
+	// Nem o hook que falhou nem os seguintes são parados
+	rec.assert(t, "start db", "stop db")
+}
+
+func TestFailStopsTheApp(t *testing.T) {
+	errServe := errors.New("listener fechado")
+	rec := &recorder{}
+
+	app := New(time.Second)
+	app.Append(rec.hook("db"))
+	app.Append(Hook{Name: "http", OnStart: func(context.Context) error {
+		go func() {
+			app.Fail(errServe)
+			// Só a primeira falha é reportada e as demais não bloqueiam
+			app.Fail(errors.New("outra falha"))
+		}()
+		return nil
+	}})
+
+	err := app.Run(context.Background())
+	if !errors.Is(err, errServe) {
+		t.Fatalf("erro = %v, esperado %v", err, errServe)
+	}
+	rec.assert(t, "start db", "stop db")
+}
+
+func TestStopContinuesAfterErrorsAndTimeout(t *testing.T) {
+	errStop := errors.New("flush falhou")
+	rec := &recorder{}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	app := New(20 * time.Millisecond)
+	app.Append(rec.hook("db"))
+	app.Append(Hook{Name: "tracing", OnStop: func(context.Context) error { return errStop }})
+	// Não termina dentro do prazo de desligamento
+	app.Append(Hook{Name: "http", OnStop: func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}})
+
+	err := app.Run(ctx)
+	if !errors.Is(err, errStop) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("erro = %v, esperado %v e prazo esgotado", err, errStop)
+	}
+	rec.assert(t, "start db", "stop db")
+}
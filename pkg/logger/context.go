@@ -0,0 +1,81 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+type contextKey int
+
+const (
+	requestIDKey contextKey = iota
+	orderIDKey
+	customerIDKey
+)
+
+// WithRequestID anexa o ID da requisição ao contexto; os logs emitidos com
+// esse contexto (slog.InfoContext etc.) passam a incluir request_id.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+func WithOrderID(ctx context.Context, id uint) context.Context {
+	return context.WithValue(ctx, orderIDKey, id)
+}
+
+func WithCustomerID(ctx context.Context, id uint) context.Context {
+	return context.WithValue(ctx, customerIDKey, id)
+}
+
+// RequestID retorna o ID da requisição presente no contexto, se houver.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// FromContext retorna o logger padrão com os atributos do contexto já
+// aplicados, para código que registra vários logs sem repassar o ctx.
+func FromContext(ctx context.Context) *slog.Logger {
+	return slog.Default().With(contextAttrs(ctx)...)
+}
+
+func contextAttrs(ctx context.Context) []any {
+	var attrs []any
+	if id, ok := ctx.Value(requestIDKey).(string); ok {
+		attrs = append(attrs, slog.String("request_id", id))
+	}
+	if id, ok := ctx.Value(orderIDKey).(uint); ok {
+		attrs = append(attrs, slog.Uint64("order_id", uint64(id)))
+	}
+	if id, ok := ctx.Value(customerIDKey).(uint); ok {
+		attrs = append(attrs, slog.Uint64("customer_id", uint64(id)))
+	}
+	if span := trace.SpanContextFromContext(ctx); span.HasTraceID() {
+		attrs = append(attrs, slog.String("trace_id", span.TraceID().String()))
+	}
+	return attrs
+}
+
+// contextHandler acrescenta a cada registro os atributos do contexto.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h *contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if ctx != nil {
+		for _, attr := range contextAttrs(ctx) {
+			r.AddAttrs(attr.(slog.Attr))
+		}
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithGroup(name)}
+}
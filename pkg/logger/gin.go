@@ -0,0 +1,93 @@
+package logger
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDHeader é lido da requisição (quando enviado pelo gateway) e
+// devolvido na resposta.
+const RequestIDHeader = "X-Request-ID"
+
+// Middleware atribui um request ID à requisição, grava-o no contexto e
+// registra uma linha de acesso ao final. Substitui o logger padrão do gin.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		requestID := c.GetHeader(RequestIDHeader)
+		if requestID == "" {
+			requestID = NewRequestID()
+		}
+		c.Header(RequestIDHeader, requestID)
+
+		ctx := WithRequestID(c.Request.Context(), requestID)
+		if customerID, err := strconv.ParseUint(c.GetHeader("X-Customer-ID"), 10, 32); err == nil {
+			ctx = WithCustomerID(ctx, uint(customerID))
+		}
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+
+		attrs := []any{
+			"method", c.Request.Method,
+			"path", c.Request.URL.Path,
+			"route", c.FullPath(),
+			"status", status,
+			"duration", time.Since(start),
+			"client_ip", c.ClientIP(),
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, "errors", c.Errors.String())
+		}
+
+		slog.Log(c.Request.Context(), level, "Requisição HTTP", attrs...)
+	}
+}
+
+// LevelRequest é o corpo aceito por PUT /debug/log-level.
+type LevelRequest struct {
+	Level string `json:"level" binding:"required"`
+}
+
+// LevelHandler expõe o nível atual (GET) e permite alterá-lo (PUT) sem
+// reiniciar o serviço.
+func LevelHandler(c *gin.Context) {
+	if c.Request.Method == http.MethodPut {
+		var req LevelRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Dados inválidos", "message": err.Error()})
+			return
+		}
+		if err := SetLevel(req.Level); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Nível inválido", "message": err.Error()})
+			return
+		}
+		slog.InfoContext(c.Request.Context(), "Nível de log alterado", "level", strings.ToLower(Level().String()))
+	}
+
+	c.JSON(http.StatusOK, LevelRequest{Level: strings.ToLower(Level().String())})
+}
+
+// NewRequestID gera um request ID para quem não recebeu um (ex.: gRPC).
+func NewRequestID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,66 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// GormLogger encaminha os logs do GORM para o slog. O SQL de cada statement
+// sai em debug, queries acima de slowThreshold em warn e erros em error; o
+// filtro fica por conta do nível do slog (LOG_LEVEL). O SQL é logado com os
+// placeholders, sem os valores (segredos de webhook, dados de clientes).
+type GormLogger struct {
+	slowThreshold time.Duration
+}
+
+var _ gorm.ParamsFilter = (*GormLogger)(nil)
+
+func NewGormLogger(slowThreshold time.Duration) gormlogger.Interface {
+	return &GormLogger{slowThreshold: slowThreshold}
+}
+
+func (l *GormLogger) LogMode(gormlogger.LogLevel) gormlogger.Interface {
+	return l
+}
+
+func (l *GormLogger) Info(ctx context.Context, msg string, args ...any) {
+	slog.InfoContext(ctx, fmt.Sprintf(msg, args...), "component", "gorm")
+}
+
+func (l *GormLogger) Warn(ctx context.Context, msg string, args ...any) {
+	slog.WarnContext(ctx, fmt.Sprintf(msg, args...), "component", "gorm")
+}
+
+func (l *GormLogger) Error(ctx context.Context, msg string, args ...any) {
+	slog.ErrorContext(ctx, fmt.Sprintf(msg, args...), "component", "gorm")
+}
+
+// ParamsFilter descarta os valores dos parâmetros do SQL entregue a Trace.
+func (l *GormLogger) ParamsFilter(_ context.Context, sql string, _ ...any) (string, []any) {
+	return sql, nil
+}
+
+func (l *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	elapsed := time.Since(begin)
+
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound):
+		sql, rows := fc()
+		slog.ErrorContext(ctx, "Erro na query", "component", "gorm",
+			"sql", sql, "rows", rows, "duration", elapsed, "error", err)
+	case l.slowThreshold > 0 && elapsed > l.slowThreshold:
+		sql, rows := fc()
+		slog.WarnContext(ctx, "Query lenta", "component", "gorm",
+			"sql", sql, "rows", rows, "duration", elapsed, "threshold", l.slowThreshold)
+	case slog.Default().Enabled(ctx, slog.LevelDebug):
+		sql, rows := fc()
+		slog.DebugContext(ctx, "Query executada", "component", "gorm",
+			"sql", sql, "rows", rows, "duration", elapsed)
+	}
+}
@@ -0,0 +1,88 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestGormLoggerTrace(t *testing.T) {
+	fc := func() (string, int64) { return "SELECT * FROM orders WHERE id = 1", 1 }
+	ctx := WithRequestID(context.Background(), "req-1")
+
+	tests := []struct {
+		name    string
+		level   string
+		elapsed time.Duration
+		err     error
+		want    string // nível esperado, "" para nenhum log
+	}{
+		{"erro", "error", 0, errors.New("connection reset"), "ERROR"},
+		{"registro não encontrado não é erro", "info", 0, gorm.ErrRecordNotFound, ""},
+		{"lenta", "warn", 300 * time.Millisecond, nil, "WARN"},
+		{"rápida em info", "info", 0, nil, ""},
+		{"rápida em debug", "debug", 0, nil, "DEBUG"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := capture(t, tt.level)
+			l := NewGormLogger(200 * time.Millisecond)
+
+			l.Trace(ctx, time.Now().Add(-tt.elapsed), fc, tt.err)
+
+			logs := entries(t, buf)
+			if tt.want == "" {
+				if len(logs) != 0 {
+					t.Errorf("logs = %v, esperado nenhum", logs)
+				}
+				return
+			}
+			if len(logs) != 1 {
+				t.Fatalf("logs = %v, esperado 1", logs)
+			}
+			entry := logs[0]
+			if entry["level"] != tt.want || entry["component"] != "gorm" || entry["request_id"] != "req-1" {
+				t.Errorf("log = %v", entry)
+			}
+			if entry["sql"] != "SELECT * FROM orders WHERE id = 1" || entry["rows"] != float64(1) {
+				t.Errorf("sql/rows = %v/%v", entry["sql"], entry["rows"])
+			}
+		})
+	}
+}
+
+// TestGormLoggerOmitsParams: com o logger no GORM, o SQL logado traz os
+// placeholders e nunca os valores.
+func TestGormLoggerOmitsParams(t *testing.T) {
+	buf := capture(t, "debug")
+	database, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: NewGormLogger(time.Second)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, _ := database.DB()
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	type subscription struct {
+		ID     uint
+		Secret string
+	}
+	if err := database.AutoMigrate(&subscription{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := database.Create(&subscription{Secret: "whsec_abc123"}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(buf.String(), "whsec_abc123") {
+		t.Fatalf("valor do parâmetro no log: %s", buf)
+	}
+	if !strings.Contains(buf.String(), "INSERT INTO") || !strings.Contains(buf.String(), "?") {
+		t.Errorf("SQL do INSERT ausente ou sem placeholders: %s", buf)
+	}
+}
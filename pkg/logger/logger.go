@@ -1,31 +1,64 @@
 package logger
 
 import (
-	"log"
+	"fmt"
+	"io"
+	"log/slog"
 	"os"
+	"strings"
+
+	"order-service/internal/config"
 )
 
-// Simple logger setup para começar
-func Init() {
-	log.SetFlags(log.LstdFlags | log.Lshortfile)
-	log.SetPrefix("[ORDER-SERVICE] ")
+// level é compartilhado por todos os handlers criados por Init, então
+// SetLevel vale imediatamente para o processo inteiro.
+var level = new(slog.LevelVar)
+
+// Init configura o logger padrão do slog (e, por consequência, o pacote log)
+// com o formato e o nível de cfg. Formato "json" ou "text".
+func Init(cfg *config.LogConfig) *slog.Logger {
+	return initWriter(os.Stdout, cfg)
 }
 
-func Info(msg string, args ...interface{}) {
-	log.Printf("INFO: "+msg, args...)
+func initWriter(w io.Writer, cfg *config.LogConfig) *slog.Logger {
+	if err := SetLevel(cfg.Level); err != nil {
+		level.Set(slog.LevelInfo)
+	}
+
+	opts := &slog.HandlerOptions{
+		Level:       level,
+		ReplaceAttr: redact,
+	}
+
+	var handler slog.Handler
+	if cfg.Format == "json" {
+		handler = slog.NewJSONHandler(w, opts)
+	} else {
+		handler = slog.NewTextHandler(w, opts)
+	}
+
+	logger := slog.New(&contextHandler{Handler: handler})
+	slog.SetDefault(logger)
+	return logger
 }
 
-func Error(msg string, args ...interface{}) {
-	log.Printf("ERROR: "+msg, args...)
+// SetLevel altera o nível em tempo de execução (debug, info, warn ou error).
+func SetLevel(name string) error {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(strings.TrimSpace(name))); err != nil {
+		return fmt.Errorf("invalid log level %q", name)
+	}
+	level.Set(l)
+	return nil
 }
 
-func Fatal(msg string, args ...interface{}) {
-	log.Fatalf("FATAL: "+msg, args...)
-	os.Exit(1)
+// Level retorna o nível atual.
+func Level() slog.Level {
+	return level.Level()
 }
 
-func Debug(msg string, args ...interface{}) {
-	if os.Getenv("ENV") != "production" {
-		log.Printf("DEBUG: "+msg, args...)
-	}
+// Fatal registra a mensagem em nível error e encerra o processo.
+func Fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
 }
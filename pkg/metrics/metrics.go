@@ -0,0 +1,46 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Namespace é o prefixo de todas as métricas do serviço.
+const Namespace = "order_service"
+
+var httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: Namespace,
+	Subsystem: "http",
+	Name:      "request_duration_seconds",
+	Help:      "Duração das requisições HTTP por rota e status.",
+	Buckets:   prometheus.DefBuckets,
+}, []string{"method", "route", "status"})
+
+// Handler expõe as métricas no formato do Prometheus.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// Middleware registra a duração de cada requisição. A rota é o template do
+// gin (/api/v1/orders/:id), para não criar uma série por ID.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		httpRequestDuration.
+			WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).
+			Observe(time.Since(start).Seconds())
+	}
+}
@@ -0,0 +1,94 @@
+package metrics_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"order-service/pkg/metrics"
+	"order-service/pkg/metrics/metricstest"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const (
+	httpDuration = "order_service_http_request_duration_seconds"
+	dbDuration   = "order_service_db_query_duration_seconds"
+	dbErrors     = "order_service_db_query_errors_total"
+)
+
+func TestMiddlewareUsesRouteTemplate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(metrics.Middleware())
+	r.GET("/metrics-test/:id", func(c *gin.Context) { c.Status(http.StatusTeapot) })
+
+	before := metricstest.HistogramCount(t, httpDuration, "method", "GET", "route", "/metrics-test/:id", "status", "418")
+	unmatched := metricstest.HistogramCount(t, httpDuration, "method", "GET", "route", "unmatched", "status", "404")
+
+	for _, path := range []string{"/metrics-test/1", "/metrics-test/2", "/nao-existe"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	// Uma série por rota, não por ID
+	if got := metricstest.HistogramCount(t, httpDuration, "method", "GET", "route", "/metrics-test/:id", "status", "418"); got != before+2 {
+		t.Errorf("%d observações na rota, esperado %d", got, before+2)
+	}
+	if got := metricstest.HistogramCount(t, httpDuration, "method", "GET", "route", "unmatched", "status", "404"); got != unmatched+1 {
+		t.Errorf("%d observações em unmatched, esperado %d", got, unmatched+1)
+	}
+}
+
+type widget struct {
+	ID   uint
+	Name string
+}
+
+func TestGormPlugin(t *testing.T) {
+	database, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, _ := database.DB()
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	if err := database.Use(metrics.GormPlugin{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := database.Exec("CREATE TABLE widgets (id INTEGER PRIMARY KEY, name TEXT)").Error; err != nil {
+		t.Fatal(err)
+	}
+
+	creates := metricstest.HistogramCount(t, dbDuration, "operation", "create", "table", "widgets")
+	queries := metricstest.HistogramCount(t, dbDuration, "operation", "query", "table", "widgets")
+	queryErrors := metricstest.CounterValue(t, dbErrors, "operation", "query", "table", "widgets")
+	missingErrors := metricstest.CounterValue(t, dbErrors, "operation", "query", "table", "missing")
+
+	if err := database.Create(&widget{Name: "teclado"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	var w widget
+	if err := database.First(&w, 1).Error; err != nil {
+		t.Fatal(err)
+	}
+	// Registro não encontrado não é erro de banco
+	database.First(&w, 99)
+	// Tabela inexistente é
+	database.Table("missing").Find(&[]widget{})
+
+	if got := metricstest.HistogramCount(t, dbDuration, "operation", "create", "table", "widgets"); got != creates+1 {
+		t.Errorf("%d creates, esperado %d", got, creates+1)
+	}
+	if got := metricstest.HistogramCount(t, dbDuration, "operation", "query", "table", "widgets"); got != queries+2 {
+		t.Errorf("%d queries, esperado %d", got, queries+2)
+	}
+	if got := metricstest.CounterValue(t, dbErrors, "operation", "query", "table", "widgets"); got != queryErrors {
+		t.Errorf("registro não encontrado contado como erro (%v)", got-queryErrors)
+	}
+	if got := metricstest.CounterValue(t, dbErrors, "operation", "query", "table", "missing"); got != missingErrors+1 {
+		t.Errorf("%v erros na tabela inexistente, esperado %v", got, missingErrors+1)
+	}
+}
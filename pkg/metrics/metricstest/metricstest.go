@@ -0,0 +1,53 @@
+// Package metricstest lê as métricas registradas no Prometheus padrão, para
+// os testes conferirem o que foi medido.
+package metricstest
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// HistogramCount devolve quantas observações o histograma name (com o
+// prefixo do namespace) tem na série com os labels, dados em pares
+// nome, valor. Como o registro é global, compare com a contagem de antes.
+func HistogramCount(t testing.TB, name string, labels ...string) uint64 {
+	t.Helper()
+	return find(t, name, labels).GetHistogram().GetSampleCount()
+}
+
+// CounterValue devolve o valor do contador name na série com os labels.
+func CounterValue(t testing.TB, name string, labels ...string) float64 {
+	t.Helper()
+	return find(t, name, labels).GetCounter().GetValue()
+}
+
+// find devolve a série de name com os labels, ou nil se ela ainda não existe.
+func find(t testing.TB, name string, labels []string) *dto.Metric {
+	t.Helper()
+	families, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+	series:
+		for _, metric := range family.GetMetric() {
+			values := make(map[string]string)
+			for _, label := range metric.GetLabel() {
+				values[label.GetName()] = label.GetValue()
+			}
+			for i := 0; i+1 < len(labels); i += 2 {
+				if values[labels[i]] != labels[i+1] {
+					continue series
+				}
+			}
+			return metric
+		}
+	}
+	return nil
+}
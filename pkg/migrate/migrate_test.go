@@ -0,0 +1,298 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	_ "github.com/glebarez/sqlite"
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+func file(body string) *fstest.MapFile {
+	return &fstest.MapFile{Data: []byte(body)}
+}
+
+var testMigrations = fstest.MapFS{
+	"000001_create_a.up.sql":   file("CREATE TABLE a (id INTEGER PRIMARY KEY);"),
+	"000001_create_a.down.sql": file("DROP TABLE a;"),
+	"000002_create_b.up.sql":   file("CREATE TABLE b (id INTEGER PRIMARY KEY);\nINSERT INTO b (id) VALUES (1);"),
+	"000002_create_b.down.sql": file("DROP TABLE b;"),
+	"README.md":                file("ignorado"),
+}
+
+func newSQLite(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// O banco em memória existe só na conexão que o criou
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func versions(migrations []Migration) []int64 {
+	var v []int64
+	for _, m := range migrations {
+		v = append(v, m.Version)
+	}
+	return v
+}
+
+func tableExists(t *testing.T, db *sql.DB, name string) bool {
+	t.Helper()
+	var n int
+	err := db.QueryRow("SELECT count(*) FROM sqlite_master WHERE type = 'table' AND name = ?", name).Scan(&n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return n == 1
+}
+
+func TestUpDownAndStatus(t *testing.T) {
+	ctx := context.Background()
+	db := newSQLite(t)
+	m, err := New(db, "sqlite", testMigrations)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	applied, err := m.Up(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := versions(applied); !slices.Equal(got, []int64{1, 2}) {
+		t.Fatalf("aplicadas = %v, esperado [1 2]", got)
+	}
+
+	// Aplicadas não rodam de novo
+	if applied, err := m.Up(ctx); err != nil || len(applied) != 0 {
+		t.Fatalf("segundo Up aplicou %v (erro %v)", versions(applied), err)
+	}
+
+	reverted, err := m.Down(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := versions(reverted); !slices.Equal(got, []int64{2}) {
+		t.Fatalf("revertidas = %v, esperado [2]", got)
+	}
+	if tableExists(t, db, "b") || !tableExists(t, db, "a") {
+		t.Error("Down(1) deveria remover só a tabela b")
+	}
+
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(statuses) != 2 || statuses[0].AppliedAt == nil || statuses[1].AppliedAt != nil {
+		t.Errorf("status = %+v, esperado 1 aplicada e 2 pendente", statuses)
+	}
+	pending, err := m.Pending(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := versions(pending); !slices.Equal(got, []int64{2}) {
+		t.Errorf("pendentes = %v, esperado [2]", got)
+	}
+}
+
+// TestFailedMigrationRollsBack: cada migration roda na sua transação, então
+// uma falha desfaz só ela e mantém as anteriores aplicadas.
+func TestFailedMigrationRollsBack(t *testing.T) {
+	ctx := context.Background()
+	db := newSQLite(t)
+	fsys := fstest.MapFS{
+		"000001_create_a.up.sql":   testMigrations["000001_create_a.up.sql"],
+		"000001_create_a.down.sql": testMigrations["000001_create_a.down.sql"],
+		"000002_broken.up.sql":     file("CREATE TABLE b (id INTEGER);\nINSERT INTO missing (id) VALUES (1);"),
+		"000002_broken.down.sql":   file("DROP TABLE b;"),
+	}
+	m, err := New(db, "sqlite", fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	applied, err := m.Up(ctx)
+	if err == nil || !strings.Contains(err.Error(), "migration 2_broken failed") {
+		t.Fatalf("erro = %v, esperado falha da migration 2", err)
+	}
+	if got := versions(applied); !slices.Equal(got, []int64{1}) {
+		t.Errorf("aplicadas = %v, esperado [1]", got)
+	}
+	if tableExists(t, db, "b") {
+		t.Error("tabela b criada pela migration que falhou")
+	}
+	pending, err := m.Pending(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := versions(pending); !slices.Equal(got, []int64{2}) {
+		t.Errorf("pendentes = %v, esperado [2]", got)
+	}
+}
+
+func TestNewRejectsInvalidMigrations(t *testing.T) {
+	tests := []struct {
+		name string
+		fsys fstest.MapFS
+		want string
+	}{
+		{"sem down", fstest.MapFS{
+			"000001_a.up.sql": file("SELECT 1;"),
+		}, "must have both up and down files"},
+		{"versão duplicada", fstest.MapFS{
+			"000001_a.up.sql":   file("SELECT 1;"),
+			"000001_a.down.sql": file("SELECT 1;"),
+			"000001_b.up.sql":   file("SELECT 1;"),
+		}, "duplicate migration version 1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(nil, "sqlite", tt.fsys)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("erro = %v, esperado %q", err, tt.want)
+			}
+		})
+	}
+
+	if _, err := New(nil, "mysql", testMigrations); err == nil {
+		t.Error("driver mysql deveria ser recusado")
+	}
+}
+
+func TestCreate(t *testing.T) {
+	postgres, sqlite := t.TempDir(), t.TempDir()
+	write := func(dir, name string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("SELECT 1;"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(postgres, "000001_a.up.sql")
+	write(postgres, "000001_a.down.sql")
+	write(sqlite, "000001_a.up.sql")
+	write(sqlite, "000001_a.down.sql")
+	// Diretório adiantado: a próxima versão vale para os dois
+	write(sqlite, "000002_b.up.sql")
+	write(sqlite, "000002_b.down.sql")
+
+	paths, err := Create("add_index", postgres, sqlite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		filepath.Join(postgres, "000003_add_index.up.sql"),
+		filepath.Join(postgres, "000003_add_index.down.sql"),
+		filepath.Join(sqlite, "000003_add_index.up.sql"),
+		filepath.Join(sqlite, "000003_add_index.down.sql"),
+	}
+	if !slices.Equal(paths, want) {
+		t.Fatalf("arquivos = %v, esperado %v", paths, want)
+	}
+	for _, path := range paths {
+		if _, err := os.Stat(path); err != nil {
+			t.Error(err)
+		}
+	}
+
+	if _, err := Create("Add-Index", postgres); err == nil {
+		t.Error("nome inválido deveria ser recusado")
+	}
+}
+
+// newPostgres abre o banco de TEST_DATABASE_DSN (formato chave=valor) em um
+// schema próprio, removido ao fim do teste.
+func newPostgres(t *testing.T) *sql.DB {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_DSN não definido")
+	}
+
+	admin, err := sql.Open("pgx", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { admin.Close() })
+	schema := fmt.Sprintf("migrate_test_%d", time.Now().UnixNano())
+	if _, err := admin.Exec("CREATE SCHEMA " + schema); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { admin.Exec("DROP SCHEMA " + schema + " CASCADE") })
+
+	db, err := sql.Open("pgx", dsn+" search_path="+schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// TestPostgresAdvisoryLock roda contra o Postgres de TEST_DATABASE_DSN:
+// réplicas subindo juntas aplicam cada migration uma única vez.
+func TestPostgresAdvisoryLock(t *testing.T) {
+	ctx := context.Background()
+	db := newPostgres(t)
+	fsys := fstest.MapFS{
+		"000001_slow.up.sql":   file("CREATE TABLE slow (id INT);\nSELECT pg_sleep(0.2);"),
+		"000001_slow.down.sql": file("DROP TABLE slow;"),
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var total int
+	for range 3 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m, err := New(db, "postgres", fsys)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			applied, err := m.Up(ctx)
+			if err != nil {
+				t.Error(err)
+			}
+			mu.Lock()
+			total += len(applied)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	if total != 1 {
+		t.Errorf("migration aplicada %d vezes, esperado 1", total)
+	}
+
+	// Com o lock preso por outra sessão, Up espera até o prazo do contexto
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", lockID); err != nil {
+		t.Fatal(err)
+	}
+	defer conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", lockID)
+
+	m, err := New(db, "postgres", fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	if _, err := m.Up(timeoutCtx); err == nil || !strings.Contains(err.Error(), "failed to acquire migration lock") {
+		t.Errorf("erro = %v, esperado espera pelo lock", err)
+	}
+}
@@ -0,0 +1,128 @@
+package mq
+
+import (
+	"context"
+	"sync"
+)
+
+// Broadcaster distribui eventos de pedido para assinantes dentro do processo
+// (streams gRPC, SSE, WebSocket). Mantém os últimos eventos em memória para
+// que clientes reconectando possam retomar a partir do último ID recebido.
+// Assinantes lentos perdem eventos em vez de bloquear quem publica.
+type Broadcaster struct {
+	mu      sync.RWMutex
+	nextID  int
+	subs    map[int]*subscription
+	history []OrderEvent
+	size    int
+	closed  bool
+}
+
+type subscription struct {
+	filter func(OrderEvent) bool
+	ch     chan OrderEvent
+}
+
+func NewBroadcaster(historySize int) *Broadcaster {
+	return &Broadcaster{
+		subs: make(map[int]*subscription),
+		size: historySize,
+	}
+}
+
+// Subscribe registra um assinante. A função retornada cancela a assinatura e
+// fecha o canal.
+func (b *Broadcaster) Subscribe(filter func(OrderEvent) bool) (<-chan OrderEvent, func()) {
+	_, ch, cancel, _ := b.SubscribeFrom("", filter)
+	return ch, cancel
+}
+
+// SubscribeFrom registra um assinante e retorna, de forma atômica, os eventos
+// do histórico posteriores a lastEventID. found é false quando o ID não está
+// mais (ou nunca esteve) no histórico; nesse caso o chamador deve reenviar o
+// estado atual.
+func (b *Broadcaster) SubscribeFrom(lastEventID string, filter func(OrderEvent) bool) (replay []OrderEvent, ch <-chan OrderEvent, cancel func(), found bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if lastEventID != "" {
+		for i, event := range b.history {
+			if event.ID != lastEventID {
+				continue
+			}
+			found = true
+			for _, e := range b.history[i+1:] {
+				if filter == nil || filter(e) {
+					replay = append(replay, e)
+				}
+			}
+			break
+		}
+	}
+
+	sub := &subscription{filter: filter, ch: make(chan OrderEvent, 16)}
+	if b.closed {
+		close(sub.ch)
+		return replay, sub.ch, func() {}, found
+	}
+
+	id := b.nextID
+	b.nextID++
+	b.subs[id] = sub
+
+	cancel = func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subs[id]; ok {
+			delete(b.subs, id)
+			close(sub.ch)
+		}
+	}
+	return replay, sub.ch, cancel, found
+}
+
+// Close fecha os canais de todos os assinantes, encerrando os streams abertos.
+// Usado no desligamento, antes de drenar o servidor HTTP e o gRPC.
+func (b *Broadcaster) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for id, sub := range b.subs {
+		delete(b.subs, id)
+		close(sub.ch)
+	}
+}
+
+func (b *Broadcaster) Broadcast(event OrderEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.size > 0 {
+		if len(b.history) >= b.size {
+			b.history = b.history[1:]
+		}
+		b.history = append(b.history, event)
+	}
+
+	for _, sub := range b.subs {
+		if sub.filter != nil && !sub.filter(event) {
+			continue
+		}
+		select {
+		case sub.ch <- event:
+		default:
+		}
+	}
+}
+
+// HandleEvent permite usar o broadcaster como EventHandler de um Consumer.
+// Eventos republicados (replay) não são mudanças novas e não vão para os
+// streams.
+func (b *Broadcaster) HandleEvent(_ context.Context, event OrderEvent) error {
+	if event.Replay != "" {
+		return nil
+	}
+	b.Broadcast(event)
+	return nil
+}
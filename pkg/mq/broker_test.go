@@ -0,0 +1,386 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"order-service/internal/config"
+)
+
+func TestTopicMatches(t *testing.T) {
+	for _, tc := range []struct {
+		pattern, key string
+		want         bool
+	}{
+		{"order.created", "order.created", true},
+		{"order.created", "order.cancelled", false},
+		{"order.*", "order.created", true},
+		{"order.*", "order", false},
+		{"order.*", "order.status.changed", false},
+		{"*.created", "order.created", true},
+		{"order.#", "order", true},
+		{"order.#", "order.status.changed", true},
+		{"#", "order.created", true},
+		{"#.changed", "order.status.changed", true},
+		{"order.#.changed", "order.changed", true},
+		{"order.#.changed", "order.status.created", false},
+	} {
+		if got := topicMatches(strings.Split(tc.pattern, "."), strings.Split(tc.key, ".")); got != tc.want {
+			t.Errorf("%q × %q = %v, esperado %v", tc.pattern, tc.key, got, tc.want)
+		}
+	}
+}
+
+// recorder é um EventHandler que guarda os tipos recebidos.
+type recorder struct {
+	mu     sync.Mutex
+	events []string
+	got    chan struct{}
+}
+
+func newRecorder() *recorder {
+	return &recorder{got: make(chan struct{}, 100)}
+}
+
+func (r *recorder) handle(_ context.Context, event OrderEvent) error {
+	r.mu.Lock()
+	r.events = append(r.events, event.Type)
+	r.mu.Unlock()
+	r.got <- struct{}{}
+	return nil
+}
+
+// wait espera n eventos e confere que nenhum outro chega logo depois.
+func (r *recorder) wait(t *testing.T, n int) []string {
+	t.Helper()
+	for range n {
+		select {
+		case <-r.got:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("esperava %d eventos, recebeu %v", n, r.events)
+		}
+	}
+	select {
+	case <-r.got:
+		t.Fatalf("evento a mais: %v", r.events)
+	case <-time.After(20 * time.Millisecond):
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.events
+}
+
+// brokerFactory cria publishers e consumers ligados a um broker vazio.
+type brokerFactory func(t *testing.T) (newPublisher func() Publisher, newConsumer func() Consumer)
+
+func newTestConfig(broker, url string) *config.MQConfig {
+	return &config.MQConfig{
+		Broker:    broker,
+		URL:       url,
+		Exchange:  "orders",
+		Prefetch:  1,
+		AckWait:   30 * time.Second,
+		Retention: time.Hour,
+	}
+}
+
+func TestMemoryBroker(t *testing.T) {
+	testBroker(t, func(t *testing.T) (func() Publisher, func() Consumer) {
+		cfg := newTestConfig(BrokerMemory, "")
+		broker := NewMemoryBroker()
+		return func() Publisher { return broker.NewPublisher(cfg) },
+			func() Consumer { return broker.NewConsumer(cfg) }
+	})
+}
+
+// testBroker é o contrato de Publisher e Consumer, com a semântica de
+// exchange topic do RabbitMQ.
+func testBroker(t *testing.T, newBroker brokerFactory) {
+	ctx := context.Background()
+
+	setup := func(t *testing.T) (Publisher, func() Consumer) {
+		newPublisher, newConsumer := newBroker(t)
+		publisher := newPublisher()
+		t.Cleanup(func() { publisher.Close() })
+		return publisher, func() Consumer {
+			consumer := newConsumer()
+			t.Cleanup(func() { consumer.Close() })
+			return consumer
+		}
+	}
+
+	t.Run("Routing", func(t *testing.T) {
+		publisher, newConsumer := setup(t)
+
+		all, created := newRecorder(), newRecorder()
+		consumer := newConsumer()
+		if err := consumer.StartListening("", []string{"order.#"}, all.handle); err != nil {
+			t.Fatal(err)
+		}
+		// Os dois bindings casam com order.created, mas a fila recebe uma cópia só
+		if err := consumer.StartListening("created", []string{"order.created", "*.created"}, created.handle); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, eventType := range []string{"created", "status_changed", "cancelled"} {
+			if err := publisher.PublishOrderEvent(ctx, eventType, 1, map[string]any{"customer_id": 7}); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if got := all.wait(t, 3); strings.Join(got, ",") != "created,status_changed,cancelled" {
+			t.Errorf("order.# recebeu %v", got)
+		}
+		if got := created.wait(t, 1); got[0] != "created" {
+			t.Errorf("order.created recebeu %v", got)
+		}
+	})
+
+	t.Run("DurableQueue", func(t *testing.T) {
+		publisher, newConsumer := setup(t)
+
+		first := newRecorder()
+		consumer := newConsumer()
+		if err := consumer.StartListening("webhooks", []string{"order.*"}, first.handle); err != nil {
+			t.Fatal(err)
+		}
+		if err := consumer.StartListening("", []string{"order.*"}, first.handle); err != nil {
+			t.Fatal(err)
+		}
+		if err := consumer.Shutdown(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if err := consumer.Check(); err == nil {
+			t.Error("Check deveria falhar após o Shutdown")
+		}
+
+		// Sem consumer: a fila nomeada guarda os eventos; a temporária já não existe
+		publisher.PublishOrderEvent(ctx, "created", 1, nil)
+		publisher.PublishOrderEvent(ctx, "cancelled", 1, nil)
+
+		// Os bindings da fila continuam valendo sem serem repetidos
+		second := newRecorder()
+		if err := newConsumer().StartListening("webhooks", nil, second.handle); err != nil {
+			t.Fatal(err)
+		}
+		if got := second.wait(t, 2); strings.Join(got, ",") != "created,cancelled" {
+			t.Errorf("fila durável entregou %v", got)
+		}
+		if n := len(first.got); n != 0 {
+			t.Errorf("consumer encerrado recebeu %d eventos", n)
+		}
+	})
+
+	t.Run("NackRedelivers", func(t *testing.T) {
+		publisher, newConsumer := setup(t)
+
+		var mu sync.Mutex
+		attempts := map[string]int{}
+		done := make(chan struct{}, 10)
+		handler := func(_ context.Context, event OrderEvent) error {
+			mu.Lock()
+			defer mu.Unlock()
+			attempts[event.Type]++
+			if event.Type == "created" && attempts[event.Type] < 3 {
+				return errors.New("falha temporária")
+			}
+			done <- struct{}{}
+			return nil
+		}
+
+		if err := newConsumer().StartListening("retries", []string{"order.#"}, handler); err != nil {
+			t.Fatal(err)
+		}
+		publisher.PublishOrderEvents(ctx, []OrderEvent{{Type: "created", OrderID: 1}, {Type: "cancelled", OrderID: 1}})
+
+		// A nova entrega não espera o AckWait
+		for range 2 {
+			select {
+			case <-done:
+			case <-time.After(5 * time.Second):
+				mu.Lock()
+				defer mu.Unlock()
+				t.Fatalf("tentativas = %v", attempts)
+			}
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if attempts["created"] != 3 || attempts["cancelled"] != 1 {
+			t.Errorf("tentativas = %v, esperado created 3 e cancelled 1", attempts)
+		}
+	})
+
+	t.Run("Replay", func(t *testing.T) {
+		publisher, newConsumer := setup(t)
+
+		var mu sync.Mutex
+		replays := map[string][]string{}
+		got := make(chan struct{}, 10)
+		handlerFor := func(queue string) EventHandler {
+			return func(_ context.Context, event OrderEvent) error {
+				mu.Lock()
+				replay := event.Type + ":" + event.Replay
+				if event.ReplayQueue != "" {
+					replay += "@" + event.ReplayQueue
+				}
+				replays[queue] = append(replays[queue], replay)
+				mu.Unlock()
+				got <- struct{}{}
+				return nil
+			}
+		}
+		consumer := newConsumer()
+		for _, queue := range []string{"billing", "shipping"} {
+			if err := consumer.StartListening(queue, []string{"order.created", "replay.#"}, handlerFor(queue)); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		// Só a fila billing processa; a routing key é a do target
+		err := publisher.ReplayOrderEvents(ctx, []OrderEvent{{Type: "created", OrderID: 1}},
+			ReplayTarget{ID: "r1", RoutingKey: "replay.orders", Queue: "billing"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Sem fila, todas processam
+		publisher.ReplayOrderEvents(ctx, []OrderEvent{{Type: "created", OrderID: 2}}, ReplayTarget{ID: "r2"})
+		publisher.PublishOrderEvent(ctx, "created", 3, nil)
+
+		for range 5 {
+			select {
+			case <-got:
+			case <-time.After(5 * time.Second):
+				t.Fatalf("eventos não entregues: %v", replays)
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		mu.Lock()
+		defer mu.Unlock()
+		if got := strings.Join(replays["billing"], ","); got != "created:r1@billing,created:r2,created:" {
+			t.Errorf("billing recebeu %s", got)
+		}
+		if got := strings.Join(replays["shipping"], ","); got != "created:r2,created:" {
+			t.Errorf("shipping recebeu %s", got)
+		}
+	})
+
+	t.Run("BatchKeepsOrder", func(t *testing.T) {
+		publisher, newConsumer := setup(t)
+
+		received := newRecorder()
+		if err := newConsumer().StartListening("batch", []string{"order.*"}, received.handle); err != nil {
+			t.Fatal(err)
+		}
+
+		// Um evento que não serializa falha sozinho; os demais seguem em ordem
+		var events []OrderEvent
+		var want []string
+		for i := range 20 {
+			events = append(events, OrderEvent{Type: fmt.Sprintf("e%02d", i), OrderID: i})
+			want = append(want, events[i].Type)
+		}
+		events[10].Data = make(chan int)
+		want = slices.Delete(want, 10, 11)
+
+		err := publisher.PublishOrderEvents(ctx, events)
+		if err == nil || !strings.Contains(err.Error(), "order 10: failed to marshal event") || strings.Count(err.Error(), "order ") != 1 {
+			t.Fatalf("erro = %v, esperado só a falha do pedido 10", err)
+		}
+		for _, event := range events {
+			if event.ID == "" || event.OccurredAt.IsZero() {
+				t.Errorf("evento %s sem ID ou horário", event.Type)
+			}
+		}
+		if got := received.wait(t, len(want)); !slices.Equal(got, want) {
+			t.Errorf("ordem de entrega = %v, esperado %v", got, want)
+		}
+	})
+
+	t.Run("SharedQueue", func(t *testing.T) {
+		publisher, newConsumer := setup(t)
+
+		var mu sync.Mutex
+		received := map[int]int{}
+		got := make(chan struct{}, 100)
+		handler := func(_ context.Context, event OrderEvent) error {
+			mu.Lock()
+			received[event.OrderID]++
+			mu.Unlock()
+			got <- struct{}{}
+			return nil
+		}
+
+		for range 2 {
+			if err := newConsumer().StartListening("shared", []string{"order.created"}, handler); err != nil {
+				t.Fatal(err)
+			}
+		}
+		for id := range uint(10) {
+			publisher.PublishOrderEvent(ctx, "created", id, nil)
+		}
+
+		for range 10 {
+			select {
+			case <-got:
+			case <-time.After(5 * time.Second):
+				t.Fatal("eventos não entregues")
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		mu.Lock()
+		defer mu.Unlock()
+		for id, n := range received {
+			if n != 1 {
+				t.Errorf("pedido %d entregue %d vezes entre os consumers da fila", id, n)
+			}
+		}
+		if len(received) != 10 {
+			t.Errorf("%d pedidos entregues, esperado 10", len(received))
+		}
+	})
+}
+
+func TestMemoryBrokerPrefetch(t *testing.T) {
+	ctx := context.Background()
+	cfg := newTestConfig(BrokerMemory, "")
+	broker := NewMemoryBroker()
+	publisher := broker.NewPublisher(cfg)
+	defer publisher.Close()
+
+	// O primeiro consumer fica preso na primeira mensagem; com prefetch 1, as
+	// demais vão para o segundo em vez de esperar no buffer do primeiro.
+	release := make(chan struct{})
+	blocked := make(chan struct{}, 1)
+	slow := func(context.Context, OrderEvent) error {
+		blocked <- struct{}{}
+		<-release
+		return nil
+	}
+	fast := newRecorder()
+
+	first, second := broker.NewConsumer(cfg), broker.NewConsumer(cfg)
+	defer first.Close()
+	defer second.Close()
+	if err := first.StartListening("shared", []string{"order.#"}, slow); err != nil {
+		t.Fatal(err)
+	}
+	publisher.PublishOrderEvent(ctx, "created", 1, nil)
+	<-blocked
+
+	if err := second.StartListening("shared", []string{"order.#"}, fast.handle); err != nil {
+		t.Fatal(err)
+	}
+	for range 3 {
+		publisher.PublishOrderEvent(ctx, "status_changed", 1, nil)
+	}
+	if got := fast.wait(t, 3); len(got) != 3 {
+		t.Errorf("segundo consumer recebeu %v", got)
+	}
+	close(release)
+}
@@ -1,154 +1,171 @@
 package mq
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"order-service/internal/config"
-
-	amqp "github.com/rabbitmq/amqp091-go"
+	"order-service/pkg/tracing"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Consumer interface {
+	// StartListening declara a fila, a vincula ao exchange com as routing keys
+	// (padrões de tópico com * e #) e passa a entregar as mensagens a handler.
+	// Sem nome, a fila é exclusiva deste consumer e removida ao encerrar.
 	StartListening(queueName string, routingKeys []string, handler EventHandler) error
+	// Check retorna erro se a conexão ou o canal com o broker estiverem fechados.
+	Check() error
+	// Shutdown para de receber mensagens, espera as que estão em processamento
+	// (até o prazo de ctx) e fecha a conexão.
+	Shutdown(ctx context.Context) error
 	Close() error
 }
 
-type EventHandler func(event OrderEvent) error
+// EventHandler processa um evento. Um erro devolve a mensagem à fila para
+// ser entregue de novo, exceto os marcados com Permanent.
+type EventHandler func(ctx context.Context, event OrderEvent) error
 
-type consumer struct {
-	conn    *amqp.Connection
-	channel *amqp.Channel
-	config  *config.RabbitMQConfig
+// permanentError marca um erro que uma nova entrega não resolveria.
+type permanentError struct {
+	err error
 }
 
-func NewConsumer(cfg *config.RabbitMQConfig) (Consumer, error) {
-	conn, err := amqp.Dial(cfg.URL)
-	if err != nil {
-		return nil, fmt.Errorf("failed to connect to RabbitMQ: %w", err)
-	}
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
 
-	channel, err := conn.Channel()
-	if err != nil {
-		conn.Close()
-		return nil, fmt.Errorf("failed to open channel: %w", err)
+// Permanent marca err como permanente (mensagem malformada, evento que nunca
+// poderá ser aplicado): a mensagem é rejeitada sem voltar à fila, e o broker
+// a descarta ou a envia à dead-letter configurada na fila.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
 	}
+	return &permanentError{err: err}
+}
 
-	c := &consumer{
-		conn:    conn,
-		channel: channel,
-		config:  cfg,
-	}
+// IsPermanent indica se err (ou algum erro que ele embrulha) foi marcado com
+// Permanent.
+func IsPermanent(err error) bool {
+	var permanent *permanentError
+	return errors.As(err, &permanent)
+}
 
-	// Declarar exchange (caso não exista)
-	if err := c.setupExchange(); err != nil {
-		c.Close()
-		return nil, err
+// NewConsumer conecta ao broker escolhido em cfg.Broker.
+func NewConsumer(cfg *config.MQConfig) (Consumer, error) {
+	switch cfg.Broker {
+	case BrokerMemory:
+		slog.Info("Consumer conectado ao broker em memória", "exchange", cfg.Exchange)
+		return defaultMemoryBroker.NewConsumer(cfg), nil
+	case BrokerNATS:
+		return newNATSConsumer(cfg)
+	case BrokerRedis:
+		return newRedisConsumer(cfg)
+	default:
+		return newRabbitMQConsumer(cfg)
 	}
-
-	log.Printf("RabbitMQ Consumer conectado")
-	return c, nil
 }
 
-func (c *consumer) setupExchange() error {
-	return c.channel.ExchangeDeclare(
-		c.config.Exchange, // name
-		"topic",           // type
-		true,              // durable
-		false,             // auto-deleted
-		false,             // internal
-		false,             // no-wait
-		nil,               // arguments
-	)
+// delivery é uma mensagem recebida, com a confirmação no broker de origem.
+type delivery struct {
+	message
+	Redelivered bool
+	Ack         func() error
+	Nack        func(requeue bool) error
 }
 
-func (c *consumer) StartListening(queueName string, routingKeys []string, handler EventHandler) error {
-	// Declarar fila
-	queue, err := c.channel.QueueDeclare(
-		queueName, // name
-		true,      // durable
-		false,     // delete when unused
-		false,     // exclusive
-		false,     // no-wait
-		nil,       // arguments
-	)
-	if err != nil {
-		return fmt.Errorf("failed to declare queue: %w", err)
+// handleDelivery processa uma mensagem e a confirma: ack em caso de sucesso,
+// nack com reenvio à fila em caso de erro transitório e nack sem reenvio em
+// caso de erro permanente. label identifica a fila nas métricas (filas
+// temporárias têm nome novo a cada conexão).
+func handleDelivery(d delivery, queue, label string, attrs []attribute.KeyValue, handler EventHandler) {
+	// Replay direcionado a outra fila
+	if target, _ := d.Headers[HeaderReplayQueue].(string); target != "" && target != queue {
+		d.Ack()
+		return
 	}
 
-	// Bind fila ao exchange com routing keys
-	for _, routingKey := range routingKeys {
-		err := c.channel.QueueBind(
-			queue.Name,        // queue name
-			routingKey,        // routing key
-			c.config.Exchange, // exchange
-			false,
-			nil,
-		)
-		if err != nil {
-			return fmt.Errorf("failed to bind queue: %w", err)
-		}
-		log.Printf("Fila '%s' vinculada ao routing key: %s", queueName, routingKey)
+	if !d.Timestamp.IsZero() {
+		consumerLag.WithLabelValues(label).Observe(time.Since(d.Timestamp).Seconds())
 	}
 
-	// Configurar QoS
-	err = c.channel.Qos(
-		1,     // prefetch count
-		0,     // prefetch size
-		false, // global
-	)
-	if err != nil {
-		return fmt.Errorf("failed to set QoS: %w", err)
+	start := time.Now()
+	err := processMessage(d, attrs, handler)
+	handlerDuration.WithLabelValues(label).Observe(time.Since(start).Seconds())
+
+	switch {
+	case IsPermanent(err):
+		slog.Error("Mensagem rejeitada sem reenvio",
+			"queue", queue, "routing_key", d.RoutingKey, "message_id", d.ID, "redelivered", d.Redelivered, "error", err)
+		d.Nack(false) // Reenviar não adianta: descartar ou mandar para a dead-letter
+		messagesRejected.WithLabelValues(label).Inc()
+	case err != nil:
+		slog.Error("Erro ao processar mensagem",
+			"queue", queue, "routing_key", d.RoutingKey, "message_id", d.ID, "redelivered", d.Redelivered, "error", err)
+		d.Nack(true) // Rejeitar e reenviar para fila
+		messagesNacked.WithLabelValues(label).Inc()
+	default:
+		d.Ack()
+		messagesAcked.WithLabelValues(label).Inc()
 	}
+}
 
-	// Consumir mensagens
-	msgs, err := c.channel.Consume(
-		queue.Name, // queue
-		"",         // consumer
-		false,      // auto-ack
-		false,      // exclusive
-		false,      // no-local
-		false,      // no-wait
-		nil,        // args
+func processMessage(d delivery, attrs []attribute.KeyValue, handler EventHandler) (err error) {
+	// Continua o trace iniciado na publicação (headers traceparent/tracestate)
+	ctx := otel.GetTextMapPropagator().Extract(context.Background(), headerCarrier(d.Headers))
+	ctx, span := tracing.Tracer().Start(ctx, d.RoutingKey+" process",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(attrs...),
+		trace.WithAttributes(
+			semconv.MessagingOperationTypeProcess,
+			semconv.MessagingMessageID(d.ID),
+		),
 	)
-	if err != nil {
-		return fmt.Errorf("failed to register consumer: %w", err)
-	}
-
-	log.Printf("Escutando mensagens na fila: %s", queueName)
-
-	// Processar mensagens em goroutine
-	go func() {
-		for msg := range msgs {
-			if err := c.processMessage(msg, handler); err != nil {
-				log.Printf("Erro ao processar mensagem: %v", err)
-				msg.Nack(false, true) // Rejeitar e reenviar para fila
-			} else {
-				msg.Ack(false)
-			}
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
 		}
+		span.End()
 	}()
 
-	return nil
-}
-
-func (c *consumer) processMessage(msg amqp.Delivery, handler EventHandler) error {
 	var event OrderEvent
-	if err := json.Unmarshal(msg.Body, &event); err != nil {
-		return fmt.Errorf("failed to unmarshal event: %w", err)
+	if err := json.Unmarshal(d.Body, &event); err != nil {
+		return Permanent(fmt.Errorf("failed to unmarshal event: %w", err))
+	}
+	if event.ID == "" {
+		event.ID = d.ID
 	}
+	event.Replay, _ = d.Headers[HeaderReplay].(string)
+	event.ReplayQueue, _ = d.Headers[HeaderReplayQueue].(string)
+	event.RoutingKey = d.RoutingKey
 
-	log.Printf("Evento recebido: %s (Order ID: %d)", event.Type, event.OrderID)
+	slog.DebugContext(ctx, "Evento recebido", "event_type", event.Type, "order_id", event.OrderID, "event_id", event.ID)
 
-	return handler(event)
+	return handler(ctx, event)
 }
 
-func (c *consumer) Close() error {
-	if c.channel != nil {
-		c.channel.Close()
-	}
-	if c.conn != nil {
-		c.conn.Close()
+// wait espera wg zerar, até o prazo de ctx.
+func wait(ctx context.Context, wg *sync.WaitGroup) error {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
 	}
-	return nil
 }
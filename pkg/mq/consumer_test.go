@@ -0,0 +1,72 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"order-service/pkg/metrics/metricstest"
+)
+
+const metricRejected = "order_service_mq_messages_rejected_total"
+
+func TestHandleDelivery(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		err     error
+		want    string
+		counter string
+	}{
+		{"sucesso", `{"type": "created", "order_id": 1}`, nil, "ack", metricAcked},
+		{"erro transitório", `{"type": "created", "order_id": 1}`, errors.New("connection refused"), "nack com reenvio", metricNacked},
+		{"erro permanente", `{"type": "created", "order_id": 1}`, fmt.Errorf("failed to apply event: %w", Permanent(errors.New("pedido inválido"))), "nack sem reenvio", metricRejected},
+		{"corpo malformado", `{"type":`, nil, "nack sem reenvio", metricRejected},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := metricstest.CounterValue(t, tt.counter, "queue", "handle_delivery")
+
+			var got string
+			d := delivery{
+				message: message{ID: "m1", RoutingKey: "order.created", Body: []byte(tt.body)},
+				Ack: func() error {
+					got = "ack"
+					return nil
+				},
+				Nack: func(requeue bool) error {
+					got = "nack sem reenvio"
+					if requeue {
+						got = "nack com reenvio"
+					}
+					return nil
+				},
+			}
+			handleDelivery(d, "handle_delivery", "handle_delivery", nil, func(context.Context, OrderEvent) error {
+				return tt.err
+			})
+
+			if got != tt.want {
+				t.Errorf("confirmação = %q, esperado %q", got, tt.want)
+			}
+			if after := metricstest.CounterValue(t, tt.counter, "queue", "handle_delivery"); after != before+1 {
+				t.Errorf("%s = %v, esperado %v", tt.counter, after, before+1)
+			}
+		})
+	}
+}
+
+func TestPermanent(t *testing.T) {
+	if Permanent(nil) != nil {
+		t.Error("Permanent(nil) != nil")
+	}
+	cause := errors.New("pedido inválido")
+	err := fmt.Errorf("failed to apply event: %w", Permanent(cause))
+	if !IsPermanent(err) || !errors.Is(err, cause) {
+		t.Errorf("IsPermanent = %v, errors.Is = %v, esperado true", IsPermanent(err), errors.Is(err, cause))
+	}
+	if IsPermanent(cause) {
+		t.Error("erro sem marca reconhecido como permanente")
+	}
+}
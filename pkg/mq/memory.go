@@ -0,0 +1,396 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"order-service/internal/config"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+)
+
+// errMemoryClosed é o erro de Check e das operações após o Close, como o de
+// uma conexão fechada no RabbitMQ.
+var errMemoryClosed = errors.New("connection closed")
+
+// defaultMemoryBroker é o broker compartilhado por NewPublisher e
+// NewConsumer com BrokerMemory.
+var defaultMemoryBroker = NewMemoryBroker()
+
+// MemoryBroker é um broker de mensagens dentro do processo com a semântica
+// dos exchanges topic do RabbitMQ, para desenvolvimento local e testes de
+// integração sem RabbitMQ:
+//
+//   - routing keys separadas por ponto, com * (exatamente uma palavra) e #
+//     (zero ou mais palavras) nos bindings; cada fila recebe uma cópia da
+//     mensagem, mesmo que mais de um binding case, e mensagens sem fila são
+//     descartadas
+//   - filas nomeadas são duráveis enquanto o processo existir: guardam as
+//     mensagens sem consumer e são compartilhadas (round-robin) entre os
+//     consumers que as escutam; filas sem nome são exclusivas e removidas
+//     junto com o consumer
+//   - cada consumer recebe até Prefetch mensagens sem ack; nack devolve a
+//     mensagem ao início da fila, marcada como reentregue, e as não
+//     processadas voltam à fila quando o consumer é fechado
+//
+// As mensagens passam pelo mesmo envelope JSON do RabbitMQ, então os
+// handlers recebem os eventos como chegariam do broker real.
+type MemoryBroker struct {
+	mu     sync.Mutex
+	queues map[string]*memoryQueue
+	// Bindings por exchange
+	bindings  map[string][]memoryBinding
+	nextQueue int
+}
+
+type memoryBinding struct {
+	pattern []string
+	queue   *memoryQueue
+}
+
+type memoryQueue struct {
+	name      string
+	temporary bool
+	ready     []*memoryMessage
+	consumers []*memorySubscription
+	// Próximo consumer no round-robin
+	next int
+}
+
+type memoryMessage struct {
+	message
+	redelivered bool
+}
+
+// memorySubscription é um consumer de uma fila; deliveries tem capacidade
+// prefetch, então a entrega nunca bloqueia o broker.
+type memorySubscription struct {
+	queue      *memoryQueue
+	prefetch   int
+	unacked    int
+	deliveries chan *memoryMessage
+}
+
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{
+		queues:   make(map[string]*memoryQueue),
+		bindings: make(map[string][]memoryBinding),
+	}
+}
+
+// NewPublisher cria um Publisher que publica no exchange cfg.Exchange deste
+// broker, com o mesmo PublishTimeout do publisher do RabbitMQ.
+func (b *MemoryBroker) NewPublisher(cfg *config.MQConfig) Publisher {
+	return &publisher{
+		transport: &memoryTransport{broker: b, exchange: cfg.Exchange},
+		config:    cfg,
+	}
+}
+
+// NewConsumer cria um Consumer das filas deste broker vinculadas ao exchange
+// cfg.Exchange, com prefetch cfg.Prefetch.
+func (b *MemoryBroker) NewConsumer(cfg *config.MQConfig) Consumer {
+	return &memoryConsumer{broker: b, config: cfg}
+}
+
+func (b *MemoryBroker) publish(exchange string, msg message) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	words := strings.Split(msg.RoutingKey, ".")
+	var matched []*memoryQueue
+	for _, binding := range b.bindings[exchange] {
+		if !slices.Contains(matched, binding.queue) && topicMatches(binding.pattern, words) {
+			matched = append(matched, binding.queue)
+		}
+	}
+
+	for _, queue := range matched {
+		queue.ready = append(queue.ready, &memoryMessage{message: msg})
+		b.dispatch(queue)
+	}
+}
+
+// subscribe declara a fila (com nome gerado, se vazio), a vincula ao
+// exchange e registra um consumer.
+func (b *MemoryBroker) subscribe(exchange, queueName string, routingKeys []string, prefetch int) *memorySubscription {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	temporary := queueName == ""
+	if temporary {
+		b.nextQueue++
+		queueName = fmt.Sprintf("amq.gen-%d", b.nextQueue)
+	}
+	queue, ok := b.queues[queueName]
+	if !ok {
+		queue = &memoryQueue{name: queueName, temporary: temporary}
+		b.queues[queueName] = queue
+	}
+
+	for _, routingKey := range routingKeys {
+		pattern := strings.Split(routingKey, ".")
+		bound := slices.ContainsFunc(b.bindings[exchange], func(binding memoryBinding) bool {
+			return binding.queue == queue && slices.Equal(binding.pattern, pattern)
+		})
+		if !bound {
+			b.bindings[exchange] = append(b.bindings[exchange], memoryBinding{pattern: pattern, queue: queue})
+		}
+	}
+
+	sub := &memorySubscription{
+		queue:      queue,
+		prefetch:   prefetch,
+		deliveries: make(chan *memoryMessage, prefetch),
+	}
+	queue.consumers = append(queue.consumers, sub)
+	b.dispatch(queue)
+	return sub
+}
+
+// cancel para as entregas ao consumer e fecha deliveries; as mensagens já
+// entregues continuam aguardando ack ou nack. Uma fila temporária é removida
+// junto com o seu último consumer.
+func (b *MemoryBroker) cancel(sub *memorySubscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	queue := sub.queue
+	i := slices.Index(queue.consumers, sub)
+	if i < 0 {
+		return
+	}
+	queue.consumers = slices.Delete(queue.consumers, i, i+1)
+	close(sub.deliveries)
+
+	if queue.temporary && len(queue.consumers) == 0 {
+		delete(b.queues, queue.name)
+		for exchange, bindings := range b.bindings {
+			b.bindings[exchange] = slices.DeleteFunc(bindings, func(binding memoryBinding) bool {
+				return binding.queue == queue
+			})
+		}
+	}
+}
+
+func (b *MemoryBroker) ack(sub *memorySubscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	sub.unacked--
+	b.dispatch(sub.queue)
+}
+
+// nack libera a vaga de prefetch e, com requeue, devolve a mensagem ao
+// início da fila, como o RabbitMQ.
+func (b *MemoryBroker) nack(sub *memorySubscription, msg *memoryMessage, requeue bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	sub.unacked--
+	if requeue {
+		msg.redelivered = true
+		sub.queue.ready = slices.Insert(sub.queue.ready, 0, msg)
+	}
+	b.dispatch(sub.queue)
+}
+
+// dispatch entrega as mensagens prontas aos consumers com vaga de prefetch,
+// em round-robin. Chamado com b.mu travado.
+func (b *MemoryBroker) dispatch(queue *memoryQueue) {
+	for len(queue.ready) > 0 {
+		sub := queue.nextConsumer()
+		if sub == nil {
+			return
+		}
+		msg := queue.ready[0]
+		queue.ready = queue.ready[1:]
+		sub.unacked++
+		sub.deliveries <- msg
+	}
+}
+
+func (q *memoryQueue) nextConsumer() *memorySubscription {
+	for range q.consumers {
+		sub := q.consumers[q.next%len(q.consumers)]
+		q.next++
+		if sub.unacked < sub.prefetch {
+			return sub
+		}
+	}
+	return nil
+}
+
+// topicMatches aplica um binding de exchange topic a uma routing key, ambos
+// já separados em palavras.
+func topicMatches(pattern, words []string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case "#":
+			for i := range len(words) + 1 {
+				if topicMatches(pattern[1:], words[i:]) {
+					return true
+				}
+			}
+			return false
+		case "*":
+			if len(words) == 0 {
+				return false
+			}
+		default:
+			if len(words) == 0 || words[0] != pattern[0] {
+				return false
+			}
+		}
+		pattern, words = pattern[1:], words[1:]
+	}
+	return len(words) == 0
+}
+
+// memoryTransport publica em um exchange do MemoryBroker.
+type memoryTransport struct {
+	broker   *MemoryBroker
+	exchange string
+	closed   atomic.Bool
+}
+
+func (t *memoryTransport) attributes(string) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		semconv.MessagingSystemKey.String(BrokerMemory),
+		semconv.MessagingDestinationName(t.exchange),
+	}
+}
+
+func (t *memoryTransport) send(_ context.Context, msg message) error {
+	if t.closed.Load() {
+		return errMemoryClosed
+	}
+	t.broker.publish(t.exchange, msg)
+	return nil
+}
+
+func (t *memoryTransport) check() error {
+	if t.closed.Load() {
+		return errMemoryClosed
+	}
+	return nil
+}
+
+func (t *memoryTransport) close() error {
+	t.closed.Store(true)
+	return nil
+}
+
+type memoryConsumer struct {
+	broker *MemoryBroker
+	config *config.MQConfig
+
+	mu     sync.Mutex
+	subs   []*memorySubscription
+	closed bool
+	// Goroutines de processamento ainda ativas
+	wg sync.WaitGroup
+}
+
+func (c *memoryConsumer) StartListening(queueName string, routingKeys []string, handler EventHandler) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return fmt.Errorf("failed to declare queue: %w", errMemoryClosed)
+	}
+
+	sub := c.broker.subscribe(c.config.Exchange, queueName, routingKeys, max(c.config.Prefetch, 1))
+	c.subs = append(c.subs, sub)
+
+	queue := sub.queue.name
+	slog.Info("Escutando mensagens", "queue", queue, "broker", BrokerMemory)
+
+	// Mesmo label das filas exclusivas do RabbitMQ
+	label := queue
+	if sub.queue.temporary {
+		label = "exclusive"
+	}
+	attrs := []attribute.KeyValue{
+		semconv.MessagingSystemKey.String(BrokerMemory),
+		semconv.MessagingDestinationName(queue),
+	}
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		for msg := range sub.deliveries {
+			// Após o Close, o que já estava no prefetch volta para a fila
+			if c.isClosed() {
+				c.broker.nack(sub, msg, true)
+				continue
+			}
+			handleDelivery(delivery{
+				message:     msg.message,
+				Redelivered: msg.redelivered,
+				Ack: func() error {
+					c.broker.ack(sub)
+					return nil
+				},
+				Nack: func(requeue bool) error {
+					c.broker.nack(sub, msg, requeue)
+					return nil
+				},
+			}, queue, label, attrs, handler)
+		}
+	}()
+
+	return nil
+}
+
+func (c *memoryConsumer) isClosed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.closed
+}
+
+func (c *memoryConsumer) Check() error {
+	if c.isClosed() {
+		return errMemoryClosed
+	}
+	return nil
+}
+
+// Shutdown cancela o consumo de todas as filas e espera as mensagens já
+// recebidas serem processadas, como o consumer do RabbitMQ.
+func (c *memoryConsumer) Shutdown(ctx context.Context) error {
+	c.cancelAll()
+
+	err := wait(ctx, &c.wg)
+	if err != nil {
+		err = fmt.Errorf("consumer drain: %w", err)
+	}
+
+	c.Close()
+	return err
+}
+
+func (c *memoryConsumer) Close() error {
+	c.mu.Lock()
+	c.closed = true
+	c.mu.Unlock()
+
+	c.cancelAll()
+	return nil
+}
+
+func (c *memoryConsumer) cancelAll() {
+	c.mu.Lock()
+	subs := c.subs
+	c.subs = nil
+	c.mu.Unlock()
+
+	for _, sub := range subs {
+		c.broker.cancel(sub)
+	}
+}
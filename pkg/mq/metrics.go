@@ -0,0 +1,62 @@
+package mq
+
+import (
+	"order-service/pkg/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	publishDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "mq",
+		Name:      "publish_duration_seconds",
+		Help:      "Latência de publicação no exchange por tipo de evento.",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+	}, []string{"event_type"})
+
+	publishFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "mq",
+		Name:      "publish_failures_total",
+		Help:      "Eventos que não puderam ser publicados.",
+	}, []string{"event_type"})
+
+	consumerLag = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "mq",
+		Name:      "consumer_lag_seconds",
+		Help:      "Tempo entre a publicação do evento e o início do processamento.",
+		Buckets:   []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60},
+	}, []string{"queue"})
+
+	handlerDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "mq",
+		Name:      "handler_duration_seconds",
+		Help:      "Duração do processamento de cada mensagem.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"queue"})
+
+	messagesAcked = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "mq",
+		Name:      "messages_acked_total",
+		Help:      "Mensagens confirmadas (ack).",
+	}, []string{"queue"})
+
+	messagesNacked = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "mq",
+		Name:      "messages_nacked_total",
+		Help:      "Mensagens rejeitadas (nack) e devolvidas à fila.",
+	}, []string{"queue"})
+
+	messagesRejected = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "mq",
+		Name:      "messages_rejected_total",
+		Help:      "Mensagens rejeitadas sem reenvio (erro permanente).",
+	}, []string{"queue"})
+)
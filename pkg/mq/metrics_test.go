@@ -0,0 +1,93 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"order-service/pkg/metrics/metricstest"
+)
+
+const (
+	metricPublishDuration = "order_service_mq_publish_duration_seconds"
+	metricHandlerDuration = "order_service_mq_handler_duration_seconds"
+	metricConsumerLag     = "order_service_mq_consumer_lag_seconds"
+	metricAcked           = "order_service_mq_messages_acked_total"
+	metricNacked          = "order_service_mq_messages_nacked_total"
+)
+
+// eventually repete cond até ela valer ou o prazo acabar: as métricas do
+// consumer são registradas depois que o handler retorna.
+func eventually(t *testing.T, cond func() bool) bool {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	return true
+}
+
+func TestBrokerMetrics(t *testing.T) {
+	ctx := context.Background()
+	cfg := newTestConfig(BrokerMemory, "")
+	broker := NewMemoryBroker()
+	publisher := broker.NewPublisher(cfg)
+	consumer := broker.NewConsumer(cfg)
+	t.Cleanup(func() {
+		consumer.Close()
+		publisher.Close()
+	})
+
+	published := metricstest.HistogramCount(t, metricPublishDuration, "event_type", "metrics_test")
+	handled := metricstest.HistogramCount(t, metricHandlerDuration, "queue", "metrics")
+	lag := metricstest.HistogramCount(t, metricConsumerLag, "queue", "metrics")
+	acked := metricstest.CounterValue(t, metricAcked, "queue", "metrics")
+	nacked := metricstest.CounterValue(t, metricNacked, "queue", "metrics")
+
+	// Falha na primeira entrega e processa na segunda
+	attempts := 0
+	done := make(chan struct{}, 1)
+	err := consumer.StartListening("metrics", []string{"order.metrics_test"}, func(context.Context, OrderEvent) error {
+		attempts++
+		if attempts == 1 {
+			return errors.New("falha temporária")
+		}
+		done <- struct{}{}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := publisher.PublishOrderEvent(ctx, "metrics_test", 1, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("evento não reprocessado")
+	}
+
+	if got := metricstest.HistogramCount(t, metricPublishDuration, "event_type", "metrics_test"); got != published+1 {
+		t.Errorf("%d publicações medidas, esperado %d", got, published+1)
+	}
+	if !eventually(t, func() bool {
+		return metricstest.CounterValue(t, metricAcked, "queue", "metrics") == acked+1
+	}) {
+		t.Errorf("acks = %v, esperado %v", metricstest.CounterValue(t, metricAcked, "queue", "metrics"), acked+1)
+	}
+	if got := metricstest.CounterValue(t, metricNacked, "queue", "metrics"); got != nacked+1 {
+		t.Errorf("nacks = %v, esperado %v", got, nacked+1)
+	}
+	// Cada entrega mede a duração do handler e o atraso desde a publicação
+	if got := metricstest.HistogramCount(t, metricHandlerDuration, "queue", "metrics"); got != handled+2 {
+		t.Errorf("%d execuções do handler medidas, esperado %d", got, handled+2)
+	}
+	if got := metricstest.HistogramCount(t, metricConsumerLag, "queue", "metrics"); got != lag+2 {
+		t.Errorf("%d medições de lag, esperado %d", got, lag+2)
+	}
+}
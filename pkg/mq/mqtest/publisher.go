@@ -0,0 +1,138 @@
+// Package mqtest traz dublês de pkg/mq para testes sem RabbitMQ.
+package mqtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"order-service/pkg/mq"
+)
+
+// Publisher é um mq.Publisher que guarda os eventos publicados em memória.
+// Err, se definido, faz as publicações falharem sem registrar nada.
+type Publisher struct {
+	mu      sync.Mutex
+	events  []mq.OrderEvent
+	targets []mq.ReplayTarget
+	calls   int
+	nextID  int
+	closed  bool
+	Err     error
+}
+
+var _ mq.Publisher = (*Publisher)(nil)
+
+func NewPublisher() *Publisher {
+	return &Publisher{}
+}
+
+func (p *Publisher) PublishOrderEvent(ctx context.Context, eventType string, orderID uint, data any) error {
+	return p.PublishOrderEvents(ctx, []mq.OrderEvent{{Type: eventType, OrderID: int(orderID), Data: data}})
+}
+
+func (p *Publisher) PublishOrderEvents(ctx context.Context, events []mq.OrderEvent) error {
+	return p.ReplayOrderEvents(ctx, events, mq.ReplayTarget{})
+}
+
+// ReplayOrderEvents registra os eventos com Replay preenchido pelo ID do
+// replay, e o destino de cada um em Targets.
+func (p *Publisher) ReplayOrderEvents(ctx context.Context, events []mq.OrderEvent, target mq.ReplayTarget) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	switch {
+	case p.closed:
+		return mq.ErrPublisherClosed
+	case ctx.Err() != nil:
+		return fmt.Errorf("failed to publish event: %w", ctx.Err())
+	case p.Err != nil:
+		return p.Err
+	}
+
+	for _, event := range events {
+		// Como o publisher real: ID e horário preenchidos quando ausentes
+		if event.ID == "" {
+			p.nextID++
+			event.ID = fmt.Sprintf("evt-%d", p.nextID)
+		}
+		if event.OccurredAt.IsZero() {
+			event.OccurredAt = time.Now().UTC()
+		}
+		event.Replay = target.ID
+		p.events = append(p.events, event)
+		p.targets = append(p.targets, target)
+	}
+	p.calls++
+	return nil
+}
+
+// PublishTo registra o evento com a routing key em Targets.
+func (p *Publisher) PublishTo(ctx context.Context, routingKey string, event mq.OrderEvent) error {
+	return p.ReplayOrderEvents(ctx, []mq.OrderEvent{event}, mq.ReplayTarget{RoutingKey: routingKey})
+}
+
+// Events devolve os eventos publicados, na ordem.
+func (p *Publisher) Events() []mq.OrderEvent {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return slices.Clone(p.events)
+}
+
+// Targets devolve o destino de cada evento de Events (vazio nas publicações
+// normais; só RoutingKey em PublishTo).
+func (p *Publisher) Targets() []mq.ReplayTarget {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return slices.Clone(p.targets)
+}
+
+// Calls devolve quantas publicações deram certo; um lote conta como uma.
+func (p *Publisher) Calls() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.calls
+}
+
+// EventsOfType devolve os eventos publicados do tipo informado (ex.: "created").
+func (p *Publisher) EventsOfType(eventType string) []mq.OrderEvent {
+	var events []mq.OrderEvent
+	for _, event := range p.Events() {
+		if event.Type == eventType {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// Reset descarta os eventos registrados.
+func (p *Publisher) Reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.events = nil
+	p.targets = nil
+	p.calls = 0
+}
+
+func (p *Publisher) Check() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return errors.New("connection closed")
+	}
+	return nil
+}
+
+func (p *Publisher) Shutdown(context.Context) error {
+	return p.Close()
+}
+
+func (p *Publisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closed = true
+	return nil
+}
@@ -0,0 +1,436 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"order-service/internal/config"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+)
+
+// No NATS JetStream, o exchange é uma stream com os subjects
+// <exchange>.<routing key>, e cada fila é um consumer da stream: durável
+// (compartilhado entre as réplicas) quando tem nome, efêmero quando não tem.
+// Os bindings viram filtros de subject, com # traduzido para >.
+
+// natsConn é a conexão e o contexto JetStream, com a stream já declarada.
+type natsConn struct {
+	conn   *nats.Conn
+	js     jetstream.JetStream
+	config *config.MQConfig
+}
+
+func dialNATS(cfg *config.MQConfig) (*natsConn, error) {
+	conn, err := nats.Connect(cfg.URL,
+		nats.Name("order-service"),
+		nats.MaxReconnects(-1),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to NATS: %w", err)
+	}
+
+	js, err := jetstream.New(conn)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to open JetStream: %w", err)
+	}
+
+	c := &natsConn{conn: conn, js: js, config: cfg}
+
+	// Declarar stream (caso não exista)
+	if err := c.setupStream(); err != nil {
+		c.close()
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *natsConn) setupStream() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := c.js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:     c.config.Exchange,
+		Subjects: []string{c.config.Exchange + ".>"},
+		Storage:  jetstream.FileStorage,
+		MaxAge:   c.config.Retention,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to declare stream: %w", err)
+	}
+	return nil
+}
+
+func (c *natsConn) check() error {
+	if status := c.conn.Status(); status != nats.CONNECTED {
+		return fmt.Errorf("connection %s", strings.ToLower(status.String()))
+	}
+	return nil
+}
+
+func (c *natsConn) close() error {
+	c.conn.Close()
+	return nil
+}
+
+// natsTransport publica na stream do exchange.
+type natsTransport struct {
+	*natsConn
+}
+
+func newNATSTransport(cfg *config.MQConfig) (*natsTransport, error) {
+	conn, err := dialNATS(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &natsTransport{conn}, nil
+}
+
+func (t *natsTransport) attributes(routingKey string) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		semconv.MessagingSystemKey.String(BrokerNATS),
+		semconv.MessagingDestinationName(t.config.Exchange + "." + routingKey),
+	}
+}
+
+func (t *natsTransport) send(ctx context.Context, msg message) error {
+	// O ID também evita duplicatas na stream em caso de nova tentativa
+	_, err := t.js.PublishMsg(ctx, t.natsMsg(msg), jetstream.WithMsgID(msg.ID))
+	return err
+}
+
+var _ batchTransport = (*natsTransport)(nil)
+
+// sendBatch publica as mensagens sem esperar o ack de cada uma e depois
+// espera todos, até o prazo de ctx.
+func (t *natsTransport) sendBatch(ctx context.Context, msgs []message) []error {
+	errs := make([]error, len(msgs))
+	futures := make([]jetstream.PubAckFuture, len(msgs))
+	for i, msg := range msgs {
+		futures[i], errs[i] = t.js.PublishMsgAsync(t.natsMsg(msg), jetstream.WithMsgID(msg.ID))
+	}
+
+	for i, future := range futures {
+		if future == nil {
+			continue
+		}
+		select {
+		case <-future.Ok():
+		case err := <-future.Err():
+			errs[i] = err
+		case <-ctx.Done():
+			errs[i] = ctx.Err()
+		}
+	}
+	return errs
+}
+
+func (t *natsTransport) natsMsg(msg message) *nats.Msg {
+	header := nats.Header{}
+	for key, value := range msg.Headers {
+		header.Set(key, fmt.Sprint(value))
+	}
+	return &nats.Msg{
+		Subject: t.config.Exchange + "." + msg.RoutingKey,
+		Header:  header,
+		Data:    msg.Body,
+	}
+}
+
+type natsConsumer struct {
+	*natsConn
+
+	mu        sync.Mutex
+	iterators []jetstream.MessagesContext
+	// Consumers efêmeros, removidos no Shutdown
+	ephemeral []string
+	// Goroutines de processamento ainda ativas
+	wg sync.WaitGroup
+}
+
+func newNATSConsumer(cfg *config.MQConfig) (Consumer, error) {
+	conn, err := dialNATS(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("NATS Consumer conectado", "stream", cfg.Exchange)
+	return &natsConsumer{natsConn: conn}, nil
+}
+
+func (c *natsConsumer) StartListening(queueName string, routingKeys []string, handler EventHandler) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Sem nome, o consumer é efêmero: cada réplica recebe todos os eventos e
+	// o servidor o remove se a réplica sumir sem Shutdown.
+	temporary := queueName == ""
+
+	// Como os bindings de uma fila do RabbitMQ, as routing keys se somam às
+	// já registradas no consumer durável (guardadas nos metadados)
+	if !temporary {
+		existing, err := c.js.Consumer(ctx, c.config.Exchange, queueName)
+		switch {
+		case err == nil:
+			registered := existing.CachedInfo().Config.Metadata["routing_keys"]
+			routingKeys = mergeRoutingKeys(strings.Split(registered, ","), routingKeys)
+		case !errors.Is(err, jetstream.ErrConsumerNotFound):
+			return fmt.Errorf("failed to declare consumer: %w", err)
+		}
+	}
+
+	consumerConfig := jetstream.ConsumerConfig{
+		Durable:        queueName,
+		FilterSubjects: natsFilters(c.config.Exchange, routingKeys),
+		DeliverPolicy:  jetstream.DeliverNewPolicy,
+		AckPolicy:      jetstream.AckExplicitPolicy,
+		AckWait:        c.config.AckWait,
+		MaxAckPending:  c.config.Prefetch,
+		Metadata:       map[string]string{"routing_keys": strings.Join(routingKeys, ",")},
+	}
+	if temporary {
+		consumerConfig.InactiveThreshold = time.Minute
+	}
+
+	consumer, err := c.js.CreateOrUpdateConsumer(ctx, c.config.Exchange, consumerConfig)
+	if err != nil {
+		return fmt.Errorf("failed to declare consumer: %w", err)
+	}
+	name := consumer.CachedInfo().Name
+
+	msgs, err := consumer.Messages(jetstream.PullMaxMessages(c.config.Prefetch))
+	if err != nil {
+		return fmt.Errorf("failed to register consumer: %w", err)
+	}
+
+	c.mu.Lock()
+	c.iterators = append(c.iterators, msgs)
+	if temporary {
+		c.ephemeral = append(c.ephemeral, name)
+	}
+	c.mu.Unlock()
+
+	slog.Info("Escutando mensagens", "queue", name, "broker", BrokerNATS)
+
+	// Mesmo label das filas exclusivas do RabbitMQ
+	label := name
+	if temporary {
+		label = "exclusive"
+	}
+	patterns := topicPatterns(routingKeys)
+	prefix := c.config.Exchange + "."
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		for {
+			msg, err := msgs.Next()
+			if errors.Is(err, jetstream.ErrMsgIteratorClosed) {
+				return
+			}
+			if err != nil {
+				slog.Warn("Erro ao receber mensagem", "queue", name, "error", err)
+				continue
+			}
+
+			routingKey := strings.TrimPrefix(msg.Subject(), prefix)
+			// Filtros sobrepostos são unificados no servidor; o restante é
+			// filtrado aqui, com a semântica de tópicos do RabbitMQ
+			if !matchesAny(patterns, routingKey) {
+				msg.Ack()
+				continue
+			}
+
+			headers := make(map[string]any, len(msg.Headers()))
+			for key := range msg.Headers() {
+				headers[key] = msg.Headers().Get(key)
+			}
+			d := delivery{
+				message: message{
+					ID:         msg.Headers().Get(nats.MsgIdHdr),
+					RoutingKey: routingKey,
+					Headers:    headers,
+					Body:       msg.Data(),
+				},
+				Ack: msg.Ack,
+				Nack: func(requeue bool) error {
+					if requeue {
+						return msg.Nak()
+					}
+					return msg.Term()
+				},
+			}
+			if md, err := msg.Metadata(); err == nil {
+				d.Timestamp = md.Timestamp
+				d.Redelivered = md.NumDelivered > 1
+			}
+
+			attrs := []attribute.KeyValue{
+				semconv.MessagingSystemKey.String(BrokerNATS),
+				semconv.MessagingDestinationName(msg.Subject()),
+				semconv.MessagingConsumerGroupName(name),
+			}
+			handleDelivery(d, name, label, attrs, handler)
+		}
+	}()
+
+	return nil
+}
+
+// Shutdown para de buscar mensagens, processa as que já foram recebidas e
+// remove os consumers efêmeros. As demais ficam na stream para outra réplica.
+func (c *natsConsumer) Shutdown(ctx context.Context) error {
+	c.mu.Lock()
+	for _, msgs := range c.iterators {
+		msgs.Drain()
+	}
+	c.mu.Unlock()
+
+	err := wait(ctx, &c.wg)
+	if err != nil {
+		err = fmt.Errorf("consumer drain: %w", err)
+	}
+
+	c.mu.Lock()
+	for _, name := range c.ephemeral {
+		if err := c.js.DeleteConsumer(ctx, c.config.Exchange, name); err != nil {
+			slog.Warn("Erro ao remover consumer", "queue", name, "error", err)
+		}
+	}
+	c.ephemeral = nil
+	c.mu.Unlock()
+
+	c.Close()
+	return err
+}
+
+func (c *natsConsumer) Check() error {
+	return c.check()
+}
+
+func (c *natsConsumer) Close() error {
+	c.mu.Lock()
+	for _, msgs := range c.iterators {
+		msgs.Stop()
+	}
+	c.iterators = nil
+	c.mu.Unlock()
+
+	return c.close()
+}
+
+// natsFilters traduz os bindings para filtros de subject. # no fim vira o
+// próprio prefixo mais ">" (zero ou mais palavras); em outra posição, ou
+// quando os filtros se sobrepõem (o JetStream não aceita), o filtro é a
+// stream inteira e a seleção fica com o consumer.
+func natsFilters(exchange string, routingKeys []string) []string {
+	var filters [][]string
+	for _, routingKey := range routingKeys {
+		words := strings.Split(routingKey, ".")
+		hash := slices.Index(words, "#")
+		switch {
+		case hash < 0:
+			filters = append(filters, words)
+		case hash == len(words)-1:
+			if hash > 0 {
+				filters = append(filters, words[:hash])
+			}
+			filters = append(filters, append(slices.Clone(words[:hash]), ">"))
+		default:
+			return []string{exchange + ".>"}
+		}
+	}
+
+	// Descarta filtros contidos em outros (ex.: order.created em order.*)
+	var kept [][]string
+	for i, filter := range filters {
+		contained := slices.ContainsFunc(filters, func(other []string) bool {
+			return !slices.Equal(other, filter) && subjectContains(other, filter)
+		}) || slices.ContainsFunc(filters[:i], func(other []string) bool {
+			return slices.Equal(other, filter)
+		})
+		if !contained {
+			kept = append(kept, filter)
+		}
+	}
+
+	subjects := make([]string, len(kept))
+	for i, filter := range kept {
+		for _, other := range kept[i+1:] {
+			if subjectsCollide(filter, other) {
+				return []string{exchange + ".>"}
+			}
+		}
+		subjects[i] = exchange + "." + strings.Join(filter, ".")
+	}
+	return subjects
+}
+
+// subjectContains informa se todo subject aceito por inner também é aceito
+// por outer.
+func subjectContains(outer, inner []string) bool {
+	for i, token := range outer {
+		if token == ">" {
+			return len(inner) > i
+		}
+		if i >= len(inner) || inner[i] == ">" {
+			return false
+		}
+		if token != "*" && token != inner[i] {
+			return false
+		}
+	}
+	return len(inner) == len(outer)
+}
+
+// subjectsCollide informa se algum subject é aceito pelos dois filtros.
+func subjectsCollide(a, b []string) bool {
+	for i := 0; ; i++ {
+		if i == len(a) || i == len(b) {
+			return len(a) == len(b)
+		}
+		switch {
+		case a[i] == ">" || b[i] == ">":
+			return true
+		case a[i] == "*" || b[i] == "*":
+		case a[i] != b[i]:
+			return false
+		}
+	}
+}
+
+func topicPatterns(routingKeys []string) [][]string {
+	patterns := make([][]string, len(routingKeys))
+	for i, routingKey := range routingKeys {
+		patterns[i] = strings.Split(routingKey, ".")
+	}
+	return patterns
+}
+
+func matchesAny(patterns [][]string, routingKey string) bool {
+	words := strings.Split(routingKey, ".")
+	return slices.ContainsFunc(patterns, func(pattern []string) bool {
+		return topicMatches(pattern, words)
+	})
+}
+
+// mergeRoutingKeys devolve as routing keys de registered mais as de added,
+// sem repetições.
+func mergeRoutingKeys(registered, added []string) []string {
+	var merged []string
+	for _, routingKey := range slices.Concat(registered, added) {
+		if routingKey != "" && !slices.Contains(merged, routingKey) {
+			merged = append(merged, routingKey)
+		}
+	}
+	return merged
+}
@@ -0,0 +1,66 @@
+package mq
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+)
+
+func TestNATSBroker(t *testing.T) {
+	testBroker(t, func(t *testing.T) (func() Publisher, func() Consumer) {
+		srv, err := server.NewServer(&server.Options{
+			Host:      "127.0.0.1",
+			Port:      -1,
+			JetStream: true,
+			StoreDir:  t.TempDir(),
+			NoLog:     true,
+			NoSigs:    true,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		srv.Start()
+		t.Cleanup(srv.Shutdown)
+		if !srv.ReadyForConnections(5 * time.Second) {
+			t.Fatal("servidor NATS não iniciou")
+		}
+
+		cfg := newTestConfig(BrokerNATS, srv.ClientURL())
+		return func() Publisher {
+				publisher, err := NewPublisher(cfg)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return publisher
+			}, func() Consumer {
+				consumer, err := NewConsumer(cfg)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return consumer
+			}
+	})
+}
+
+func TestNATSFilters(t *testing.T) {
+	for _, tc := range []struct {
+		keys string
+		want []string
+	}{
+		{"order.created", []string{"orders.order.created"}},
+		{"order.created,order.cancelled", []string{"orders.order.created", "orders.order.cancelled"}},
+		{"order.*,order.created", []string{"orders.order.*"}},
+		{"order.#", []string{"orders.order", "orders.order.>"}},
+		{"#", []string{"orders.>"}},
+		{"order.#,order.created", []string{"orders.order", "orders.order.>"}},
+		{"order.#.changed", []string{"orders.>"}},
+		{"order.*,*.created", []string{"orders.>"}},
+	} {
+		if got := natsFilters("orders", strings.Split(tc.keys, ",")); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s = %v, esperado %v", tc.keys, got, tc.want)
+		}
+	}
+}
@@ -1,66 +1,140 @@
 package mq
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"order-service/internal/config"
+	"order-service/pkg/tracing"
+	"sync"
+	"time"
 
-	amqp "github.com/rabbitmq/amqp091-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Publisher interface {
-	PublishOrderEvent(eventType string, orderID uint, data any) error
+	// As publicações respeitam o prazo de ctx, limitado a PublishTimeout.
+	PublishOrderEvent(ctx context.Context, eventType string, orderID uint, data any) error
+	// PublishOrderEvents publica vários eventos de uma vez (ex.: operações em
+	// lote), em ordem; nos brokers com confirmação, envia o lote inteiro antes
+	// de esperar as confirmações. Continua após falhas e retorna os erros
+	// agregados, um por evento.
+	PublishOrderEvents(ctx context.Context, events []OrderEvent) error
+	// ReplayOrderEvents republica eventos já emitidos (ou reconstruídos),
+	// marcados com o header replay e direcionados conforme target.
+	ReplayOrderEvents(ctx context.Context, events []OrderEvent, target ReplayTarget) error
+	// PublishTo publica com uma routing key própria em vez de order.<tipo>,
+	// para comandos a outros serviços (ex.: inventory.reserve).
+	PublishTo(ctx context.Context, routingKey string, event OrderEvent) error
+	// Check retorna erro se a conexão ou o canal com o broker estiverem fechados.
+	Check() error
+	// Shutdown recusa novas publicações, espera as que estão em andamento (até
+	// o prazo de ctx) e fecha a conexão.
+	Shutdown(ctx context.Context) error
 	Close() error
 }
 
-type publisher struct {
-	conn    *amqp.Connection
-	channel *amqp.Channel
-	config  *config.RabbitMQConfig
+// ErrPublisherClosed é retornado por publicações feitas após o Shutdown.
+var ErrPublisherClosed = errors.New("publisher closed")
+
+// Brokers suportados (config mq.broker)
+const (
+	BrokerRabbitMQ = "rabbitmq"
+	BrokerNATS     = "nats"
+	BrokerRedis    = "redis"
+	BrokerMemory   = "memory"
+)
+
+// Headers das mensagens republicadas
+const (
+	// HeaderReplay leva o ID do replay; ausente nos eventos originais
+	HeaderReplay = "replay"
+	// HeaderReplayQueue restringe o processamento à fila indicada
+	HeaderReplayQueue = "replay_queue"
+)
+
+// ReplayTarget identifica e direciona uma republicação.
+type ReplayTarget struct {
+	// ID vai no header replay de cada mensagem
+	ID string
+	// RoutingKey substitui order.<tipo>, se definida
+	RoutingKey string
+	// Queue, se definida, é a única fila que processa as mensagens: as
+	// demais filas vinculadas à routing key as confirmam sem processar
+	Queue string
 }
 
-func NewPublisher(cfg *config.RabbitMQConfig) (Publisher, error) {
-	conn, err := amqp.Dial(cfg.URL)
-	if err != nil {
-		return nil, fmt.Errorf("failed to connect to RabbitMQ: %w", err)
-	}
+// message é um evento pronto para o broker: corpo JSON e headers com o tipo,
+// o pedido e o contexto de trace.
+type message struct {
+	ID         string
+	RoutingKey string
+	Timestamp  time.Time
+	Headers    map[string]any
+	Body       []byte
+}
 
-	channel, err := conn.Channel()
-	if err != nil {
-		conn.Close()
-		return nil, fmt.Errorf("failed to open channel: %w", err)
-	}
+// transport entrega mensagens a um broker específico; o publisher cuida do
+// envelope, dos prazos, do trace, das métricas e do desligamento.
+type transport interface {
+	// attributes identificam o broker e o destino nos spans
+	attributes(routingKey string) []attribute.KeyValue
+	send(ctx context.Context, msg message) error
+	check() error
+	close() error
+}
+
+// batchTransport é implementado pelos transportes com confirmação de
+// publicação: sendBatch envia as mensagens em ordem, sem esperar cada
+// confirmação, e só então espera todas. Devolve o erro de cada mensagem.
+type batchTransport interface {
+	sendBatch(ctx context.Context, msgs []message) []error
+}
+
+type publisher struct {
+	transport transport
+	config    *config.MQConfig
 
-	p := &publisher{
-		conn:    conn,
-		channel: channel,
-		config:  cfg,
+	mu       sync.RWMutex
+	closed   bool
+	inflight sync.WaitGroup
+}
+
+// NewPublisher conecta ao broker escolhido em cfg.Broker. Com BrokerMemory,
+// publisher e consumers do processo compartilham o mesmo MemoryBroker.
+func NewPublisher(cfg *config.MQConfig) (Publisher, error) {
+	if cfg.Broker == BrokerMemory {
+		slog.Warn("Usando broker em memória: os eventos não saem do processo", "exchange", cfg.Exchange)
+		return defaultMemoryBroker.NewPublisher(cfg), nil
 	}
 
-	// Declarar exchange
-	if err := p.setupExchange(); err != nil {
-		p.Close()
+	var t transport
+	var err error
+	switch cfg.Broker {
+	case BrokerNATS:
+		t, err = newNATSTransport(cfg)
+	case BrokerRedis:
+		t, err = newRedisTransport(cfg)
+	default:
+		t, err = newRabbitMQTransport(cfg)
+	}
+	if err != nil {
 		return nil, err
 	}
 
-	log.Printf("RabbitMQ Publisher conectado")
-	return p, nil
-}
-
-func (p *publisher) setupExchange() error {
-	return p.channel.ExchangeDeclare(
-		p.config.Exchange, // name
-		"topic",           // type
-		true,              // durable
-		false,             // auto-deleted
-		false,             // internal
-		false,             // no-wait
-		nil,               // arguments
-	)
+	slog.Info("Publisher conectado", "broker", cfg.Broker, "url", cfg.URL, "exchange", cfg.Exchange)
+	return &publisher{transport: t, config: cfg}, nil
 }
 
-func (p *publisher) PublishOrderEvent(eventType string, orderID uint, data interface{}) error {
+func (p *publisher) PublishOrderEvent(ctx context.Context, eventType string, orderID uint, data interface{}) error {
 	// Criar evento
 	event := OrderEvent{
 		Type:    eventType,
@@ -68,49 +142,235 @@ func (p *publisher) PublishOrderEvent(eventType string, orderID uint, data inter
 		Data:    data,
 	}
 
+	if err := p.publish(ctx, &event, ReplayTarget{}); err != nil {
+		return err
+	}
+
+	slog.DebugContext(ctx, "Evento publicado", "event_type", eventType, "order_id", orderID, "event_id", event.ID)
+	return nil
+}
+
+func (p *publisher) PublishOrderEvents(ctx context.Context, events []OrderEvent) error {
+	failures, err := orderErrors(events, p.send(ctx, events, ReplayTarget{}))
+
+	slog.InfoContext(ctx, "Lote de eventos publicado", "events", len(events), "failures", failures)
+	return err
+}
+
+func (p *publisher) ReplayOrderEvents(ctx context.Context, events []OrderEvent, target ReplayTarget) error {
+	failures, err := orderErrors(events, p.send(ctx, events, target))
+
+	slog.DebugContext(ctx, "Eventos republicados", "replay_id", target.ID, "events", len(events), "failures", failures)
+	return err
+}
+
+func (p *publisher) PublishTo(ctx context.Context, routingKey string, event OrderEvent) error {
+	if err := p.publish(ctx, &event, ReplayTarget{RoutingKey: routingKey}); err != nil {
+		return err
+	}
+
+	slog.DebugContext(ctx, "Evento publicado", "routing_key", routingKey, "order_id", event.OrderID, "event_id", event.ID)
+	return nil
+}
+
+// orderErrors agrega os erros de send, identificando o pedido de cada um, e
+// devolve quantos eventos falharam.
+func orderErrors(events []OrderEvent, errs []error) (int, error) {
+	var joined []error
+	for i, err := range errs {
+		if err != nil {
+			joined = append(joined, fmt.Errorf("order %d: %w", events[i].OrderID, err))
+		}
+	}
+	return len(joined), errors.Join(joined...)
+}
+
+func (p *publisher) publish(ctx context.Context, event *OrderEvent, target ReplayTarget) error {
+	events := []OrderEvent{*event}
+	err := p.send(ctx, events, target)[0]
+	*event = events[0]
+	return err
+}
+
+// send publica os eventos na ordem recebida e devolve o erro de cada um (nil
+// para os publicados). Nos brokers com confirmação (NATS, Redis), o lote
+// inteiro é enviado antes de esperar as confirmações, em vez de uma ida e
+// volta por evento. O prazo de PublishTimeout vale para o lote todo.
+func (p *publisher) send(ctx context.Context, events []OrderEvent, target ReplayTarget) []error {
+	errs := make([]error, len(events))
+
+	p.mu.RLock()
+	if p.closed {
+		p.mu.RUnlock()
+		for i := range errs {
+			errs[i] = ErrPublisherClosed
+		}
+		return errs
+	}
+	p.inflight.Add(1)
+	p.mu.RUnlock()
+	defer p.inflight.Done()
+
+	if p.config.PublishTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.config.PublishTimeout)
+		defer cancel()
+	}
+
+	spans := make([]trace.Span, len(events))
+	msgs := make([]message, 0, len(events))
+	// Posição em events de cada mensagem de msgs
+	positions := make([]int, 0, len(events))
+	for i := range events {
+		var msg message
+		spans[i], msg, errs[i] = p.prepare(ctx, &events[i], target)
+		if errs[i] == nil {
+			msgs = append(msgs, msg)
+			positions = append(positions, i)
+		}
+	}
+
+	start := time.Now()
+	var sendErrs []error
+	// O broker pode não interromper um envio em andamento, então o prazo é conferido antes
+	if batch, ok := p.transport.(batchTransport); ok && len(msgs) > 1 && ctx.Err() == nil {
+		sendErrs = batch.sendBatch(ctx, msgs)
+	} else {
+		sendErrs = make([]error, len(msgs))
+		for j, msg := range msgs {
+			if sendErrs[j] = ctx.Err(); sendErrs[j] == nil {
+				sendErrs[j] = p.transport.send(ctx, msg)
+			}
+		}
+	}
+	elapsed := time.Since(start)
+
+	// Em lote, cada evento registra a duração do envio do lote inteiro
+	for j, i := range positions {
+		publishDuration.WithLabelValues(events[i].Type).Observe(elapsed.Seconds())
+		if sendErrs[j] != nil {
+			errs[i] = fmt.Errorf("failed to publish event: %w", sendErrs[j])
+		}
+	}
+	for i, span := range spans {
+		if errs[i] != nil {
+			publishFailures.WithLabelValues(events[i].Type).Inc()
+			span.RecordError(errs[i])
+			span.SetStatus(codes.Error, errs[i].Error())
+		}
+		span.End()
+	}
+	return errs
+}
+
+// prepare completa o envelope do evento (ID, horário), abre o span de
+// publicação e monta a mensagem, com o contexto de trace nos headers.
+func (p *publisher) prepare(ctx context.Context, event *OrderEvent, target ReplayTarget) (trace.Span, message, error) {
+	if event.ID == "" {
+		event.ID = newEventID()
+	}
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now().UTC()
+	}
+
+	routingKey := fmt.Sprintf("order.%s", event.Type)
+	if target.RoutingKey != "" {
+		routingKey = target.RoutingKey
+	}
+
+	ctx, span := tracing.Tracer().Start(ctx, routingKey+" publish",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(p.transport.attributes(routingKey)...),
+		trace.WithAttributes(
+			semconv.MessagingOperationTypeSend,
+			semconv.MessagingMessageID(event.ID),
+		),
+	)
+
 	body, err := json.Marshal(event)
 	if err != nil {
-		return fmt.Errorf("failed to marshal event: %w", err)
-	}
-
-	routingKey := fmt.Sprintf("order.%s", eventType)
-
-	err = p.channel.Publish(
-		p.config.Exchange, // exchange
-		routingKey,        // routing key
-		false,             // mandatory
-		false,             // immediate
-		amqp.Publishing{
-			ContentType:  "application/json",
-			DeliveryMode: amqp.Persistent, // Persistir mensagem
-			Body:         body,
-			Headers: amqp.Table{
-				"event_type": eventType,
-				"order_id":   int(orderID),
-			},
-		},
-	)
+		return span, message{}, fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	headers := map[string]any{
+		"event_type": event.Type,
+		"order_id":   event.OrderID,
+	}
+	if target.ID != "" {
+		headers[HeaderReplay] = target.ID
+		event.Replay = target.ID
+	}
+	if target.Queue != "" {
+		headers[HeaderReplayQueue] = target.Queue
+	}
+	// Contexto W3C (traceparent) para o consumer continuar o trace
+	otel.GetTextMapPropagator().Inject(ctx, headerCarrier(headers))
+
+	return span, message{
+		ID:         event.ID,
+		RoutingKey: routingKey,
+		Timestamp:  event.OccurredAt,
+		Headers:    headers,
+		Body:       body,
+	}, nil
+}
+
+func (p *publisher) Check() error {
+	return p.transport.check()
+}
 
+func (p *publisher) Shutdown(ctx context.Context) error {
+	p.mu.Lock()
+	p.closed = true
+	p.mu.Unlock()
+
+	err := wait(ctx, &p.inflight)
 	if err != nil {
-		return fmt.Errorf("failed to publish event: %w", err)
+		err = fmt.Errorf("publisher drain: %w", err)
 	}
 
-	log.Printf("Evento publicado: %s (Order ID: %d)", eventType, orderID)
-	return nil
+	p.Close()
+	return err
 }
 
 func (p *publisher) Close() error {
-	if p.channel != nil {
-		p.channel.Close()
+	return p.transport.close()
+}
+
+type OrderEvent struct {
+	ID         string    `json:"id,omitempty"`
+	Type       string    `json:"type"`
+	OrderID    int       `json:"order_id"`
+	Data       any       `json:"data"`
+	OccurredAt time.Time `json:"occurred_at,omitzero"`
+	// Replay é o ID do replay (header replay) quando o evento é uma
+	// republicação; não faz parte do corpo da mensagem
+	Replay string `json:"-"`
+	// ReplayQueue é a fila a que o replay se destina (header replay_queue);
+	// vazio quando o replay vai para todas as filas
+	ReplayQueue string `json:"-"`
+	// RoutingKey com que a mensagem foi recebida (preenchida pelo consumer)
+	RoutingKey string `json:"-"`
+}
+
+// CustomerID extrai o customer_id do payload, quando presente.
+func (e OrderEvent) CustomerID() (uint, bool) {
+	data, ok := e.Data.(map[string]any)
+	if !ok {
+		return 0, false
 	}
-	if p.conn != nil {
-		p.conn.Close()
+
+	switch id := data["customer_id"].(type) {
+	case float64:
+		return uint(id), true
+	case uint:
+		return id, true
 	}
-	return nil
+	return 0, false
 }
 
-type OrderEvent struct {
-	Type    string `json:"type"`
-	OrderID int    `json:"order_id"`
-	Data    any    `json:"data"`
+func newEventID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
 }
@@ -0,0 +1,262 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"order-service/internal/config"
+	"sync"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+)
+
+// rabbitMQConn é a conexão e o canal usados pelo publisher e pelo consumer.
+type rabbitMQConn struct {
+	conn    *amqp.Connection
+	channel *amqp.Channel
+	config  *config.MQConfig
+}
+
+func dialRabbitMQ(cfg *config.MQConfig) (*rabbitMQConn, error) {
+	conn, err := amqp.Dial(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to RabbitMQ: %w", err)
+	}
+
+	channel, err := conn.Channel()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to open channel: %w", err)
+	}
+
+	c := &rabbitMQConn{
+		conn:    conn,
+		channel: channel,
+		config:  cfg,
+	}
+
+	// Declarar exchange (caso não exista)
+	if err := c.setupExchange(); err != nil {
+		c.close()
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *rabbitMQConn) setupExchange() error {
+	return c.channel.ExchangeDeclare(
+		c.config.Exchange, // name
+		"topic",           // type
+		true,              // durable
+		false,             // auto-deleted
+		false,             // internal
+		false,             // no-wait
+		nil,               // arguments
+	)
+}
+
+func (c *rabbitMQConn) check() error {
+	if c.conn == nil || c.conn.IsClosed() {
+		return errors.New("connection closed")
+	}
+	if c.channel == nil || c.channel.IsClosed() {
+		return errors.New("channel closed")
+	}
+	return nil
+}
+
+func (c *rabbitMQConn) close() error {
+	if c.channel != nil {
+		c.channel.Close()
+	}
+	if c.conn != nil {
+		c.conn.Close()
+	}
+	return nil
+}
+
+// rabbitMQTransport publica no exchange topic do RabbitMQ.
+type rabbitMQTransport struct {
+	*rabbitMQConn
+}
+
+func newRabbitMQTransport(cfg *config.MQConfig) (*rabbitMQTransport, error) {
+	conn, err := dialRabbitMQ(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &rabbitMQTransport{conn}, nil
+}
+
+func (t *rabbitMQTransport) attributes(routingKey string) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		semconv.MessagingSystemRabbitMQ,
+		semconv.MessagingDestinationName(t.config.Exchange),
+		semconv.MessagingRabbitMQDestinationRoutingKey(routingKey),
+	}
+}
+
+func (t *rabbitMQTransport) send(ctx context.Context, msg message) error {
+	return t.channel.PublishWithContext(ctx,
+		t.config.Exchange, // exchange
+		msg.RoutingKey,    // routing key
+		false,             // mandatory
+		false,             // immediate
+		amqp.Publishing{
+			ContentType:  "application/json",
+			DeliveryMode: amqp.Persistent, // Persistir mensagem
+			MessageId:    msg.ID,
+			Timestamp:    msg.Timestamp,
+			Body:         msg.Body,
+			Headers:      amqp.Table(msg.Headers),
+		},
+	)
+}
+
+type rabbitMQConsumer struct {
+	*rabbitMQConn
+
+	mu   sync.Mutex
+	tags []string
+	// Goroutines de processamento ainda ativas
+	wg sync.WaitGroup
+}
+
+func newRabbitMQConsumer(cfg *config.MQConfig) (Consumer, error) {
+	conn, err := dialRabbitMQ(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("RabbitMQ Consumer conectado", "exchange", cfg.Exchange)
+	return &rabbitMQConsumer{rabbitMQConn: conn}, nil
+}
+
+func (c *rabbitMQConsumer) StartListening(queueName string, routingKeys []string, handler EventHandler) error {
+	// Declarar fila. Sem nome, a fila é exclusiva desta conexão (nome gerado
+	// pelo broker) e removida ao desconectar: cada réplica recebe todos os eventos.
+	temporary := queueName == ""
+	queue, err := c.channel.QueueDeclare(
+		queueName,  // name
+		!temporary, // durable
+		temporary,  // delete when unused
+		temporary,  // exclusive
+		false,      // no-wait
+		nil,        // arguments
+	)
+	if err != nil {
+		return fmt.Errorf("failed to declare queue: %w", err)
+	}
+
+	// Bind fila ao exchange com routing keys
+	for _, routingKey := range routingKeys {
+		err := c.channel.QueueBind(
+			queue.Name,        // queue name
+			routingKey,        // routing key
+			c.config.Exchange, // exchange
+			false,
+			nil,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to bind queue: %w", err)
+		}
+		slog.Debug("Fila vinculada ao exchange", "queue", queue.Name, "routing_key", routingKey)
+	}
+
+	// Configurar QoS
+	err = c.channel.Qos(
+		c.config.Prefetch, // prefetch count
+		0,                 // prefetch size
+		false,             // global
+	)
+	if err != nil {
+		return fmt.Errorf("failed to set QoS: %w", err)
+	}
+
+	// Consumir mensagens. A tag permite cancelar o consumo no Shutdown.
+	c.mu.Lock()
+	tag := fmt.Sprintf("order-service-%d", len(c.tags))
+	c.tags = append(c.tags, tag)
+	c.mu.Unlock()
+
+	msgs, err := c.channel.Consume(
+		queue.Name, // queue
+		tag,        // consumer
+		false,      // auto-ack
+		false,      // exclusive
+		false,      // no-local
+		false,      // no-wait
+		nil,        // args
+	)
+	if err != nil {
+		return fmt.Errorf("failed to register consumer: %w", err)
+	}
+
+	slog.Info("Escutando mensagens", "queue", queue.Name)
+
+	// Filas temporárias têm nome gerado a cada conexão; usar um label fixo
+	// para não criar novas séries a cada restart.
+	label := queue.Name
+	if temporary {
+		label = "exclusive"
+	}
+
+	// Processar mensagens em goroutine
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		for msg := range msgs {
+			attrs := []attribute.KeyValue{
+				semconv.MessagingSystemRabbitMQ,
+				semconv.MessagingDestinationName(queue.Name),
+				semconv.MessagingRabbitMQDestinationRoutingKey(msg.RoutingKey),
+			}
+			handleDelivery(delivery{
+				message: message{
+					ID:         msg.MessageId,
+					RoutingKey: msg.RoutingKey,
+					Timestamp:  msg.Timestamp,
+					Headers:    msg.Headers,
+					Body:       msg.Body,
+				},
+				Redelivered: msg.Redelivered,
+				Ack:         func() error { return msg.Ack(false) },
+				Nack:        func(requeue bool) error { return msg.Nack(false, requeue) },
+			}, queue.Name, label, attrs, handler)
+		}
+	}()
+
+	return nil
+}
+
+// Shutdown cancela o consumo de todas as filas e espera as mensagens em
+// processamento serem confirmadas antes de fechar a conexão. Mensagens ainda
+// não entregues ficam na fila para outra réplica.
+func (c *rabbitMQConsumer) Shutdown(ctx context.Context) error {
+	c.mu.Lock()
+	for _, tag := range c.tags {
+		if err := c.channel.Cancel(tag, false); err != nil {
+			slog.Warn("Erro ao cancelar consumer", "tag", tag, "error", err)
+		}
+	}
+	c.mu.Unlock()
+
+	err := wait(ctx, &c.wg)
+	if err != nil {
+		err = fmt.Errorf("consumer drain: %w", err)
+	}
+
+	c.Close()
+	return err
+}
+
+func (c *rabbitMQConsumer) Check() error {
+	return c.check()
+}
+
+func (c *rabbitMQConsumer) Close() error {
+	return c.close()
+}
@@ -0,0 +1,366 @@
+package mq
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"order-service/internal/config"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+)
+
+// No Redis Streams, o exchange é uma stream (chave cfg.Exchange) com a
+// routing key em cada entrada, e cada fila é um consumer group: com nome, é
+// compartilhado entre as réplicas e guarda a posição entre reinícios; sem
+// nome, é criado por consumer e removido no Shutdown. O Redis não filtra por
+// routing key, então o consumer confirma sem processar o que não casa com
+// os bindings da fila.
+
+// redisConsumers numera os consumers do processo, para cada um ter seu
+// próprio nome nos groups.
+var redisConsumers atomic.Int64
+
+// redisConn é o cliente usado pelo publisher e pelo consumer.
+type redisConn struct {
+	client *redis.Client
+	config *config.MQConfig
+}
+
+func dialRedis(cfg *config.MQConfig) (*redisConn, error) {
+	opts, err := redis.ParseURL(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Redis URL: %w", err)
+	}
+	// Cancelar o contexto interrompe o XREADGROUP bloqueado no Shutdown
+	opts.ContextTimeoutEnabled = true
+	client := redis.NewClient(opts)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
+	}
+	return &redisConn{client: client, config: cfg}, nil
+}
+
+func (c *redisConn) check() error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	return c.client.Ping(ctx).Err()
+}
+
+func (c *redisConn) close() error {
+	return c.client.Close()
+}
+
+// redisTransport adiciona as mensagens à stream do exchange.
+type redisTransport struct {
+	*redisConn
+}
+
+func newRedisTransport(cfg *config.MQConfig) (*redisTransport, error) {
+	conn, err := dialRedis(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &redisTransport{conn}, nil
+}
+
+func (t *redisTransport) attributes(string) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		semconv.MessagingSystemKey.String(BrokerRedis),
+		semconv.MessagingDestinationName(t.config.Exchange),
+	}
+}
+
+func (t *redisTransport) send(ctx context.Context, msg message) error {
+	args, err := t.xAddArgs(msg)
+	if err != nil {
+		return err
+	}
+	return t.client.XAdd(ctx, args).Err()
+}
+
+var _ batchTransport = (*redisTransport)(nil)
+
+// sendBatch envia os XADD em um pipeline: uma ida e volta para o lote todo.
+func (t *redisTransport) sendBatch(ctx context.Context, msgs []message) []error {
+	errs := make([]error, len(msgs))
+	cmds := make([]*redis.StringCmd, len(msgs))
+	pipe := t.client.Pipeline()
+	for i, msg := range msgs {
+		args, err := t.xAddArgs(msg)
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		cmds[i] = pipe.XAdd(ctx, args)
+	}
+
+	// O erro de cada comando fica no próprio cmd
+	pipe.Exec(ctx)
+	for i, cmd := range cmds {
+		if cmd != nil {
+			errs[i] = cmd.Err()
+		}
+	}
+	return errs
+}
+
+func (t *redisTransport) xAddArgs(msg message) (*redis.XAddArgs, error) {
+	headers, err := json.Marshal(msg.Headers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal headers: %w", err)
+	}
+
+	// MINID descarta as entradas mais antigas que a retenção
+	return &redis.XAddArgs{
+		Stream: t.config.Exchange,
+		MinID:  strconv.FormatInt(time.Now().Add(-t.config.Retention).UnixMilli(), 10),
+		Approx: true,
+		Values: []any{
+			"id", msg.ID,
+			"routing_key", msg.RoutingKey,
+			"timestamp", msg.Timestamp.UnixMilli(),
+			"headers", headers,
+			"body", msg.Body,
+		},
+	}, nil
+}
+
+type redisConsumer struct {
+	*redisConn
+
+	// name identifica esta réplica nos consumer groups
+	name string
+
+	mu     sync.Mutex
+	cancel []context.CancelFunc
+	// Groups temporários, removidos no Shutdown
+	temporary []string
+	// Goroutines de processamento ainda ativas
+	wg sync.WaitGroup
+}
+
+func newRedisConsumer(cfg *config.MQConfig) (Consumer, error) {
+	conn, err := dialRedis(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	hostname, _ := os.Hostname()
+	slog.Info("Redis Consumer conectado", "stream", cfg.Exchange)
+	return &redisConsumer{
+		redisConn: conn,
+		name:      fmt.Sprintf("%s-%d-%d", hostname, os.Getpid(), redisConsumers.Add(1)),
+	}, nil
+}
+
+func (c *redisConsumer) StartListening(queueName string, routingKeys []string, handler EventHandler) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	stream := c.config.Exchange
+	temporary := queueName == ""
+	group := queueName
+	if temporary {
+		group = "exclusive-" + newEventID()
+	}
+
+	// Como uma fila nova, o group recebe só o que for publicado depois de criado
+	err := c.client.XGroupCreateMkStream(ctx, stream, group, "$").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return fmt.Errorf("failed to declare consumer group: %w", err)
+	}
+
+	// Os bindings de um group nomeado ficam no Redis e se somam, como os de
+	// uma fila do RabbitMQ
+	if !temporary {
+		key := stream + ":bindings:" + group
+		if len(routingKeys) > 0 {
+			if err := c.client.SAdd(ctx, key, routingKeys).Err(); err != nil {
+				return fmt.Errorf("failed to bind consumer group: %w", err)
+			}
+		}
+		if routingKeys, err = c.client.SMembers(ctx, key).Result(); err != nil {
+			return fmt.Errorf("failed to bind consumer group: %w", err)
+		}
+	}
+	patterns := topicPatterns(routingKeys)
+
+	loopCtx, stop := context.WithCancel(context.Background())
+	c.mu.Lock()
+	c.cancel = append(c.cancel, stop)
+	if temporary {
+		c.temporary = append(c.temporary, group)
+	}
+	c.mu.Unlock()
+
+	slog.Info("Escutando mensagens", "queue", group, "broker", BrokerRedis)
+
+	// Mesmo label das filas exclusivas do RabbitMQ
+	label := group
+	if temporary {
+		label = "exclusive"
+	}
+	attrs := []attribute.KeyValue{
+		semconv.MessagingSystemKey.String(BrokerRedis),
+		semconv.MessagingDestinationName(stream),
+		semconv.MessagingConsumerGroupName(group),
+	}
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		for loopCtx.Err() == nil {
+			msgs, redelivered, err := c.read(loopCtx, group)
+			if err != nil {
+				if loopCtx.Err() != nil {
+					return
+				}
+				slog.Warn("Erro ao receber mensagens", "queue", group, "error", err)
+				select {
+				case <-loopCtx.Done():
+				case <-time.After(time.Second):
+				}
+				continue
+			}
+
+			// As mensagens já lidas são processadas mesmo durante o Shutdown
+			for _, msg := range msgs {
+				d := c.delivery(group, msg, redelivered)
+				if !matchesAny(patterns, d.RoutingKey) {
+					d.Ack()
+					continue
+				}
+				handleDelivery(d, group, label, attrs, handler)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// read devolve primeiro as mensagens sem ack há mais de AckWait (de
+// consumers que falharam ou de nack) e, não havendo, as novas.
+func (c *redisConsumer) read(ctx context.Context, group string) (msgs []redis.XMessage, redelivered bool, err error) {
+	stream := c.config.Exchange
+	count := int64(c.config.Prefetch)
+
+	msgs, _, err = c.client.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+		Stream:   stream,
+		Group:    group,
+		Consumer: c.name,
+		MinIdle:  c.config.AckWait,
+		Start:    "0-0",
+		Count:    count,
+	}).Result()
+	if err != nil || len(msgs) > 0 {
+		return msgs, true, err
+	}
+
+	streams, err := c.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    group,
+		Consumer: c.name,
+		Streams:  []string{stream, ">"},
+		Count:    count,
+		Block:    time.Second,
+	}).Result()
+	switch {
+	case errors.Is(err, redis.Nil):
+		return nil, false, nil
+	case err != nil || len(streams) == 0:
+		return nil, false, err
+	}
+	return streams[0].Messages, false, nil
+}
+
+func (c *redisConsumer) delivery(group string, msg redis.XMessage, redelivered bool) delivery {
+	field := func(name string) string {
+		value, _ := msg.Values[name].(string)
+		return value
+	}
+
+	var headers map[string]any
+	json.Unmarshal([]byte(field("headers")), &headers)
+	d := delivery{
+		message: message{
+			ID:         field("id"),
+			RoutingKey: field("routing_key"),
+			Headers:    headers,
+			Body:       []byte(field("body")),
+		},
+		Redelivered: redelivered,
+		Ack: func() error {
+			return c.client.XAck(context.Background(), c.config.Exchange, group, msg.ID).Err()
+		},
+		Nack: func(requeue bool) error {
+			ctx := context.Background()
+			if !requeue {
+				return c.client.XAck(ctx, c.config.Exchange, group, msg.ID).Err()
+			}
+			// Marca a mensagem como parada há AckWait, para o próximo
+			// XAUTOCLAIM entregá-la de novo sem esperar o prazo
+			return c.client.Do(ctx, "XCLAIM", c.config.Exchange, group, c.name, 0, msg.ID,
+				"IDLE", c.config.AckWait.Milliseconds(), "JUSTID").Err()
+		},
+	}
+	if ms, err := strconv.ParseInt(field("timestamp"), 10, 64); err == nil {
+		d.Timestamp = time.UnixMilli(ms)
+	}
+	return d
+}
+
+// Shutdown para de ler, processa as mensagens já lidas e remove os groups
+// temporários. Mensagens lidas e não confirmadas voltam para o group após
+// AckWait.
+func (c *redisConsumer) Shutdown(ctx context.Context) error {
+	c.mu.Lock()
+	for _, stop := range c.cancel {
+		stop()
+	}
+	c.mu.Unlock()
+
+	err := wait(ctx, &c.wg)
+	if err != nil {
+		err = fmt.Errorf("consumer drain: %w", err)
+	}
+
+	c.mu.Lock()
+	for _, group := range c.temporary {
+		if err := c.client.XGroupDestroy(ctx, c.config.Exchange, group).Err(); err != nil {
+			slog.Warn("Erro ao remover consumer group", "queue", group, "error", err)
+		}
+	}
+	c.temporary = nil
+	c.mu.Unlock()
+
+	c.Close()
+	return err
+}
+
+func (c *redisConsumer) Check() error {
+	return c.check()
+}
+
+func (c *redisConsumer) Close() error {
+	c.mu.Lock()
+	for _, stop := range c.cancel {
+		stop()
+	}
+	c.cancel = nil
+	c.mu.Unlock()
+
+	return c.close()
+}
@@ -0,0 +1,26 @@
+package mq
+
+import (
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+func TestRedisBroker(t *testing.T) {
+	testBroker(t, func(t *testing.T) (func() Publisher, func() Consumer) {
+		cfg := newTestConfig(BrokerRedis, "redis://"+miniredis.RunT(t).Addr())
+		return func() Publisher {
+				publisher, err := NewPublisher(cfg)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return publisher
+			}, func() Consumer {
+				consumer, err := NewConsumer(cfg)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return consumer
+			}
+	})
+}
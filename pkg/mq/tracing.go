@@ -0,0 +1,39 @@
+package mq
+
+import (
+	"fmt"
+
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// headerCarrier adapta os headers das mensagens (amqp.Table no RabbitMQ) para
+// o propagador do OpenTelemetry, que grava traceparent/tracestate na
+// publicação e os lê no consumo.
+type headerCarrier map[string]any
+
+var _ propagation.TextMapCarrier = headerCarrier(nil)
+
+func (h headerCarrier) Get(key string) string {
+	switch value := h[key].(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case []byte:
+		return string(value)
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+func (h headerCarrier) Set(key, value string) {
+	h[key] = value
+}
+
+func (h headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(h))
+	for key := range h {
+		keys = append(keys, key)
+	}
+	return keys
+}
@@ -0,0 +1,114 @@
+package mq
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestHeaderCarrier(t *testing.T) {
+	// Headers lidos do RabbitMQ podem chegar como []byte ou outros tipos
+	h := headerCarrier{"traceparent": []byte("00-abc-def-01"), "order_id": 42}
+	h.Set("tracestate", "vendor=1")
+
+	tests := map[string]string{
+		"traceparent": "00-abc-def-01",
+		"tracestate":  "vendor=1",
+		"order_id":    "42",
+		"ausente":     "",
+	}
+	for key, want := range tests {
+		if got := h.Get(key); got != want {
+			t.Errorf("Get(%q) = %q, esperado %q", key, got, want)
+		}
+	}
+	if n := len(h.Keys()); n != 3 {
+		t.Errorf("%d chaves, esperado 3", n)
+	}
+}
+
+// TestTracePropagation: o span de processamento continua o trace iniciado
+// na publicação, via traceparent nos headers da mensagem.
+func TestTracePropagation(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+		provider.Shutdown(context.Background())
+	})
+
+	cfg := newTestConfig(BrokerMemory, "")
+	broker := NewMemoryBroker()
+	publisher := broker.NewPublisher(cfg)
+	consumer := broker.NewConsumer(cfg)
+	t.Cleanup(func() {
+		consumer.Close()
+		publisher.Close()
+	})
+
+	received := make(chan trace.SpanContext, 1)
+	err := consumer.StartListening("tracing", []string{"order.created"}, func(ctx context.Context, _ OrderEvent) error {
+		received <- trace.SpanContextFromContext(ctx)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "POST /orders")
+	if err := publisher.PublishOrderEvent(ctx, "created", 1, nil); err != nil {
+		t.Fatal(err)
+	}
+	parent.End()
+
+	var handlerSpan trace.SpanContext
+	select {
+	case handlerSpan = <-received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("evento não entregue")
+	}
+	if handlerSpan.TraceID() != parent.SpanContext().TraceID() {
+		t.Fatal("handler recebeu um trace novo")
+	}
+
+	// publish é filho da requisição; process é filho do publish
+	var publish sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		if span.Name() == "order.created publish" {
+			publish = span
+		}
+	}
+	if publish == nil || publish.SpanKind() != trace.SpanKindProducer || publish.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Fatalf("span de publicação = %v", publish)
+	}
+	var process sdktrace.ReadOnlySpan
+	found := eventually(t, func() bool {
+		for _, span := range recorder.Ended() {
+			if span.Name() == "order.created process" {
+				process = span
+				return true
+			}
+		}
+		return false
+	})
+	if !found {
+		t.Fatal("span de processamento não encerrado")
+	}
+	if process.SpanKind() != trace.SpanKindConsumer || process.Parent().SpanID() != publish.SpanContext().SpanID() {
+		t.Errorf("span de processamento: kind %s, pai %s; esperado consumer filho de %s",
+			process.SpanKind(), process.Parent().SpanID(), publish.SpanContext().SpanID())
+	}
+	if process.SpanContext().SpanID() != handlerSpan.SpanID() {
+		t.Error("handler não recebeu o contexto do span de processamento")
+	}
+}
@@ -0,0 +1,157 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ValidatorOptions struct {
+	ValidateRequests  bool
+	ValidateResponses bool
+	// OnRequestError escreve a resposta quando a requisição não respeita o
+	// contrato. O padrão responde 400 com {"error", "message"}.
+	OnRequestError func(c *gin.Context, err error)
+}
+
+// Validator valida requisições (e opcionalmente respostas) contra o documento.
+// Rotas que não constam no documento passam sem validação.
+func Validator(doc *Document, opts ValidatorOptions) gin.HandlerFunc {
+	if opts.OnRequestError == nil {
+		opts.OnRequestError = func(c *gin.Context, err error) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"error":   "Requisição fora do contrato",
+				"message": err.Error(),
+			})
+		}
+	}
+
+	return func(c *gin.Context) {
+		op := doc.Operation(c.Request.Method, c.FullPath())
+		if op == nil {
+			c.Next()
+			return
+		}
+
+		if opts.ValidateRequests {
+			if err := doc.validateRequest(c, op); err != nil {
+				opts.OnRequestError(c, err)
+				return
+			}
+		}
+
+		// Streams (SSE/WebSocket) não são bufferizados para validação
+		if !opts.ValidateResponses || op.Streaming() {
+			c.Next()
+			return
+		}
+
+		recorder := &bodyRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		if err := doc.validateResponse(op, recorder.Status(), recorder.body.Bytes()); err != nil {
+			slog.WarnContext(c.Request.Context(), "Resposta fora do contrato",
+				"method", c.Request.Method, "route", c.FullPath(),
+				"operation_id", op.OperationID, "status", recorder.Status(), "error", err)
+		}
+	}
+}
+
+func (d *Document) validateRequest(c *gin.Context, op *Operation) error {
+	for _, p := range op.Parameters {
+		var raw string
+		var present bool
+		switch p.In {
+		case "path":
+			raw = c.Param(p.Name)
+			present = raw != ""
+		case "query":
+			raw, present = c.GetQuery(p.Name)
+		case "header":
+			raw = c.GetHeader(p.Name)
+			present = raw != ""
+		}
+
+		if !present {
+			if p.Required {
+				return fmt.Errorf("missing required %s parameter %q", p.In, p.Name)
+			}
+			continue
+		}
+
+		if err := d.ValidateParam(p, raw); err != nil {
+			return err
+		}
+	}
+
+	if op.RequestBody == nil {
+		return nil
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read body: %w", err)
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	if len(bytes.TrimSpace(body)) == 0 {
+		if op.RequestBody.Required {
+			return fmt.Errorf("request body is required")
+		}
+		return nil
+	}
+
+	media, ok := op.RequestBody.Content["application/json"]
+	if !ok {
+		return nil
+	}
+
+	var value any
+	if err := json.Unmarshal(body, &value); err != nil {
+		return fmt.Errorf("invalid JSON body: %w", err)
+	}
+	return d.Validate(media.Schema, value)
+}
+
+func (d *Document) validateResponse(op *Operation, status int, body []byte) error {
+	resp, ok := op.Responses[strconv.Itoa(status)]
+	if !ok {
+		resp, ok = op.Responses["default"]
+	}
+	if !ok {
+		return fmt.Errorf("undocumented status %d", status)
+	}
+
+	media, ok := resp.Content["application/json"]
+	if !ok || len(body) == 0 {
+		return nil
+	}
+
+	var value any
+	if err := json.Unmarshal(body, &value); err != nil {
+		return fmt.Errorf("invalid JSON body: %w", err)
+	}
+	return d.Validate(media.Schema, value)
+}
+
+type bodyRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bodyRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *bodyRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,162 @@
+package openapi
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+const validOrder = `{"customer_id": 1, "status": "paid", "items": [{"product_id": 10, "quantity": 2}], "created_at": "2026-01-02T15:04:05Z"}`
+
+// captureWarnings troca o logger padrão por um que escreve no buffer
+// devolvido, restaurado ao fim do teste.
+func captureWarnings(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelWarn})))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+	return &buf
+}
+
+func TestValidatorRequests(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.Use(Validator(testDocument(), ValidatorOptions{ValidateRequests: true}))
+	r.GET("/orders/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.POST("/orders", func(c *gin.Context) { c.Status(http.StatusCreated) })
+	r.GET("/health", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		body   string
+		want   int
+	}{
+		{"path válido", "GET", "/orders/1", "", http.StatusOK},
+		{"path com tipo errado", "GET", "/orders/abc", "", http.StatusBadRequest},
+		{"path abaixo do mínimo", "GET", "/orders/-1", "", http.StatusBadRequest},
+		{"query no enum", "GET", "/orders/1?status=paid", "", http.StatusOK},
+		{"query fora do enum", "GET", "/orders/1?status=lost", "", http.StatusBadRequest},
+		{"body válido", "POST", "/orders", validOrder, http.StatusCreated},
+		{"body ausente", "POST", "/orders", "", http.StatusBadRequest},
+		{"body não é JSON", "POST", "/orders", "{", http.StatusBadRequest},
+		{"body sem obrigatório", "POST", "/orders", `{"status": "paid"}`, http.StatusBadRequest},
+		{"rota fora do contrato", "GET", "/health?status=lost", "", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, esperado %d: %s", w.Code, tt.want, w.Body)
+			}
+		})
+	}
+}
+
+func TestValidatorKeepsRequestBody(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var got string
+	r := gin.New()
+	r.Use(Validator(testDocument(), ValidatorOptions{ValidateRequests: true}))
+	r.POST("/orders", func(c *gin.Context) {
+		body, _ := c.GetRawData()
+		got = string(body)
+	})
+
+	req := httptest.NewRequest("POST", "/orders", strings.NewReader(validOrder))
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	if got != validOrder {
+		t.Errorf("body no handler = %q, esperado %q", got, validOrder)
+	}
+}
+
+func TestValidatorResponses(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		wantWarn string // trecho esperado no log; vazio quando a resposta respeita o contrato
+	}{
+		{"resposta válida", http.StatusOK, validOrder, ""},
+		{"status sem corpo", http.StatusNotFound, "", ""},
+		{"corpo fora do schema", http.StatusOK, `{"customer_id": "1"}`, "expected integer, got string"},
+		{"status não documentado", http.StatusTeapot, "", "undocumented status 418"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs := captureWarnings(t)
+			r := gin.New()
+			r.Use(Validator(testDocument(), ValidatorOptions{ValidateResponses: true}))
+			r.GET("/orders/:id", func(c *gin.Context) {
+				c.Data(tt.status, "application/json", []byte(tt.body))
+			})
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest("GET", "/orders/1", nil))
+
+			// A validação só loga: a resposta chega intacta ao cliente
+			if w.Code != tt.status || w.Body.String() != tt.body {
+				t.Errorf("resposta = %d %q, esperado %d %q", w.Code, w.Body, tt.status, tt.body)
+			}
+			if tt.wantWarn == "" {
+				if logs.Len() != 0 {
+					t.Errorf("log = %s, esperado vazio", logs)
+				}
+				return
+			}
+			if !strings.Contains(logs.String(), "Resposta fora do contrato") || !strings.Contains(logs.String(), tt.wantWarn) {
+				t.Errorf("log = %s, esperado %q", logs, tt.wantWarn)
+			}
+		})
+	}
+}
+
+func TestValidatorSkipsStreamingResponses(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name     string
+		target   string
+		buffered bool
+	}{
+		{"operação comum", "/orders/1", true},
+		// Decidido pela tag da operação, mesmo sem Accept: text/event-stream
+		{"operação de stream", "/orders/1/events", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs := captureWarnings(t)
+			var buffered bool
+			handler := func(c *gin.Context) {
+				_, buffered = c.Writer.(*bodyRecorder)
+				c.Data(http.StatusOK, "text/event-stream", []byte("data: {}\n\n"))
+			}
+			r := gin.New()
+			r.Use(Validator(testDocument(), ValidatorOptions{ValidateResponses: true}))
+			r.GET("/orders/:id", handler)
+			r.GET("/orders/:id/events", handler)
+
+			r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", tt.target, nil))
+			if buffered != tt.buffered {
+				t.Errorf("resposta bufferizada = %v, esperado %v", buffered, tt.buffered)
+			}
+			if !tt.buffered && logs.Len() != 0 {
+				t.Errorf("log = %s, esperado vazio", logs)
+			}
+		})
+	}
+}
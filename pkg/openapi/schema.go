@@ -0,0 +1,194 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const refPrefix = "#/components/schemas/"
+
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	AdditionalProperties *bool              `json:"additionalProperties,omitempty"`
+	// Nullable é serializado no formato 3.1: "type": ["string", "null"]
+	Nullable bool `json:"-"`
+}
+
+func (s *Schema) MarshalJSON() ([]byte, error) {
+	type plain Schema
+	if !s.Nullable || s.Type == "" {
+		return json.Marshal((*plain)(s))
+	}
+
+	return json.Marshal(struct {
+		*plain
+		Type []string `json:"type"`
+	}{
+		plain: (*plain)(s),
+		Type:  []string{s.Type, "null"},
+	})
+}
+
+func Ref(name string) *Schema {
+	return &Schema{Ref: refPrefix + name}
+}
+
+func ArrayOf(items *Schema) *Schema {
+	return &Schema{Type: "array", Items: items}
+}
+
+func Integer() *Schema {
+	return &Schema{Type: "integer"}
+}
+
+func String() *Schema {
+	return &Schema{Type: "string"}
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// Register gera o schema de v a partir das tags json/binding e o adiciona em
+// components/schemas. Structs aninhadas são registradas pelo nome do tipo.
+func (d *Document) Register(v any) *Schema {
+	return d.schemaFor(reflect.TypeOf(v))
+}
+
+// RegisterEnum associa os valores permitidos a um tipo nomeado (ex.: OrderStatus).
+func (d *Document) RegisterEnum(v any, values ...any) *Schema {
+	t := reflect.TypeOf(v)
+	s := primitiveSchema(t)
+	s.Enum = values
+	d.Components.Schemas[t.Name()] = s
+	return Ref(t.Name())
+}
+
+func (d *Document) schemaFor(t reflect.Type) *Schema {
+	if t.Kind() == reflect.Pointer {
+		s := d.schemaFor(t.Elem())
+		if s.Ref == "" {
+			s.Nullable = true
+		}
+		return s
+	}
+
+	if t.Name() != "" && t.PkgPath() != "" {
+		if _, ok := d.Components.Schemas[t.Name()]; ok {
+			return Ref(t.Name())
+		}
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		return ArrayOf(d.schemaFor(t.Elem()))
+	case t.Kind() == reflect.Map:
+		return &Schema{Type: "object"}
+	case t.Kind() == reflect.Interface:
+		return &Schema{}
+	case t.Kind() != reflect.Struct:
+		return primitiveSchema(t)
+	}
+
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	// Registrar antes dos campos para suportar tipos recursivos
+	if t.Name() != "" {
+		d.Components.Schemas[t.Name()] = s
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, omitEmpty := jsonName(field)
+		if name == "-" {
+			continue
+		}
+
+		prop := d.schemaFor(field.Type)
+		binding, hasBinding := field.Tag.Lookup("binding")
+		if hasBinding {
+			applyBinding(prop, field.Type, binding)
+		}
+
+		if strings.Contains(binding, "required") || (!hasBinding && !omitEmpty) {
+			s.Required = append(s.Required, name)
+		}
+		s.Properties[name] = prop
+	}
+
+	if t.Name() != "" {
+		return Ref(t.Name())
+	}
+	return s
+}
+
+func jsonName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "" {
+		return field.Name, false
+	}
+
+	parts := strings.Split(tag, ",")
+	name := parts[0]
+	if name == "" {
+		name = field.Name
+	}
+	return name, strings.Contains(tag, "omitempty")
+}
+
+func applyBinding(s *Schema, t reflect.Type, binding string) {
+	for _, rule := range strings.Split(binding, ",") {
+		if rule == "dive" {
+			// regras após "dive" valem para os elementos
+			return
+		}
+
+		value, ok := strings.CutPrefix(rule, "min=")
+		if !ok || s.Ref != "" {
+			continue
+		}
+
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			continue
+		}
+
+		if t.Kind() == reflect.Slice {
+			items := int(n)
+			s.MinItems = &items
+		} else {
+			s.Minimum = &n
+		}
+	}
+}
+
+func primitiveSchema(t reflect.Type) *Schema {
+	zero := 0.0
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &Schema{Type: "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer", Minimum: &zero}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	default:
+		return &Schema{Type: "string"}
+	}
+}
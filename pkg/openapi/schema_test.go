@@ -0,0 +1,105 @@
+package openapi
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+)
+
+type testStatus string
+
+type testItem struct {
+	ProductID uint `json:"product_id" binding:"required"`
+	Quantity  int  `json:"quantity" binding:"required,min=1"`
+}
+
+type testOrder struct {
+	CustomerID uint       `json:"customer_id" binding:"required"`
+	Status     testStatus `json:"status"`
+	Items      []testItem `json:"items" binding:"required,min=1,dive"`
+	Note       *string    `json:"note,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// testDocument registra os tipos de teste e as operações usadas nos testes
+// do middleware.
+func testDocument() *Document {
+	doc := NewDocument("test", "1.0.0")
+	status := doc.RegisterEnum(testStatus(""), "pending", "paid")
+	order := doc.Register(testOrder{})
+
+	doc.AddOperation("GET", "/orders/:id", &Operation{
+		OperationID: "getOrder",
+		Parameters: []Parameter{
+			PathParam("id", &Schema{Type: "integer", Minimum: new(float64)}),
+			QueryParam("status", false, status),
+		},
+		Responses: map[string]*Response{
+			"200": {Description: "Pedido", Content: JSONContent(order)},
+			"404": {Description: "Não encontrado"},
+		},
+	})
+	doc.AddOperation("POST", "/orders", &Operation{
+		OperationID: "createOrder",
+		RequestBody: &RequestBody{Required: true, Content: JSONContent(order)},
+		Responses: map[string]*Response{
+			"201": {Description: "Criado", Content: JSONContent(order)},
+		},
+	})
+	doc.AddOperation("GET", "/orders/:id/events", &Operation{
+		OperationID: "orderEvents",
+		Tags:        []string{TagStreaming},
+		Parameters:  []Parameter{PathParam("id", Integer())},
+		Responses: map[string]*Response{
+			"200": {Description: "Stream", Content: JSONContent(order)},
+		},
+	})
+	return doc
+}
+
+func TestRegister(t *testing.T) {
+	doc := testDocument()
+
+	order := doc.Components.Schemas["testOrder"]
+	if order == nil {
+		t.Fatalf("testOrder não registrado: %v", doc.Components.Schemas)
+	}
+	if want := []string{"customer_id", "status", "items", "created_at"}; !slices.Equal(order.Required, want) {
+		t.Errorf("required = %v, esperado %v", order.Required, want)
+	}
+
+	tests := []struct {
+		name   string
+		schema *Schema
+		want   string
+	}{
+		{"uint", order.Properties["customer_id"], `{"type":"integer","minimum":0}`},
+		{"enum", order.Properties["status"], `{"$ref":"#/components/schemas/testStatus"}`},
+		{"slice com min", order.Properties["items"], `{"type":"array","items":{"$ref":"#/components/schemas/testItem"},"minItems":1}`},
+		{"ponteiro", order.Properties["note"], `{"type":["string","null"]}`},
+		{"time.Time", order.Properties["created_at"], `{"type":"string","format":"date-time"}`},
+		{"valores do enum", doc.Components.Schemas["testStatus"], `{"type":"string","enum":["pending","paid"]}`},
+		{"min no elemento", doc.Components.Schemas["testItem"].Properties["quantity"], `{"type":"integer","minimum":1}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(tt.schema)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("schema = %s, esperado %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPathConversion(t *testing.T) {
+	if got := ToOpenAPIPath("/orders/:id/items/:item_id"); got != "/orders/{id}/items/{item_id}" {
+		t.Errorf("ToOpenAPIPath = %s", got)
+	}
+	if got := ToGinPath("/orders/{id}/items/{item_id}"); got != "/orders/:id/items/:item_id" {
+		t.Errorf("ToGinPath = %s", got)
+	}
+}
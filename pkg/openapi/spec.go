@@ -0,0 +1,187 @@
+package openapi
+
+import (
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+const Version = "3.1.0"
+
+// TagStreaming marca operações que respondem com stream (SSE/WebSocket).
+const TagStreaming = "streaming"
+
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas,omitempty"`
+}
+
+type PathItem struct {
+	Get    *Operation `json:"get,omitempty"`
+	Post   *Operation `json:"post,omitempty"`
+	Put    *Operation `json:"put,omitempty"`
+	Patch  *Operation `json:"patch,omitempty"`
+	Delete *Operation `json:"delete,omitempty"`
+}
+
+type Operation struct {
+	OperationID string               `json:"operationId"`
+	Summary     string               `json:"summary,omitempty"`
+	Description string               `json:"description,omitempty"`
+	Tags        []string             `json:"tags,omitempty"`
+	Parameters  []Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+// Streaming indica se a operação responde com stream.
+func (o *Operation) Streaming() bool {
+	return slices.Contains(o.Tags, TagStreaming)
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Required    bool    `json:"required,omitempty"`
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                  `json:"required,omitempty"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+func NewDocument(title, version string) *Document {
+	return &Document{
+		OpenAPI: Version,
+		Info:    Info{Title: title, Version: version},
+		Paths:   make(map[string]*PathItem),
+		Components: Components{
+			Schemas: make(map[string]*Schema),
+		},
+	}
+}
+
+// AddOperation registra uma operação usando o formato de rota do gin
+// (":id"), convertido para o formato OpenAPI ("{id}").
+func (d *Document) AddOperation(method, ginPath string, op *Operation) {
+	path := ToOpenAPIPath(ginPath)
+	item, ok := d.Paths[path]
+	if !ok {
+		item = &PathItem{}
+		d.Paths[path] = item
+	}
+
+	switch method {
+	case http.MethodGet:
+		item.Get = op
+	case http.MethodPost:
+		item.Post = op
+	case http.MethodPut:
+		item.Put = op
+	case http.MethodPatch:
+		item.Patch = op
+	case http.MethodDelete:
+		item.Delete = op
+	}
+}
+
+// Operation retorna a operação registrada para o método e a rota do gin.
+func (d *Document) Operation(method, ginPath string) *Operation {
+	item, ok := d.Paths[ToOpenAPIPath(ginPath)]
+	if !ok {
+		return nil
+	}
+
+	switch method {
+	case http.MethodGet:
+		return item.Get
+	case http.MethodPost:
+		return item.Post
+	case http.MethodPut:
+		return item.Put
+	case http.MethodPatch:
+		return item.Patch
+	case http.MethodDelete:
+		return item.Delete
+	}
+	return nil
+}
+
+// Routes lista todas as operações no formato "METHOD /path" do gin.
+func (d *Document) Routes() []string {
+	var routes []string
+	for path, item := range d.Paths {
+		ginPath := ToGinPath(path)
+		for method, op := range map[string]*Operation{
+			http.MethodGet:    item.Get,
+			http.MethodPost:   item.Post,
+			http.MethodPut:    item.Put,
+			http.MethodPatch:  item.Patch,
+			http.MethodDelete: item.Delete,
+		} {
+			if op != nil {
+				routes = append(routes, method+" "+ginPath)
+			}
+		}
+	}
+	return routes
+}
+
+// Resolve segue um $ref para components/schemas.
+func (d *Document) Resolve(s *Schema) *Schema {
+	for s != nil && s.Ref != "" {
+		s = d.Components.Schemas[strings.TrimPrefix(s.Ref, refPrefix)]
+	}
+	return s
+}
+
+var (
+	ginParam     = regexp.MustCompile(`:([A-Za-z0-9_]+)`)
+	openAPIParam = regexp.MustCompile(`\{([A-Za-z0-9_]+)\}`)
+)
+
+func ToOpenAPIPath(ginPath string) string {
+	return ginParam.ReplaceAllString(ginPath, "{$1}")
+}
+
+func ToGinPath(path string) string {
+	return openAPIParam.ReplaceAllString(path, ":$1")
+}
+
+func JSONContent(schema *Schema) map[string]*MediaType {
+	return map[string]*MediaType{
+		"application/json": {Schema: schema},
+	}
+}
+
+func PathParam(name string, schema *Schema) Parameter {
+	return Parameter{Name: name, In: "path", Required: true, Schema: schema}
+}
+
+func QueryParam(name string, required bool, schema *Schema) Parameter {
+	return Parameter{Name: name, In: "query", Required: required, Schema: schema}
+}
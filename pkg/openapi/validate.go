@@ -0,0 +1,163 @@
+package openapi
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ValidationError agrega todas as violações encontradas em um documento.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return strings.Join(e.Problems, "; ")
+}
+
+// Validate verifica um valor já decodificado de JSON (map[string]any, []any,
+// float64, string, bool ou nil) contra o schema.
+func (d *Document) Validate(schema *Schema, value any) error {
+	var problems []string
+	d.validate(schema, value, "$", &problems)
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// ValidateParam converte um parâmetro de path/query para o tipo do schema e valida.
+func (d *Document) ValidateParam(p Parameter, raw string) error {
+	schema := d.Resolve(p.Schema)
+	var value any = raw
+
+	switch schema.Type {
+	case "integer", "number":
+		n, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("parameter %q must be of type %s", p.Name, schema.Type)
+		}
+		value = n
+	case "boolean":
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("parameter %q must be a boolean", p.Name)
+		}
+		value = b
+	}
+
+	var problems []string
+	d.validate(schema, value, p.Name, &problems)
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+func (d *Document) validate(schema *Schema, value any, path string, problems *[]string) {
+	schema = d.Resolve(schema)
+	if schema == nil {
+		return
+	}
+
+	report := func(format string, args ...any) {
+		*problems = append(*problems, path+": "+fmt.Sprintf(format, args...))
+	}
+
+	if value == nil && schema.Nullable {
+		return
+	}
+
+	if len(schema.Enum) > 0 && !slices.ContainsFunc(schema.Enum, func(e any) bool {
+		return fmt.Sprint(e) == fmt.Sprint(value)
+	}) {
+		report("value %v is not one of %v", value, schema.Enum)
+		return
+	}
+
+	switch schema.Type {
+	case "":
+		return
+	case "object":
+		obj, ok := value.(map[string]any)
+		if !ok {
+			report("expected object, got %s", jsonType(value))
+			return
+		}
+		for _, name := range schema.Required {
+			if _, ok := obj[name]; !ok {
+				report("missing required property %q", name)
+			}
+		}
+		for name, v := range obj {
+			prop, ok := schema.Properties[name]
+			if !ok {
+				if schema.AdditionalProperties != nil && !*schema.AdditionalProperties {
+					report("unknown property %q", name)
+				}
+				continue
+			}
+			d.validate(prop, v, path+"."+name, problems)
+		}
+	case "array":
+		arr, ok := value.([]any)
+		if !ok {
+			report("expected array, got %s", jsonType(value))
+			return
+		}
+		if schema.MinItems != nil && len(arr) < *schema.MinItems {
+			report("expected at least %d items, got %d", *schema.MinItems, len(arr))
+		}
+		for i, v := range arr {
+			d.validate(schema.Items, v, fmt.Sprintf("%s[%d]", path, i), problems)
+		}
+	case "integer", "number":
+		n, ok := value.(float64)
+		if !ok {
+			report("expected %s, got %s", schema.Type, jsonType(value))
+			return
+		}
+		if schema.Type == "integer" && n != math.Trunc(n) {
+			report("expected integer, got %v", n)
+		}
+		if schema.Minimum != nil && n < *schema.Minimum {
+			report("value %v is less than minimum %v", n, *schema.Minimum)
+		}
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			report("expected string, got %s", jsonType(value))
+			return
+		}
+		if schema.Format == "date-time" {
+			if _, err := time.Parse(time.RFC3339Nano, s); err != nil {
+				report("invalid date-time %q", s)
+			}
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			report("expected boolean, got %s", jsonType(value))
+		}
+	}
+}
+
+func jsonType(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	}
+	return fmt.Sprintf("%T", value)
+}
@@ -0,0 +1,101 @@
+package openapi
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	doc := testDocument()
+	order := Ref("testOrder")
+	closed := false
+	strict := &Schema{Type: "object", Properties: map[string]*Schema{"id": Integer()}, AdditionalProperties: &closed}
+
+	tests := []struct {
+		name   string
+		schema *Schema
+		body   string
+		want   string // trecho esperado no erro; vazio quando o valor é válido
+	}{
+		{"válido", order, `{"customer_id": 1, "status": "paid", "items": [{"product_id": 10, "quantity": 2}], "created_at": "2026-01-02T15:04:05Z"}`, ""},
+		{"nullable nulo", order, `{"customer_id": 1, "status": "paid", "items": [{"product_id": 10, "quantity": 2}], "note": null, "created_at": "2026-01-02T15:04:05Z"}`, ""},
+		{"tipo errado", order, `{"customer_id": "1", "status": "paid", "items": [{"product_id": 10, "quantity": 2}], "created_at": "2026-01-02T15:04:05Z"}`, "$.customer_id: expected integer, got string"},
+		{"inteiro com fração", order, `{"customer_id": 1.5, "status": "paid", "items": [{"product_id": 10, "quantity": 2}], "created_at": "2026-01-02T15:04:05Z"}`, "$.customer_id: expected integer, got 1.5"},
+		{"obrigatório ausente", order, `{"status": "paid", "items": [{"product_id": 10, "quantity": 2}], "created_at": "2026-01-02T15:04:05Z"}`, `$: missing required property "customer_id"`},
+		{"fora do enum", order, `{"customer_id": 1, "status": "lost", "items": [{"product_id": 10, "quantity": 2}], "created_at": "2026-01-02T15:04:05Z"}`, "$.status: value lost is not one of [pending paid]"},
+		{"lista vazia", order, `{"customer_id": 1, "status": "paid", "items": [], "created_at": "2026-01-02T15:04:05Z"}`, "$.items: expected at least 1 items, got 0"},
+		{"abaixo do mínimo", order, `{"customer_id": 1, "status": "paid", "items": [{"product_id": 10, "quantity": 0}], "created_at": "2026-01-02T15:04:05Z"}`, "$.items[0].quantity: value 0 is less than minimum 1"},
+		{"data inválida", order, `{"customer_id": 1, "status": "paid", "items": [{"product_id": 10, "quantity": 2}], "created_at": "ontem"}`, `$.created_at: invalid date-time "ontem"`},
+		{"não é objeto", order, `[]`, "$: expected object, got array"},
+		{"propriedade desconhecida", strict, `{"id": 1, "extra": true}`, `$: unknown property "extra"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var value any
+			if err := json.Unmarshal([]byte(tt.body), &value); err != nil {
+				t.Fatal(err)
+			}
+			err := doc.Validate(tt.schema, value)
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("erro = %v, esperado nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("erro = %v, esperado %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateReportsAllProblems(t *testing.T) {
+	doc := testDocument()
+	err := doc.Validate(Ref("testOrder"), map[string]any{"status": "lost"})
+
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("erro = %T, esperado *ValidationError", err)
+	}
+	// customer_id, items e created_at ausentes, status fora do enum
+	if len(verr.Problems) != 4 {
+		t.Errorf("problemas = %v, esperado 4", verr.Problems)
+	}
+}
+
+func TestValidateParam(t *testing.T) {
+	doc := testDocument()
+	op := doc.Operation("GET", "/orders/:id")
+	id, status := op.Parameters[0], op.Parameters[1]
+
+	tests := []struct {
+		name  string
+		param Parameter
+		raw   string
+		want  string
+	}{
+		{"inteiro", id, "42", ""},
+		{"não numérico", id, "abc", `parameter "id" must be of type integer`},
+		{"negativo", id, "-1", "id: value -1 is less than minimum 0"},
+		{"fração", id, "1.5", "id: expected integer, got 1.5"},
+		{"enum válido", status, "paid", ""},
+		{"fora do enum", status, "lost", "status: value lost is not one of [pending paid]"},
+		{"booleano", QueryParam("active", false, &Schema{Type: "boolean"}), "talvez", `parameter "active" must be a boolean`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := doc.ValidateParam(tt.param, tt.raw)
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("erro = %v, esperado nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("erro = %v, esperado %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type widget struct {
+	ID   uint
+	Name string
+}
+
+func attr(span sdktrace.ReadOnlySpan, key attribute.Key) string {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value.Emit()
+		}
+	}
+	return ""
+}
+
+func TestGormPlugin(t *testing.T) {
+	recorder := useRecorder(t)
+
+	database, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, _ := database.DB()
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	if err := database.Use(GormPlugin{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := database.Exec("CREATE TABLE widgets (id INTEGER PRIMARY KEY, name TEXT)").Error; err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, parent := Tracer().Start(context.Background(), "POST /orders")
+	tx := database.WithContext(ctx)
+	if err := tx.Create(&widget{Name: "teclado"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	var w widget
+	// Registro não encontrado não é erro
+	tx.First(&w, 99)
+	tx.Table("missing").Find(&[]widget{})
+	parent.End()
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+
+	tests := []struct {
+		name      string
+		operation string
+		status    codes.Code
+	}{
+		{"gorm.create widgets", "create", codes.Unset},
+		{"gorm.query widgets", "query", codes.Unset},
+		{"gorm.query missing", "query", codes.Error},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			span, ok := spans[tt.name]
+			if !ok {
+				t.Fatalf("span não gravado; spans: %v", recorder.Ended())
+			}
+			if span.SpanKind() != trace.SpanKindClient {
+				t.Errorf("kind = %s, esperado client", span.SpanKind())
+			}
+			// Filho do span da requisição
+			if span.Parent().SpanID() != parent.SpanContext().SpanID() {
+				t.Error("span fora do contexto da query")
+			}
+			if got := attr(span, semconv.DBSystemNameKey); got != semconv.DBSystemNameSQLite.Value.Emit() {
+				t.Errorf("db.system.name = %q, esperado sqlite", got)
+			}
+			if got := attr(span, semconv.DBOperationNameKey); got != tt.operation {
+				t.Errorf("db.operation.name = %q, esperado %q", got, tt.operation)
+			}
+			if attr(span, semconv.DBQueryTextKey) == "" {
+				t.Error("span sem o SQL")
+			}
+			if span.Status().Code != tt.status {
+				t.Errorf("status = %s, esperado %s", span.Status().Code, tt.status)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package tracing
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"order-service/internal/config"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// useRecorder troca o TracerProvider global por um que guarda os spans
+// encerrados, e restaura o anterior no fim do teste.
+func useRecorder(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+		provider.Shutdown(context.Background())
+	})
+	return recorder
+}
+
+// restoreGlobals devolve o provider e o propagador globais ao fim do teste.
+func restoreGlobals(t *testing.T) {
+	provider, propagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	t.Cleanup(func() {
+		otel.SetTracerProvider(provider)
+		otel.SetTextMapPropagator(propagator)
+	})
+}
+
+func TestInitWithoutExporter(t *testing.T) {
+	restoreGlobals(t)
+	provider := otel.GetTracerProvider()
+
+	shutdown, err := Init(context.Background(), &config.TracingConfig{Exporter: "none"}, "order-service", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if otel.GetTracerProvider() != provider {
+		t.Error("exporter none não deveria trocar o TracerProvider")
+	}
+	// O contexto recebido continua sendo repassado
+	fields := otel.GetTextMapPropagator().Fields()
+	if !strings.Contains(strings.Join(fields, ","), "traceparent") {
+		t.Errorf("propagador sem traceparent: %v", fields)
+	}
+}
+
+func TestInitFileExporter(t *testing.T) {
+	restoreGlobals(t)
+	path := filepath.Join(t.TempDir(), "traces.json")
+
+	cfg := &config.TracingConfig{Exporter: "file", FilePath: path, SampleRatio: 1}
+	shutdown, err := Init(context.Background(), cfg, "order-service", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, span := Tracer().Start(context.Background(), "checkout")
+	span.End()
+
+	// O batcher só grava no Shutdown
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"Name":"checkout"`, `"order-service"`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("arquivo sem %s: %s", want, data)
+		}
+	}
+}
+
+func TestInitRejectsUnknownExporter(t *testing.T) {
+	restoreGlobals(t)
+	_, err := Init(context.Background(), &config.TracingConfig{Exporter: "zipkin"}, "order-service", "test")
+	if err == nil || !strings.Contains(err.Error(), `unknown tracing exporter "zipkin"`) {
+		t.Errorf("erro = %v, esperado exporter desconhecido", err)
+	}
+}
+
+func TestPropagatorRoundTrip(t *testing.T) {
+	restoreGlobals(t)
+	useRecorder(t)
+	if _, err := Init(context.Background(), &config.TracingConfig{}, "order-service", "test"); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, span := Tracer().Start(context.Background(), "publish")
+	defer span.End()
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+
+	extracted := otel.GetTextMapPropagator().Extract(context.Background(), carrier)
+	_, child := Tracer().Start(extracted, "process")
+	defer child.End()
+	if child.SpanContext().TraceID() != span.SpanContext().TraceID() {
+		t.Error("span extraído do carrier está em outro trace")
+	}
+}
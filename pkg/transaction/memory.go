@@ -0,0 +1,78 @@
+package transaction
+
+import (
+	"context"
+	"sync"
+)
+
+// Snapshotter é um armazenamento em memória que sabe desfazer alterações:
+// Snapshot guarda o estado atual e devolve a função que o restaura.
+type Snapshotter interface {
+	Snapshot() (restore func())
+}
+
+type memoryTx struct{}
+
+type memoryManager struct {
+	// Uma transação por vez, como se fossem serializáveis
+	mu          sync.Mutex
+	stores      []Snapshotter
+	maxAttempts int
+}
+
+// NewMemoryManager cria um Manager para testes que não precisa de banco.
+// Antes de cada transação ou savepoint o estado de stores é guardado e, se
+// fn falhar, restaurado. Conflitos simulados com ErrSerialization são
+// repetidos até maxAttempts vezes.
+func NewMemoryManager(maxAttempts int, stores ...Snapshotter) Manager {
+	return &memoryManager{stores: stores, maxAttempts: max(maxAttempts, 1)}
+}
+
+func (m *memoryManager) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(memoryTx); ok {
+		hooks := &afterCommit{}
+		return hooks.release(ctx, m.run(hooks.bind(ctx), fn))
+	}
+
+	// As funções de AfterCommit rodam depois de liberar a próxima transação
+	var hooks *afterCommit
+	err := m.transaction(ctx, func(txCtx context.Context) error {
+		hooks = &afterCommit{}
+		return m.run(hooks.bind(txCtx), fn)
+	})
+	return hooks.release(ctx, err)
+}
+
+func (m *memoryManager) transaction(ctx context.Context, attempt func(ctx context.Context) error) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	txCtx := context.WithValue(ctx, txKey{}, memoryTx{})
+	return retry(txCtx, m.maxAttempts, func() error {
+		return attempt(txCtx)
+	})
+}
+
+func (m *memoryManager) run(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	restores := make([]func(), len(m.stores))
+	for i, store := range m.stores {
+		restores[i] = store.Snapshot()
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			rollback(restores)
+			panic(r)
+		}
+		if err != nil {
+			rollback(restores)
+		}
+	}()
+	return fn(ctx)
+}
+
+func rollback(restores []func()) {
+	for _, restore := range restores {
+		restore()
+	}
+}
@@ -0,0 +1,15 @@
+package transaction
+
+import (
+	"order-service/pkg/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var transactionRetries = promauto.NewCounter(prometheus.CounterOpts{
+	Namespace: metrics.Namespace,
+	Subsystem: "db",
+	Name:      "transaction_retries_total",
+	Help:      "Transações repetidas por conflito de serialização.",
+})
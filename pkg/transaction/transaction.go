@@ -0,0 +1,155 @@
+// Package transaction delimita unidades de trabalho na camada de serviço.
+//
+// A transação viaja no contexto: repositórios que obtêm a conexão com DB
+// participam da transação aberta por Manager.Do sem precisar recebê-la
+// explicitamente.
+package transaction
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// Espera antes de repetir uma transação abortada por conflito
+const (
+	initialRetryBackoff = 10 * time.Millisecond
+	maxRetryBackoff     = 200 * time.Millisecond
+)
+
+// ErrSerialization marca conflitos que justificam repetir a transação
+// inteira. Falhas de serialização e deadlocks do Postgres são reconhecidos
+// sem precisar dele; implementações em memória podem usá-lo para simular
+// conflitos.
+var ErrSerialization = errors.New("transaction serialization failure")
+
+// Manager executa funções em uma transação.
+type Manager interface {
+	// Do executa fn em uma transação: commit se fn retornar nil, rollback
+	// caso contrário. Tudo o que usar o ctx recebido por fn participa da
+	// transação. Do chamado com esse ctx abre um savepoint, desfeito sozinho
+	// se a fn interna falhar. Na transação mais externa, conflitos de
+	// serialização repetem fn do início, então fn não deve ter efeitos fora
+	// do banco: publicar eventos, por exemplo, fica para depois do Do ou
+	// para AfterCommit.
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type txKey struct{}
+
+type hooksKey struct{}
+
+// afterCommit guarda as funções agendadas por AfterCommit em uma transação
+// ou savepoint.
+type afterCommit struct {
+	mu  sync.Mutex
+	fns []func()
+}
+
+// AfterCommit agenda fn para depois do commit da transação mais externa de
+// ctx; fora de uma transação, fn roda na hora. Se a transação, ou o
+// savepoint em que fn foi agendada, for desfeita, fn não roda; se for
+// repetida por conflito, só rodam as funções da tentativa que deu certo.
+func AfterCommit(ctx context.Context, fn func()) {
+	if hooks, ok := ctx.Value(hooksKey{}).(*afterCommit); ok {
+		hooks.mu.Lock()
+		defer hooks.mu.Unlock()
+		hooks.fns = append(hooks.fns, fn)
+		return
+	}
+	fn()
+}
+
+// bind devolve ctx com h recebendo as funções de AfterCommit.
+func (h *afterCommit) bind(ctx context.Context) context.Context {
+	return context.WithValue(ctx, hooksKey{}, h)
+}
+
+// release, se err for nil, passa as funções agendadas para a transação de
+// ctx ou, fora de uma, as executa.
+func (h *afterCommit) release(ctx context.Context, err error) error {
+	if err != nil {
+		return err
+	}
+	for _, fn := range h.fns {
+		AfterCommit(ctx, fn)
+	}
+	return nil
+}
+
+// DB devolve a transação em andamento no contexto ou, fora de uma, db com
+// o contexto aplicado.
+func DB(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
+
+type gormManager struct {
+	db          *gorm.DB
+	maxAttempts int
+}
+
+// NewManager cria um Manager sobre db que tenta cada transação até
+// maxAttempts vezes em caso de conflito de serialização.
+func NewManager(db *gorm.DB, maxAttempts int) Manager {
+	return &gormManager{db: db, maxAttempts: max(maxAttempts, 1)}
+}
+
+func (m *gormManager) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	// Dentro de uma transação, o GORM abre um savepoint
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		hooks := &afterCommit{}
+		err := tx.WithContext(ctx).Transaction(func(sp *gorm.DB) error {
+			return fn(hooks.bind(context.WithValue(ctx, txKey{}, sp)))
+		})
+		return hooks.release(ctx, err)
+	}
+
+	var hooks *afterCommit
+	err := retry(ctx, m.maxAttempts, func() error {
+		hooks = &afterCommit{}
+		return m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return fn(hooks.bind(context.WithValue(ctx, txKey{}, tx)))
+		})
+	})
+	return hooks.release(ctx, err)
+}
+
+// IsSerializationFailure informa se err é um conflito que some repetindo a
+// transação: serialization_failure (40001), deadlock_detected (40P01) ou
+// ErrSerialization.
+func IsSerializationFailure(err error) bool {
+	if errors.Is(err, ErrSerialization) {
+		return true
+	}
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && (pgErr.Code == "40001" || pgErr.Code == "40P01")
+}
+
+// retry repete attempt enquanto falhar por conflito, com backoff
+// exponencial e jitter para as transações concorrentes não colidirem de novo.
+func retry(ctx context.Context, maxAttempts int, attempt func() error) error {
+	backoff := initialRetryBackoff
+	for n := 1; ; n++ {
+		err := attempt()
+		if err == nil || n >= maxAttempts || !IsSerializationFailure(err) {
+			return err
+		}
+
+		transactionRetries.Inc()
+		wait := backoff/2 + rand.N(backoff/2+1)
+		select {
+		case <-ctx.Done():
+			return errors.Join(err, ctx.Err())
+		case <-time.After(wait):
+		}
+		backoff = min(backoff*2, maxRetryBackoff)
+	}
+}
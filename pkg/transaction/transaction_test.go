@@ -0,0 +1,148 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// counter é um armazenamento mínimo com snapshot.
+type counter struct {
+	value int
+}
+
+func (c *counter) Snapshot() func() {
+	saved := c.value
+	return func() { c.value = saved }
+}
+
+func TestMemoryManagerSavepoints(t *testing.T) {
+	store := &counter{}
+	manager := NewMemoryManager(1, store)
+	errInner := errors.New("falha interna")
+
+	err := manager.Do(context.Background(), func(ctx context.Context) error {
+		store.value = 1
+
+		// Savepoint desfeito sem derrubar a transação externa
+		err := manager.Do(ctx, func(ctx context.Context) error {
+			store.value = 2
+			return errInner
+		})
+		if !errors.Is(err, errInner) {
+			t.Errorf("savepoint err = %v", err)
+		}
+		if store.value != 1 {
+			t.Errorf("valor após rollback do savepoint = %d, esperado 1", store.value)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if store.value != 1 {
+		t.Fatalf("valor após commit = %d, esperado 1", store.value)
+	}
+
+	err = manager.Do(context.Background(), func(ctx context.Context) error {
+		store.value = 3
+		return errInner
+	})
+	if !errors.Is(err, errInner) || store.value != 1 {
+		t.Fatalf("rollback: err = %v, valor = %d, esperado 1", err, store.value)
+	}
+}
+
+func TestRetryOnSerializationFailure(t *testing.T) {
+	store := &counter{}
+	manager := NewMemoryManager(3, store)
+
+	attempts := 0
+	err := manager.Do(context.Background(), func(ctx context.Context) error {
+		attempts++
+		store.value++
+		if attempts < 3 {
+			return fmt.Errorf("conflito: %w", ErrSerialization)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attempts != 3 || store.value != 1 {
+		t.Fatalf("tentativas = %d, valor = %d; esperado 3 tentativas e valor 1", attempts, store.value)
+	}
+
+	attempts = 0
+	err = manager.Do(context.Background(), func(ctx context.Context) error {
+		attempts++
+		return ErrSerialization
+	})
+	if !errors.Is(err, ErrSerialization) || attempts != 3 {
+		t.Fatalf("err = %v após %d tentativas, esperado ErrSerialization após 3", err, attempts)
+	}
+}
+
+func TestAfterCommit(t *testing.T) {
+	manager := NewMemoryManager(3, &counter{})
+	errInner := errors.New("falha interna")
+	var ran []string
+
+	// Fora de uma transação, roda na hora
+	AfterCommit(context.Background(), func() { ran = append(ran, "fora") })
+
+	attempts := 0
+	err := manager.Do(context.Background(), func(ctx context.Context) error {
+		attempts++
+		AfterCommit(ctx, func() { ran = append(ran, fmt.Sprint("tentativa ", attempts)) })
+
+		manager.Do(ctx, func(ctx context.Context) error {
+			AfterCommit(ctx, func() { ran = append(ran, "savepoint desfeito") })
+			return errInner
+		})
+		manager.Do(ctx, func(ctx context.Context) error {
+			AfterCommit(ctx, func() { ran = append(ran, "savepoint") })
+			return nil
+		})
+
+		if len(ran) != 1 {
+			t.Errorf("funções rodaram antes do commit: %v", ran)
+		}
+		if attempts < 2 {
+			return ErrSerialization
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	manager.Do(context.Background(), func(ctx context.Context) error {
+		AfterCommit(ctx, func() { ran = append(ran, "rollback") })
+		return errInner
+	})
+
+	want := []string{"fora", "tentativa 2", "savepoint"}
+	if fmt.Sprint(ran) != fmt.Sprint(want) {
+		t.Errorf("rodaram %v, esperado %v", ran, want)
+	}
+}
+
+func TestIsSerializationFailure(t *testing.T) {
+	for _, tc := range []struct {
+		err  error
+		want bool
+	}{
+		{&pgconn.PgError{Code: "40001"}, true},
+		{fmt.Errorf("update: %w", &pgconn.PgError{Code: "40P01"}), true},
+		{&pgconn.PgError{Code: "23505"}, false},
+		{errors.New("outro erro"), false},
+	} {
+		if got := IsSerializationFailure(tc.err); got != tc.want {
+			t.Errorf("IsSerializationFailure(%v) = %v, esperado %v", tc.err, got, tc.want)
+		}
+	}
+}